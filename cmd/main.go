@@ -2,20 +2,31 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	rs "github.com/go-redis/redis"
-	"log"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // schedules of urls are in zones the host may not know
 	ch "url-shortener/internal/cache"
 	"url-shortener/internal/config"
 	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/metrics"
 	"url-shortener/internal/route/user/sign"
 	"url-shortener/internal/server"
 	"url-shortener/internal/service/mail"
+	"url-shortener/internal/service/metadata"
+	"url-shortener/internal/service/monitor"
+	"url-shortener/internal/service/privacy"
+	"url-shortener/internal/service/targeting"
+	"url-shortener/internal/service/trash"
+	"url-shortener/internal/service/webhook"
 )
 
 func periodicallyCheckRedis(r ch.Redis, err chan error) {
@@ -27,12 +38,58 @@ func periodicallyCheckRedis(r ch.Redis, err chan error) {
 	}
 }
 
+// readJSONFile returns the content of a json file at path, nil if path is empty.
+func readJSONFile(logger *logrus.Logger, path string) []byte {
+	if path == "" {
+		return nil
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		logger.WithError(err).WithField("path", path).Fatal("Unable to read file")
+	}
+	if !json.Valid(content) {
+		logger.WithField("path", path).Fatal("File is not valid json")
+	}
+	return content
+}
+
+// promoteAdmins gives the admin role to registered users with given emails, the ones not registered yet are skipped.
+// The list only seeds admins: removing an email from it leaves the account admin, admins are demoted through
+// the admin API (PATCH /admin/users/:user_id).
+func promoteAdmins(logger *logrus.Logger, db database.MySQLService, emails []string) {
+	for _, email := range emails {
+		user, err := db.GetUserWithEmail(email)
+		if err != nil {
+			if _, ok := err.(database.RecordNotFoundError); ok {
+				logger.WithField("email", email).Warn("Admin not registered yet ...skipped")
+				continue
+			}
+			logger.WithError(err).Fatal("Unable to query for admin")
+		}
+		if user.Role == database.UserRoleAdmin {
+			continue
+		}
+		if err := db.SetUserRole(user.UserID, database.UserRoleAdmin); err != nil {
+			logger.WithError(err).Fatal("Unable to promote admin")
+		}
+		logger.WithField("user_id", user.UserID).Info("Promoted to admin")
+	}
+}
+
 func main() {
 	// TODO: connect to database at startup
 	// TODO: make env variable configurable
 	// TODO: do TDD
 	env := config.ReadEnv()
 
+	/**
+	Logging configuration
+	*/
+	logger := logrus.StandardLogger()
+	if err := logging.Configure(logger, env.LogLevel, env.LogFormat); err != nil {
+		logger.WithError(err).Fatal("Unable to set up logger")
+	}
+
 	/**
 	Database configuration
 	*/
@@ -44,15 +101,17 @@ func main() {
 		DBName:   env.DBName,
 		DBParams: env.DBParams,
 	}
-	db, err := database.NewMySQLDatabase(dbConfig)
+	_db, err := database.NewMySQLDatabase(dbConfig)
 	if err != nil {
-		log.Fatalf("Unable to set up database | Reason: %v\n", err)
+		logger.WithError(err).Fatal("Unable to set up database")
 	}
+	db := metrics.InstrumentDatabase(_db)
 	defer func() {
 		if err := db.Close(); err != nil {
-			log.Printf("Warning: unable to close mysql connection properly | Reason: %v\n", err)
+			logger.WithError(err).Warn("Unable to close mysql connection properly")
 		}
 	}()
+	promoteAdmins(logger, db, env.AdminEmails)
 
 	/**
 	Caching configuration
@@ -70,10 +129,15 @@ func main() {
 	go periodicallyCheckRedis(cache, redisErr)
 	defer func() {
 		if err := cache.Close(); err != nil {
-			log.Printf("Warning: unable to close redis connection properly | Reason: %v\n", err)
+			logger.WithError(err).Warn("Unable to close redis connection properly")
 		}
 	}()
 
+	/**
+	Metrics
+	*/
+	go metrics.StartStatsCollector(context.Background(), db, time.Minute)
+
 	/**
 	jwtKey configuration
 	*/
@@ -102,6 +166,78 @@ func main() {
 
 	go mail.StartEmailService(context.Background(), emailSetupOptions, emailRequestChannel)
 
+	/**
+	Metadata service
+	*/
+	metadataRequestChannel := make(chan string, 100)
+	go metadata.StartMetadataService(context.Background(), &metadata.ServiceOptions{
+		Fetcher:       metadata.DefaultFetcherOptions,
+		RefreshAfter:  env.MetadataRefreshAfter,
+		CheckInterval: 10 * time.Minute,
+		BatchSize:     100,
+		Concurrency:   4,
+	}, db, metadataRequestChannel)
+
+	/**
+	Dead link monitor
+	*/
+	var alertRequestChannel chan<- mail.SendEmailOptions
+	if env.EmailServiceEnabled {
+		alertRequestChannel = emailRequestChannel
+	}
+	go monitor.StartMonitorService(context.Background(), &monitor.ServiceOptions{
+		Client:           metadata.DefaultFetcherOptions,
+		Interval:         env.DeadLinkCheckInterval,
+		AlertAfter:       env.DeadLinkAlertAfter,
+		HistoryRetention: 30 * 24 * time.Hour,
+		BatchSize:        100,
+		Concurrency:      8,
+		BaseUrl:          env.BaseUrl.String(),
+	}, db, alertRequestChannel)
+
+	/**
+	Trash purge
+	*/
+	go trash.StartPurgeService(context.Background(), &trash.ServiceOptions{
+		Retention: env.URLTrashRetention,
+		Interval:  time.Hour,
+		BatchSize: 100,
+	}, db)
+
+	/**
+	Data export
+	*/
+	dataExportRequestChannel := make(chan string, 20)
+	go privacy.StartExportService(context.Background(), &privacy.ServiceOptions{
+		Retention: env.DataExportRetention,
+		Interval:  10 * time.Minute,
+		BatchSize: 100,
+		BaseUrl:   env.BaseUrl.String(),
+	}, db, dataExportRequestChannel, alertRequestChannel)
+
+	/**
+	Webhook deliveries
+	*/
+	webhookOptions := webhook.DefaultServiceOptions
+	go webhook.StartDeliveryService(context.Background(), &webhookOptions, db, alertRequestChannel)
+
+	/**
+	Geolocation of redirect rules
+	*/
+	var geoLocator targeting.GeoLocator
+	if env.GeoIPDatabase != "" {
+		mmdb, err := targeting.OpenMMDB(env.GeoIPDatabase)
+		if err != nil {
+			logger.WithError(err).Fatal("Unable to open geoip database")
+		}
+		defer func() {
+			if err := mmdb.Close(); err != nil {
+				logger.WithError(err).Warn("Unable to close geoip database properly")
+			}
+		}()
+		geoLocator = mmdb
+	}
+
 	serverOptions := server.ServerOptions{
 		Database:                 db,
 		Cache:                    cache,
@@ -112,13 +248,26 @@ func main() {
 		HtmlTemplate:             "./internal/template",
 		GoogleOauthConf:          gConf,
 		EmailVerificationIgnored: !env.EmailServiceEnabled,
+		InvitationTokenReturned:  !env.EmailServiceEnabled,
 		EmailRequest:             emailRequestChannel,
+		MetadataRequest:          metadataRequestChannel,
+		DataExportRequest:        dataExportRequestChannel,
+		Logger:                   logger,
+		LegacyAPISunset:          env.LegacyAPISunset,
+		GeoLocator:               geoLocator,
+		AppleAppSiteAssociation:  readJSONFile(logger, env.AppleAppSiteAssociation),
+		AndroidAssetLinks:        readJSONFile(logger, env.AndroidAssetLinks),
+		TrashRetention:           env.URLTrashRetention,
+		TrustedProxies:           env.TrustedProxies,
+		ReportRateLimit:          env.ReportRateLimit,
+		ReportURLRateLimit:       env.ReportURLRateLimit,
+		ReportSuspendThreshold:   env.ReportSuspendThreshold,
 	}
 
 	serverErr := make(chan error) // return true indicates something is wrong
 	go func(serverErr chan<- error) {
 		r := server.SetupServer(serverOptions)
-		log.Printf("Server is listening...")
+		logger.Info("Server is listening...")
 		port := fmt.Sprintf(":%v", env.Port)
 		if err := r.Run(port); err != nil {
 			serverErr <- err
@@ -131,12 +280,12 @@ func main() {
 	for !terminated {
 		select {
 		case <-done:
-			log.Printf("Gracefully shutting down...\n")
+			logger.Info("Gracefully shutting down...")
 			terminated = true
 		case err := <-serverErr:
-			log.Fatalf("Unable to start server: %v\n", err)
+			logger.WithError(err).Fatal("Unable to start server")
 		case err := <-redisErr:
-			log.Fatalf("Connection check with redis failed: %v\n", err)
+			logger.WithError(err).Fatal("Connection check with redis failed")
 		default:
 		}
 	}
@@ -12,6 +12,7 @@ type Redis interface {
 	Del(key string) error
 	Set(key string, value interface{}, expiration time.Duration) error
 	Increment(key string) (int64, error)
+	IncrementWithin(key string, window time.Duration) (int64, error)
 	NewTx() rs.Pipeliner
 	Ping() error
 	Close() error
@@ -50,6 +51,20 @@ func (r *redis) Increment(key string) (int64, error) {
 	return r.client.Incr(key).Result()
 }
 
+// IncrementWithin increments key, which expires window after its first increment.
+func (r *redis) IncrementWithin(key string, window time.Duration) (int64, error) {
+	count, err := r.client.Incr(key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		if err := r.client.Expire(key, window).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
 // New creates an instance of Redis
 func New(options *rs.Options) Redis {
 	return &redis{
@@ -92,4 +92,23 @@ var _ = Describe("Redis", func() {
 			})
 		})
 	})
+
+	Describe("Increment key within a window", func() {
+		It("should count from one again once window is over", func() {
+			key := "test-window"
+			Expect(cache.Del(key)).To(Succeed())
+
+			count, err := cache.IncrementWithin(key, time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(int64(1)))
+			count, err = cache.IncrementWithin(key, time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(int64(2)))
+
+			time.Sleep(1100 * time.Millisecond)
+			count, err = cache.IncrementWithin(key, time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(int64(1)))
+		})
+	})
 })
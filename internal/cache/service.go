@@ -8,15 +8,28 @@ import (
 type Service interface {
 	GetCachedURL(shortenURL string) (string, error)
 	PutCachedURL(shortenURL string, oriURL string, expiration time.Duration) error
+	DelCachedURL(shortenURL string) error
 	GetCachedURLCount(shortenURL string) (string, error)
 	PutCachedURLCount(shortenURL string) (int64, error)
+	CountReportsFrom(ip string, window time.Duration) (int64, error)
+	CountReportsAbout(shortenURL string, window time.Duration) (int64, error)
 }
 
 var (
 	keyCachedUrl      = "KEY_CACHED_URL"
 	keyCachedUrlCount = "KEY_CACHED_URL_COUNT"
+	keyReportsFrom    = "KEY_REPORTS_FROM"
+	keyReportsAbout   = "KEY_REPORTS_ABOUT"
 )
 
+// DomainURL identifies shortenURL of a custom domain in place of shortenURL, codes of the default domain are used as is.
+func DomainURL(domain string, shortenURL string) string {
+	if domain == "" {
+		return shortenURL
+	}
+	return domain + "/" + shortenURL
+}
+
 func cachedURLKey(shortenURL string) string {
 	return keyCachedUrl + ":" + shortenURL
 }
@@ -52,6 +65,10 @@ func (s service) PutCachedURL(shortenURL string, oriURL string, expiration time.
 	return s.redis.Set(cachedURLKey(shortenURL), oriURL, expiration)
 }
 
+func (s service) DelCachedURL(shortenURL string) error {
+	return s.redis.Del(cachedURLKey(shortenURL))
+}
+
 func (s service) GetCachedURLCount(shortenURL string) (string, error) {
 	return s.redis.Get(cachedURLCountKey(shortenURL))
 }
@@ -60,6 +77,16 @@ func (s service) PutCachedURLCount(shortenURL string) (int64, error) {
 	return s.redis.Increment(cachedURLCountKey(shortenURL))
 }
 
+// CountReportsFrom counts a report sent from ip and returns how many were sent from it in the current window.
+func (s service) CountReportsFrom(ip string, window time.Duration) (int64, error) {
+	return s.redis.IncrementWithin(keyReportsFrom+":"+ip, window)
+}
+
+// CountReportsAbout counts a report sent about shortenURL and returns how many were sent about it in the current window.
+func (s service) CountReportsAbout(shortenURL string, window time.Duration) (int64, error) {
+	return s.redis.IncrementWithin(keyReportsAbout+":"+shortenURL, window)
+}
+
 func NewService(redis Redis) Service {
 	return &service{
 		redis: redis,
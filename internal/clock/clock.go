@@ -0,0 +1,26 @@
+package clock
+
+import (
+	"time"
+)
+
+// Clock tells the current time, so that time dependent behaviour can be tested.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// System is the wall clock.
+var System Clock = systemClock{}
+
+// Fixed is a clock standing still at its time.
+type Fixed time.Time
+
+func (f Fixed) Now() time.Time {
+	return time.Time(f)
+}
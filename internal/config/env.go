@@ -3,39 +3,61 @@ package config
 import (
 	"fmt"
 	"github.com/joho/godotenv"
-	"log"
+	"github.com/sirupsen/logrus"
+	"net"
 	url2 "net/url"
 	"os"
 	"reflect"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"url-shortener/internal/logging"
 )
 
+// Env holds configuration read from environment. Fields tagged with redact are masked when printed.
 type Env struct {
 	DBUser                  string
-	DBPass                  string
+	DBPass                  string `redact:"true"`
 	DBHost                  string
 	DBPort                  string
 	DBName                  string
 	DBParams                string
 	RedisHost               string
 	RedisPort               string
-	RedisPassword           string
-	JwtKey                  string
+	RedisPassword           string `redact:"true"`
+	JwtKey                  string `redact:"true"`
 	GoogleOauthClientId     string
-	GoogleOauthClientSecret string
+	GoogleOauthClientSecret string `redact:"true"`
 	BaseUrl                 *url2.URL
 	Port                    string
 	UseHttps                bool
 	EmailServerAddr         string
 	EmailUserName           string
-	EmailUserPassword       string
+	EmailUserPassword       string `redact:"true"`
 	EmailServiceEnabled     bool
+	LogLevel                string
+	LogFormat               string
+	LegacyAPISunset         time.Time
+	MetadataRefreshAfter    time.Duration
+	DeadLinkCheckInterval   time.Duration
+	DeadLinkAlertAfter      time.Duration
+	GeoIPDatabase           string // path of a MaxMind country database, empty disables geo targeting
+	AppleAppSiteAssociation string // path of apple-app-site-association served for iOS universal links
+	AndroidAssetLinks       string // path of assetlinks.json served for Android app links
+	URLTrashRetention       time.Duration
+	AdminEmails             []string     // users promoted to admins on start, never demoted when removed
+	TrustedProxies          []*net.IPNet // peers whose X-Forwarded-For is believed
+	ReportRateLimit         int64        // reports accepted per address an hour
+	ReportURLRateLimit      int64        // reports accepted per url an hour
+	ReportSuspendThreshold  uint64       // distinct reporters suspending a url pending review
+	DataExportRetention     time.Duration
 }
 
 func ReadEnv() Env {
 	err := godotenv.Load()
 	if err != nil {
-		log.Printf("Unable to read .env | Reason: %v ...skipped\n", err)
+		logrus.WithError(err).Info("Unable to read .env ...skipped")
 	}
 
 	/**
@@ -43,36 +65,36 @@ func ReadEnv() Env {
 	*/
 	dbUser := os.Getenv("DB_USER")
 	if dbUser == "" {
-		log.Printf("DB_USER is empty. Default as \"root\"\n")
+		logrus.Info("DB_USER is empty. Default as \"root\"")
 		dbUser = "root"
 	}
 
 	dbPass := os.Getenv("DB_PASSWORD")
 	if dbPass == "" {
-		log.Printf("DB_PASSWORD is empty\n")
+		logrus.Info("DB_PASSWORD is empty")
 	}
 
 	dbHost := os.Getenv("DB_HOST")
 	if dbHost == "" {
-		log.Printf("DB_HOST is empty. Default as \"localhost\"\n")
+		logrus.Info("DB_HOST is empty. Default as \"localhost\"")
 		dbHost = "localhost"
 	}
 
 	dbPort := os.Getenv("DB_PORT")
 	if dbPort == "" {
-		log.Printf("DB_PORT is empty. Default as \"3306\"\n")
+		logrus.Info("DB_PORT is empty. Default as \"3306\"")
 		dbPort = "3306"
 	}
 
 	dbName := os.Getenv("DB_NAME")
 	if dbName == "" {
-		log.Printf("DB_NAME is empty. Default as \"url_shortener\"\n")
+		logrus.Info("DB_NAME is empty. Default as \"url_shortener\"")
 		dbName = "url_shortener"
 	}
 
 	dbParams := os.Getenv("DB_PARAMS")
 	if dbParams == "" {
-		log.Printf("DB_PARAMS is empty. Default as \"charset=utf8&parseTime=True&loc=Local\"\n")
+		logrus.Info("DB_PARAMS is empty. Default as \"charset=utf8&parseTime=True&loc=Local\"")
 		dbParams = "charset=utf8&parseTime=True&loc=Local"
 	}
 
@@ -83,26 +105,26 @@ func ReadEnv() Env {
 	var redisHost, redisPort, redisPass string
 
 	if isHerokuRedis {
-		log.Println("HerokuRedis detected")
+		logrus.Info("HerokuRedis detected")
 		redisHost = herokuRedisConf.Host
 		redisPort = herokuRedisConf.Port
 		redisPass = herokuRedisConf.Password
 	} else {
 		redisHost = os.Getenv("REDIS_HOST")
 		if redisHost == "" {
-			log.Printf("REDIS_HOST is empty. Default as \"localhost\"\n")
+			logrus.Info("REDIS_HOST is empty. Default as \"localhost\"")
 			redisHost = "localhost"
 		}
 
 		redisPort = os.Getenv("REDIS_PORT")
 		if redisPort == "" {
-			log.Printf("REDIS_PORT is empty. Default as \"6379\"\n")
+			logrus.Info("REDIS_PORT is empty. Default as \"6379\"")
 			redisPort = "6379"
 		}
 
 		redisPass = os.Getenv("REDIS_PASSWORD")
 		if redisPass == "" {
-			log.Printf("REDIS_PASSWORD is empty\n")
+			logrus.Info("REDIS_PASSWORD is empty")
 		}
 	}
 
@@ -111,7 +133,7 @@ func ReadEnv() Env {
 	*/
 	jwtKey := os.Getenv("JWT_KEY")
 	if jwtKey == "" {
-		log.Printf("JWT_KEY is empty. Default as \"testKey\"\n")
+		logrus.Warn("JWT_KEY is empty. Default as built-in value")
 		jwtKey = "testKey"
 	}
 
@@ -120,13 +142,13 @@ func ReadEnv() Env {
 	*/
 	googleClientId := os.Getenv("GOOGLE_OAUTH_CLIENT_ID")
 	if googleClientId == "" {
-		log.Printf("GOOGLE_OAUTH_CLIENT_ID is empty. Default as \"959723324236-0e23oe704fp1rtf3k5qc780mijahd1b3.apps.googleusercontent.com\"\n")
+		logrus.Info("GOOGLE_OAUTH_CLIENT_ID is empty. Default as \"959723324236-0e23oe704fp1rtf3k5qc780mijahd1b3.apps.googleusercontent.com\"")
 		googleClientId = "959723324236-0e23oe704fp1rtf3k5qc780mijahd1b3.apps.googleusercontent.com"
 	}
 
 	googleClientSecret := os.Getenv("GOOGLE_OAUTH_CLIENT_SECRET")
 	if googleClientSecret == "" {
-		log.Printf("GOOGLE_OAUTH_CLIENT_SECRET is empty. Default as \"xG1-yt61nKfvPUAfZumduCNO\"\n")
+		logrus.Warn("GOOGLE_OAUTH_CLIENT_SECRET is empty. Default as built-in value")
 		googleClientSecret = "xG1-yt61nKfvPUAfZumduCNO"
 	}
 
@@ -135,13 +157,13 @@ func ReadEnv() Env {
 	*/
 	port := os.Getenv("API_PORT")
 	if port == "" {
-		log.Printf("API_PORT is empty. Default as \"8080\"\n")
+		logrus.Info("API_PORT is empty. Default as \"8080\"")
 		port = "8080"
 	}
 
 	baseUrl := os.Getenv("BASE_URL")
 	if baseUrl == "" {
-		log.Printf("BASE_URL is empty. Default as \"http://url-shortener.com:%v\"\n", port)
+		logrus.Infof("BASE_URL is empty. Default as \"http://url-shortener.com:%v\"", port)
 		baseUrl = fmt.Sprintf("http://url-shortener.com:%v", port)
 	}
 
@@ -151,7 +173,7 @@ func ReadEnv() Env {
 	emailServerAddr := os.Getenv("EMAIL_SERVER_ADDR")
 	emailServiceActive := true
 	if emailServerAddr == "" {
-		log.Printf("EMAIL_SERVER_ADDR is empty. This will disable email functionality\n")
+		logrus.Info("EMAIL_SERVER_ADDR is empty. This will disable email functionality")
 		emailServiceActive = false
 	}
 	emailUsername := ""
@@ -159,14 +181,193 @@ func ReadEnv() Env {
 	if emailServiceActive {
 		emailUsername = os.Getenv("EMAIL_USERNAME")
 		if emailUsername == "" {
-			log.Printf("EMAIL_USERNAME is empty\n")
+			logrus.Info("EMAIL_USERNAME is empty")
 		}
 		emailPassword = os.Getenv("EMAIL_PASSWORD")
 		if emailPassword == "" {
-			log.Printf("EMAIL_PASSWORD is empty\n")
+			logrus.Info("EMAIL_PASSWORD is empty")
 		}
 	}
 
+	/**
+	Logging
+	*/
+	logLevel := os.Getenv("LOG_LEVEL")
+	if logLevel == "" {
+		logrus.Info("LOG_LEVEL is empty. Default as \"info\"")
+		logLevel = "info"
+	}
+
+	logFormat := os.Getenv("LOG_FORMAT")
+	if logFormat == "" {
+		logrus.Info("LOG_FORMAT is empty. Default as \"json\"")
+		logFormat = "json"
+	}
+
+	/**
+	API
+	*/
+	legacyAPISunset := os.Getenv("LEGACY_API_SUNSET")
+	if legacyAPISunset == "" {
+		logrus.Info("LEGACY_API_SUNSET is empty. Default as \"2027-04-30\"")
+		legacyAPISunset = "2027-04-30"
+	}
+	sunset, err := time.Parse("2006-01-02", legacyAPISunset)
+	if err != nil {
+		panic("Invalid LEGACY_API_SUNSET")
+	}
+
+	/**
+	Metadata
+	*/
+	metadataRefreshAfter := os.Getenv("METADATA_REFRESH_AFTER")
+	if metadataRefreshAfter == "" {
+		logrus.Info("METADATA_REFRESH_AFTER is empty. Default as \"24h\"")
+		metadataRefreshAfter = "24h"
+	}
+	refreshAfter, err := time.ParseDuration(metadataRefreshAfter)
+	if err != nil || refreshAfter <= 0 {
+		panic("Invalid METADATA_REFRESH_AFTER")
+	}
+
+	/**
+	Dead link monitor
+	*/
+	deadLinkCheckInterval := os.Getenv("DEAD_LINK_CHECK_INTERVAL")
+	if deadLinkCheckInterval == "" {
+		logrus.Info("DEAD_LINK_CHECK_INTERVAL is empty. Default as \"6h\"")
+		deadLinkCheckInterval = "6h"
+	}
+	checkInterval, err := time.ParseDuration(deadLinkCheckInterval)
+	if err != nil || checkInterval <= 0 {
+		panic("Invalid DEAD_LINK_CHECK_INTERVAL")
+	}
+
+	deadLinkAlertAfter := os.Getenv("DEAD_LINK_ALERT_AFTER")
+	if deadLinkAlertAfter == "" {
+		logrus.Info("DEAD_LINK_ALERT_AFTER is empty. Default as \"72h\"")
+		deadLinkAlertAfter = "72h"
+	}
+	alertAfter, err := time.ParseDuration(deadLinkAlertAfter)
+	if err != nil || alertAfter < 0 {
+		panic("Invalid DEAD_LINK_ALERT_AFTER")
+	}
+
+	/**
+	Redirect rules
+	*/
+	geoIPDatabase := os.Getenv("GEOIP_DATABASE")
+	if geoIPDatabase == "" {
+		logrus.Info("GEOIP_DATABASE is empty. Rules on countries are disabled")
+	}
+
+	/**
+	Deep links
+	*/
+	appleAppSiteAssociation := os.Getenv("APPLE_APP_SITE_ASSOCIATION_FILE")
+	if appleAppSiteAssociation == "" {
+		logrus.Info("APPLE_APP_SITE_ASSOCIATION_FILE is empty. Nothing is served")
+	}
+	androidAssetLinks := os.Getenv("ANDROID_ASSET_LINKS_FILE")
+	if androidAssetLinks == "" {
+		logrus.Info("ANDROID_ASSET_LINKS_FILE is empty. Nothing is served")
+	}
+
+	/**
+	Trash
+	*/
+	urlTrashRetention := os.Getenv("URL_TRASH_RETENTION")
+	if urlTrashRetention == "" {
+		logrus.Info("URL_TRASH_RETENTION is empty. Default as \"720h\"")
+		urlTrashRetention = "720h"
+	}
+	trashRetention, err := time.ParseDuration(urlTrashRetention)
+	if err != nil || trashRetention <= 0 {
+		panic("Invalid URL_TRASH_RETENTION")
+	}
+
+	/**
+	Administration
+	*/
+	var adminEmails []string
+	for _, email := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			adminEmails = append(adminEmails, email)
+		}
+	}
+	if len(adminEmails) == 0 {
+		logrus.Info("ADMIN_EMAILS is empty. Nobody is promoted to admin")
+	}
+
+	/**
+	Proxies
+	*/
+	var trustedProxies []*net.IPNet
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			panic("Invalid TRUSTED_PROXIES")
+		}
+		trustedProxies = append(trustedProxies, network)
+	}
+	if len(trustedProxies) == 0 {
+		logrus.Info("TRUSTED_PROXIES is empty. X-Forwarded-For is ignored")
+	}
+
+	/**
+	Abuse reports
+	*/
+	reportRateLimitEnv := os.Getenv("REPORT_RATE_LIMIT")
+	if reportRateLimitEnv == "" {
+		logrus.Info("REPORT_RATE_LIMIT is empty. Default as \"10\"")
+		reportRateLimitEnv = "10"
+	}
+	reportRateLimit, err := strconv.ParseInt(reportRateLimitEnv, 10, 64)
+	if err != nil || reportRateLimit <= 0 {
+		panic("Invalid REPORT_RATE_LIMIT")
+	}
+	reportURLRateLimitEnv := os.Getenv("REPORT_URL_RATE_LIMIT")
+	if reportURLRateLimitEnv == "" {
+		logrus.Info("REPORT_URL_RATE_LIMIT is empty. Default as \"20\"")
+		reportURLRateLimitEnv = "20"
+	}
+	reportURLRateLimit, err := strconv.ParseInt(reportURLRateLimitEnv, 10, 64)
+	if err != nil || reportURLRateLimit <= 0 {
+		panic("Invalid REPORT_URL_RATE_LIMIT")
+	}
+	reportSuspendThresholdEnv := os.Getenv("REPORT_SUSPEND_THRESHOLD")
+	if reportSuspendThresholdEnv == "" {
+		logrus.Info("REPORT_SUSPEND_THRESHOLD is empty. Default as \"5\"")
+		reportSuspendThresholdEnv = "5"
+	}
+	reportSuspendThreshold, err := strconv.ParseUint(reportSuspendThresholdEnv, 10, 64)
+	if err != nil || reportSuspendThreshold == 0 {
+		panic("Invalid REPORT_SUSPEND_THRESHOLD")
+	}
+
+	/**
+	Data export
+	*/
+	dataExportRetentionEnv := os.Getenv("DATA_EXPORT_RETENTION")
+	if dataExportRetentionEnv == "" {
+		logrus.Info("DATA_EXPORT_RETENTION is empty. Default as \"168h\"")
+		dataExportRetentionEnv = "168h"
+	}
+	dataExportRetention, err := time.ParseDuration(dataExportRetentionEnv)
+	if err != nil || dataExportRetention <= 0 {
+		panic("Invalid DATA_EXPORT_RETENTION")
+	}
+
 	u, err := url2.ParseRequestURI(baseUrl)
 	if err != nil {
 		panic("Invalid baseUrl")
@@ -201,14 +402,35 @@ func ReadEnv() Env {
 		EmailUserName:           emailUsername,
 		EmailUserPassword:       emailPassword,
 		EmailServiceEnabled:     emailServiceActive,
+		LogLevel:                logLevel,
+		LogFormat:               logFormat,
+		LegacyAPISunset:         sunset,
+		MetadataRefreshAfter:    refreshAfter,
+		DeadLinkCheckInterval:   checkInterval,
+		DeadLinkAlertAfter:      alertAfter,
+		GeoIPDatabase:           geoIPDatabase,
+		AppleAppSiteAssociation: appleAppSiteAssociation,
+		AndroidAssetLinks:       androidAssetLinks,
+		URLTrashRetention:       trashRetention,
+		AdminEmails:             adminEmails,
+		TrustedProxies:          trustedProxies,
+		ReportRateLimit:         reportRateLimit,
+		ReportURLRateLimit:      reportURLRateLimit,
+		ReportSuspendThreshold:  reportSuspendThreshold,
+		DataExportRetention:     dataExportRetention,
 	}
 
-	fmt.Printf("===========================\n")
+	fields := logrus.Fields{}
 	v := reflect.ValueOf(env)
 	for i := 0; i < v.NumField(); i++ {
-		fmt.Printf("%v: %v\n", v.Type().Field(i).Name, v.Field(i).Interface())
+		field := v.Type().Field(i)
+		if field.Tag.Get("redact") == "true" {
+			fields[field.Name] = logging.Redact(v.Field(i).String())
+			continue
+		}
+		fields[field.Name] = fmt.Sprintf("%v", v.Field(i).Interface())
 	}
-	fmt.Printf("===========================\n")
+	logrus.WithFields(fields).Info("Configuration loaded")
 
 	return env
 }
@@ -235,19 +457,19 @@ func getRedisHeroku() (bool, RedisHerokuConf) {
 
 	redisHost := matches[3]
 	if redisHost == "" {
-		log.Printf("[getRedisHeroku] %s - HOST is empty. Default as \"localhost\"\n", key)
+		logrus.Infof("[getRedisHeroku] %s - HOST is empty. Default as \"localhost\"", key)
 		redisHost = "localhost"
 	}
 
 	redisPort := matches[4]
 	if redisPort == "" {
-		log.Printf("[getRedisHeroku] %s - PORT is empty. Default as \"6379\"\n", key)
+		logrus.Infof("[getRedisHeroku] %s - PORT is empty. Default as \"6379\"", key)
 		redisPort = "6379"
 	}
 
 	redisPass := matches[2]
 	if redisPass == "" {
-		log.Printf("[getRedisHeroku] %s - PASSWORD is empty\n", key)
+		logrus.Infof("[getRedisHeroku] %s - PASSWORD is empty", key)
 	}
 
 	return true, RedisHerokuConf{
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"github.com/jinzhu/gorm"
+	"strings"
+)
+
+// filterUsers scopes query to users matching query.Search
+func (g *gormService) filterUsers(query UserQuery) *gorm.DB {
+	filtered := g.db.Model(&gormUser{})
+	if query.Search != "" {
+		filtered = filtered.Where("email LIKE ?", "%"+escapeLike(strings.ToLower(query.Search))+"%")
+	}
+	return filtered
+}
+
+func (g *gormService) GetUsers(query UserQuery) (uint64, []User, error) {
+	var count uint64
+	if err := g.filterUsers(query).Count(&count).Error; err != nil {
+		return 0, nil, err
+	}
+
+	var gormUsers []gormUser
+	if err := g.filterUsers(query).Order("email").Offset(query.Offset).Limit(query.Limit).Find(&gormUsers).Error; err != nil {
+		return 0, nil, err
+	}
+
+	users := make([]User, len(gormUsers))
+	for i, user := range gormUsers {
+		users[i] = user.toUser()
+	}
+
+	return count, users, nil
+}
+
+func (g *gormService) SetUserRole(userID string, role string) error {
+	return g.updateUserColumn(userID, "role", role)
+}
+
+func (g *gormService) SetUserDisabled(userID string, disabled bool) error {
+	return g.updateUserColumn(userID, "disabled", disabled)
+}
+
+func (g *gormService) updateUserColumn(userID string, column string, value interface{}) error {
+	var count int
+	if err := g.db.Model(&gormUser{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return NewRecordNotFoundError()
+	}
+
+	return g.db.Model(&gormUser{}).Where("user_id = ?", userID).UpdateColumn(column, value).Error
+}
+
+// TransferURLs moves every url of fromWorkspaceID on the default domain, deleted ones included, to toWorkspaceID
+// and returns how many were moved. Urls on custom domains stay, as their domains are not transferred.
+func (g *gormService) TransferURLs(fromWorkspaceID string, toWorkspaceID string) (uint64, error) {
+	execute := g.db.Unscoped().Model(&gormURL{}).Where("owner = ? AND domain = ''", fromWorkspaceID).UpdateColumn("owner", toWorkspaceID)
+	if err := execute.Error; err != nil {
+		return 0, err
+	}
+
+	return uint64(execute.RowsAffected), nil
+}
+
+// PurgeURL permanently removes url right away, whether in the trash or not. Its shorten url is kept as a tombstone.
+func (g *gormService) PurgeURL(shortenURL string) error {
+	var count int
+	if err := g.db.Unscoped().Model(&gormURL{}).Where("shorten_url = ?", shortenURL).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return NewRecordNotFoundError()
+	}
+
+	return g.purgeURL(shortenURL)
+}
+
+func (g *gormService) GetSystemStats() (SystemStats, error) {
+	var stats SystemStats
+	counts := []struct {
+		count *uint64
+		query *gorm.DB
+	}{
+		{&stats.Users, g.db.Model(&gormUser{})},
+		{&stats.DisabledUsers, g.db.Model(&gormUser{}).Where("disabled = ?", true)},
+		{&stats.Workspaces, g.db.Model(&gormWorkspace{})},
+		{&stats.URLs, g.db.Model(&gormURL{})},
+		{&stats.DeletedURLs, g.db.Unscoped().Model(&gormURL{}).Where("deleted_at IS NOT NULL")},
+		{&stats.Domains, g.db.Model(&gormDomain{})},
+		{&stats.Pages, g.db.Model(&gormPage{})},
+	}
+	for _, c := range counts {
+		if err := c.query.Count(c.count).Error; err != nil {
+			return SystemStats{}, err
+		}
+	}
+
+	if err := g.db.Model(&gormURL{}).Select("COALESCE(SUM(count), 0)").Row().Scan(&stats.Hits); err != nil {
+		return SystemStats{}, err
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,86 @@
+package database
+
+import "time"
+
+// gormAuditEntry is only ever inserted, entries outlive the users and urls they refer to.
+type gormAuditEntry struct {
+	ID         uint64 `gorm:"primary_key"`
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     string `gorm:"type:text"`
+	After      string `gorm:"type:text"`
+	IP         string
+	UserAgent  string `gorm:"type:text"`
+	CreatedAt  time.Time
+}
+
+func (e gormAuditEntry) toAuditEntry() AuditEntry {
+	return AuditEntry{
+		ID:         e.ID,
+		ActorID:    e.ActorID,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		Before:     e.Before,
+		After:      e.After,
+		IP:         e.IP,
+		UserAgent:  e.UserAgent,
+		CreatedAt:  e.CreatedAt,
+	}
+}
+
+func (g *gormService) initAudit() {
+	if hasAuditEntryTable := g.db.HasTable(&gormAuditEntry{}); !hasAuditEntryTable {
+		g.db.CreateTable(&gormAuditEntry{})
+		g.db.Model(&gormAuditEntry{}).AddIndex("idx_actor_id", "actor_id")
+		g.db.Model(&gormAuditEntry{}).AddIndex("idx_target", "target_type", "target_id")
+	}
+}
+
+func (g *gormService) CreateAuditEntry(entry AuditEntry) error {
+	e := gormAuditEntry{
+		ActorID:    entry.ActorID,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Before:     entry.Before,
+		After:      entry.After,
+		IP:         entry.IP,
+		UserAgent:  entry.UserAgent,
+		CreatedAt:  entry.CreatedAt,
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	return g.db.Create(&e).Error
+}
+
+func (g *gormService) GetAuditEntries(query AuditQuery) ([]AuditEntry, error) {
+	filtered := g.db.Model(&gormAuditEntry{})
+	if query.ActorID != "" {
+		filtered = filtered.Where("actor_id = ?", query.ActorID)
+	}
+	if query.Action != "" {
+		filtered = filtered.Where("action = ?", query.Action)
+	}
+	if query.TargetID != "" {
+		filtered = filtered.Where("target_id = ?", query.TargetID)
+	}
+	if query.BeforeID > 0 {
+		filtered = filtered.Where("id < ?", query.BeforeID)
+	}
+
+	var gormEntries []gormAuditEntry
+	if err := filtered.Order("id desc").Limit(query.Limit).Find(&gormEntries).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]AuditEntry, len(gormEntries))
+	for i, entry := range gormEntries {
+		entries[i] = entry.toAuditEntry()
+	}
+
+	return entries, nil
+}
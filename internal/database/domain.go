@@ -0,0 +1,123 @@
+package database
+
+import (
+	"github.com/jinzhu/gorm"
+	"time"
+)
+
+// gormDomain is the claim of a workspace on a hostname. Several workspaces can claim a hostname until one of them
+// verifies it, the other claims are dropped then.
+type gormDomain struct {
+	Hostname          string `gorm:"primary_key"`
+	WorkspaceID       string `gorm:"primary_key"`
+	VerificationToken string
+	VerifiedAt        *time.Time
+	CreatedAt         time.Time
+}
+
+func (d gormDomain) toDomain() Domain {
+	domain := Domain{
+		Hostname:          d.Hostname,
+		WorkspaceID:       d.WorkspaceID,
+		VerificationToken: d.VerificationToken,
+		CreatedAt:         d.CreatedAt,
+	}
+	if d.VerifiedAt != nil {
+		domain.VerifiedAt = *d.VerifiedAt
+	}
+	return domain
+}
+
+func (g *gormService) initDomains() {
+	if hasDomainTable := g.db.HasTable(&gormDomain{}); !hasDomainTable {
+		g.db.CreateTable(&gormDomain{})
+		g.db.Model(&gormDomain{}).AddIndex("idx_workspace_id", "workspace_id")
+	}
+
+	// note: claims were keyed by hostname alone before several workspaces could claim one
+	var keyColumns int
+	g.db.Raw("SELECT COUNT(*) FROM information_schema.key_column_usage " +
+		"WHERE table_schema = DATABASE() AND table_name = 'gorm_domains' AND constraint_name = 'PRIMARY'").Row().Scan(&keyColumns)
+	if keyColumns == 1 {
+		g.db.Exec("ALTER TABLE gorm_domains DROP PRIMARY KEY, ADD PRIMARY KEY (hostname, workspace_id)")
+	}
+}
+
+func (g *gormService) CreateDomain(domain Domain) error {
+	d := gormDomain{
+		Hostname:          domain.Hostname,
+		WorkspaceID:       domain.WorkspaceID,
+		VerificationToken: domain.VerificationToken,
+	}
+	return g.db.Create(&d).Error
+}
+
+// GetDomain queries the verified registration of hostname, pending claims are not found.
+func (g *gormService) GetDomain(hostname string) (*Domain, error) {
+	return g.queryDomain(g.db.Where("hostname = ? AND verified_at IS NOT NULL", hostname))
+}
+
+// GetWorkspaceDomain queries the claim of workspace on hostname, verified or not.
+func (g *gormService) GetWorkspaceDomain(workspaceID string, hostname string) (*Domain, error) {
+	return g.queryDomain(g.db.Where("hostname = ? AND workspace_id = ?", hostname, workspaceID))
+}
+
+func (g *gormService) queryDomain(query *gorm.DB) (*Domain, error) {
+	var d gormDomain
+	execute := query.First(&d)
+
+	if execute.RecordNotFound() {
+		return nil, NewRecordNotFoundError()
+	}
+
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	domain := d.toDomain()
+	return &domain, nil
+}
+
+func (g *gormService) GetDomainsInWorkspace(workspaceID string) ([]Domain, error) {
+	var ds []gormDomain
+	if err := g.db.Where("workspace_id = ?", workspaceID).Order("hostname").Find(&ds).Error; err != nil {
+		return nil, err
+	}
+
+	domains := make([]Domain, len(ds))
+	for i, d := range ds {
+		domains[i] = d.toDomain()
+	}
+	return domains, nil
+}
+
+// MarkDomainVerified gives hostname to workspace and drops the claims of other workspaces on it.
+// DomainTakenError is returned if another workspace verified it first.
+func (g *gormService) MarkDomainVerified(workspaceID string, hostname string, verifiedAt time.Time) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		var claims []gormDomain
+		if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("hostname = ?", hostname).Find(&claims).Error; err != nil {
+			return err
+		}
+		for _, claim := range claims {
+			if claim.WorkspaceID != workspaceID && claim.VerifiedAt != nil {
+				return NewDomainTakenError()
+			}
+		}
+
+		if err := tx.Where("hostname = ? AND workspace_id <> ?", hostname, workspaceID).Delete(&gormDomain{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&gormDomain{}).Where("hostname = ? AND workspace_id = ?", hostname, workspaceID).UpdateColumn("verified_at", verifiedAt).Error
+	})
+}
+
+func (g *gormService) CountURLsOnDomain(hostname string) (uint64, error) {
+	var count uint64
+	err := g.db.Model(&gormURL{}).Where("domain = ?", hostname).Count(&count).Error
+	return count, err
+}
+
+func (g *gormService) DeleteDomain(workspaceID string, hostname string) error {
+	return g.db.Where("hostname = ? AND workspace_id = ?", hostname, workspaceID).Delete(&gormDomain{}).Error
+}
@@ -11,3 +11,42 @@ func (r RecordNotFoundError) Error() string {
 func NewRecordNotFoundError() RecordNotFoundError {
 	return RecordNotFoundError{s: "Record not found in database"}
 }
+
+// ShortenURLTakenError returns when a shorten url was already issued, possibly to a url deleted since.
+type ShortenURLTakenError struct {
+	s string
+}
+
+func (r ShortenURLTakenError) Error() string {
+	return r.s
+}
+
+func NewShortenURLTakenError() ShortenURLTakenError {
+	return ShortenURLTakenError{s: "Shorten url already issued"}
+}
+
+// DomainTakenError returns when a domain was verified by another workspace.
+type DomainTakenError struct {
+	s string
+}
+
+func (r DomainTakenError) Error() string {
+	return r.s
+}
+
+func NewDomainTakenError() DomainTakenError {
+	return DomainTakenError{s: "Domain verified by another workspace"}
+}
+
+// LastOwnerError returns when removing a user would leave a shared workspace without owner.
+type LastOwnerError struct {
+	s string
+}
+
+func (r LastOwnerError) Error() string {
+	return r.s
+}
+
+func NewLastOwnerError() LastOwnerError {
+	return LastOwnerError{s: "User is the last owner of a shared workspace"}
+}
@@ -0,0 +1,27 @@
+package database
+
+// CountRowsOfURL counts the rows about shortenURL left in each table attached to urls, its tombstone aside.
+func CountRowsOfURL(db MySQLService, shortenURL string) (map[string]int, error) {
+	g := db.(*gormService)
+	tables := map[string]interface{}{
+		"urls":               &gormURL{},
+		"tags":               &gormURLTag{},
+		"rules":              &gormURLRule{},
+		"variants":           &gormURLVariant{},
+		"page links":         &gormPageLink{},
+		"schedule windows":   &gormURLScheduleWindow{},
+		"checks":             &gormURLCheck{},
+		"reports":            &gormReport{},
+		"webhook milestones": &gormWebhookMilestone{},
+	}
+
+	counts := map[string]int{}
+	for table, model := range tables {
+		var count int
+		if err := g.db.Unscoped().Model(model).Where("shorten_url = ?", shortenURL).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		counts[table] = count
+	}
+	return counts, nil
+}
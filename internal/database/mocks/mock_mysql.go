@@ -7,6 +7,7 @@ package mock_database
 import (
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 	database "url-shortener/internal/database"
 )
 
@@ -91,33 +92,33 @@ func (mr *MockMySQLServiceMockRecorder) GetUserWithID(userId interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserWithID", reflect.TypeOf((*MockMySQLService)(nil).GetUserWithID), userId)
 }
 
-// GetURLIfExistsWithUser mocks base method
-func (m *MockMySQLService) GetURLIfExistsWithUser(user database.User, oriURL string) (*database.URL, error) {
+// GetURLIfExistsInWorkspace mocks base method
+func (m *MockMySQLService) GetURLIfExistsInWorkspace(workspaceID, domain, oriURL string) (*database.URL, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetURLIfExistsWithUser", user, oriURL)
+	ret := m.ctrl.Call(m, "GetURLIfExistsInWorkspace", workspaceID, domain, oriURL)
 	ret0, _ := ret[0].(*database.URL)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetURLIfExistsWithUser indicates an expected call of GetURLIfExistsWithUser
-func (mr *MockMySQLServiceMockRecorder) GetURLIfExistsWithUser(user, oriURL interface{}) *gomock.Call {
+// GetURLIfExistsInWorkspace indicates an expected call of GetURLIfExistsInWorkspace
+func (mr *MockMySQLServiceMockRecorder) GetURLIfExistsInWorkspace(workspaceID, domain, oriURL interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLIfExistsWithUser", reflect.TypeOf((*MockMySQLService)(nil).GetURLIfExistsWithUser), user, oriURL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLIfExistsInWorkspace", reflect.TypeOf((*MockMySQLService)(nil).GetURLIfExistsInWorkspace), workspaceID, domain, oriURL)
 }
 
 // CreateURL mocks base method
-func (m *MockMySQLService) CreateURL(oriURL, shortenURL string, user database.User) error {
+func (m *MockMySQLService) CreateURL(oriURL, shortenURL, domain, workspaceID string, creator database.User) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateURL", oriURL, shortenURL, user)
+	ret := m.ctrl.Call(m, "CreateURL", oriURL, shortenURL, domain, workspaceID, creator)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateURL indicates an expected call of CreateURL
-func (mr *MockMySQLServiceMockRecorder) CreateURL(oriURL, shortenURL, user interface{}) *gomock.Call {
+func (mr *MockMySQLServiceMockRecorder) CreateURL(oriURL, shortenURL, domain, workspaceID, creator interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateURL", reflect.TypeOf((*MockMySQLService)(nil).CreateURL), oriURL, shortenURL, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateURL", reflect.TypeOf((*MockMySQLService)(nil).CreateURL), oriURL, shortenURL, domain, workspaceID, creator)
 }
 
 // GetURLWithShortenURL mocks base method
@@ -135,20 +136,1083 @@ func (mr *MockMySQLServiceMockRecorder) GetURLWithShortenURL(shortenURL interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLWithShortenURL", reflect.TypeOf((*MockMySQLService)(nil).GetURLWithShortenURL), shortenURL)
 }
 
-// GetURLsWithUser mocks base method
-func (m *MockMySQLService) GetURLsWithUser(user database.User, offset, limit uint64) (uint64, []database.URL, error) {
+// GetURLsWithShortenURLs mocks base method
+func (m *MockMySQLService) GetURLsWithShortenURLs(shortenURLs []string) ([]database.URL, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetURLsWithUser", user, offset, limit)
+	ret := m.ctrl.Call(m, "GetURLsWithShortenURLs", shortenURLs)
+	ret0, _ := ret[0].([]database.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetURLsWithShortenURLs indicates an expected call of GetURLsWithShortenURLs
+func (mr *MockMySQLServiceMockRecorder) GetURLsWithShortenURLs(shortenURLs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLsWithShortenURLs", reflect.TypeOf((*MockMySQLService)(nil).GetURLsWithShortenURLs), shortenURLs)
+}
+
+// GetDeletedURL mocks base method
+func (m *MockMySQLService) GetDeletedURL(shortenURL string) (*database.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedURL", shortenURL)
+	ret0, _ := ret[0].(*database.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedURL indicates an expected call of GetDeletedURL
+func (mr *MockMySQLServiceMockRecorder) GetDeletedURL(shortenURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedURL", reflect.TypeOf((*MockMySQLService)(nil).GetDeletedURL), shortenURL)
+}
+
+// GetDeletedURLsInWorkspace mocks base method
+func (m *MockMySQLService) GetDeletedURLsInWorkspace(workspaceID string) ([]database.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedURLsInWorkspace", workspaceID)
+	ret0, _ := ret[0].([]database.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedURLsInWorkspace indicates an expected call of GetDeletedURLsInWorkspace
+func (mr *MockMySQLServiceMockRecorder) GetDeletedURLsInWorkspace(workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedURLsInWorkspace", reflect.TypeOf((*MockMySQLService)(nil).GetDeletedURLsInWorkspace), workspaceID)
+}
+
+// UpdateURL mocks base method
+func (m *MockMySQLService) UpdateURL(url *database.URL) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateURL", url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateURL indicates an expected call of UpdateURL
+func (mr *MockMySQLServiceMockRecorder) UpdateURL(url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateURL", reflect.TypeOf((*MockMySQLService)(nil).UpdateURL), url)
+}
+
+// IncreaseURLCount mocks base method
+func (m *MockMySQLService) IncreaseURLCount(shortenURL string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncreaseURLCount", shortenURL)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncreaseURLCount indicates an expected call of IncreaseURLCount
+func (mr *MockMySQLServiceMockRecorder) IncreaseURLCount(shortenURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseURLCount", reflect.TypeOf((*MockMySQLService)(nil).IncreaseURLCount), shortenURL)
+}
+
+// UpdateURLDetails mocks base method
+func (m *MockMySQLService) UpdateURLDetails(shortenURL string, details database.URLDetails) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateURLDetails", shortenURL, details)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateURLDetails indicates an expected call of UpdateURLDetails
+func (mr *MockMySQLServiceMockRecorder) UpdateURLDetails(shortenURL, details interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateURLDetails", reflect.TypeOf((*MockMySQLService)(nil).UpdateURLDetails), shortenURL, details)
+}
+
+// GetURLsInWorkspace mocks base method
+func (m *MockMySQLService) GetURLsInWorkspace(workspaceID string, filter database.URLFilter, offset, limit uint64) (uint64, []database.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetURLsInWorkspace", workspaceID, filter, offset, limit)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].([]database.URL)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetURLsInWorkspace indicates an expected call of GetURLsInWorkspace
+func (mr *MockMySQLServiceMockRecorder) GetURLsInWorkspace(workspaceID, filter, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLsInWorkspace", reflect.TypeOf((*MockMySQLService)(nil).GetURLsInWorkspace), workspaceID, filter, offset, limit)
+}
+
+// ListURLsInWorkspace mocks base method
+func (m *MockMySQLService) ListURLsInWorkspace(workspaceID string, query database.URLListQuery) (uint64, []database.URL, *database.URLCursor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListURLsInWorkspace", workspaceID, query)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].([]database.URL)
+	ret2, _ := ret[2].(*database.URLCursor)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// ListURLsInWorkspace indicates an expected call of ListURLsInWorkspace
+func (mr *MockMySQLServiceMockRecorder) ListURLsInWorkspace(workspaceID, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListURLsInWorkspace", reflect.TypeOf((*MockMySQLService)(nil).ListURLsInWorkspace), workspaceID, query)
+}
+
+// GetTagStatsInWorkspace mocks base method
+func (m *MockMySQLService) GetTagStatsInWorkspace(workspaceID string) ([]database.TagStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTagStatsInWorkspace", workspaceID)
+	ret0, _ := ret[0].([]database.TagStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTagStatsInWorkspace indicates an expected call of GetTagStatsInWorkspace
+func (mr *MockMySQLServiceMockRecorder) GetTagStatsInWorkspace(workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTagStatsInWorkspace", reflect.TypeOf((*MockMySQLService)(nil).GetTagStatsInWorkspace), workspaceID)
+}
+
+// UpdateURLMetadata mocks base method
+func (m *MockMySQLService) UpdateURLMetadata(shortenURL string, metadata database.URLMetadata) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateURLMetadata", shortenURL, metadata)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateURLMetadata indicates an expected call of UpdateURLMetadata
+func (mr *MockMySQLServiceMockRecorder) UpdateURLMetadata(shortenURL, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateURLMetadata", reflect.TypeOf((*MockMySQLService)(nil).UpdateURLMetadata), shortenURL, metadata)
+}
+
+// GetURLsForMetadataRefresh mocks base method
+func (m *MockMySQLService) GetURLsForMetadataRefresh(fetchedBefore time.Time, limit uint64) ([]database.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetURLsForMetadataRefresh", fetchedBefore, limit)
+	ret0, _ := ret[0].([]database.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetURLsForMetadataRefresh indicates an expected call of GetURLsForMetadataRefresh
+func (mr *MockMySQLServiceMockRecorder) GetURLsForMetadataRefresh(fetchedBefore, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLsForMetadataRefresh", reflect.TypeOf((*MockMySQLService)(nil).GetURLsForMetadataRefresh), fetchedBefore, limit)
+}
+
+// GetURLsAfter mocks base method
+func (m *MockMySQLService) GetURLsAfter(shortenURL string, limit uint64) ([]database.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetURLsAfter", shortenURL, limit)
+	ret0, _ := ret[0].([]database.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetURLsAfter indicates an expected call of GetURLsAfter
+func (mr *MockMySQLServiceMockRecorder) GetURLsAfter(shortenURL, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLsAfter", reflect.TypeOf((*MockMySQLService)(nil).GetURLsAfter), shortenURL, limit)
+}
+
+// GetURLsInWorkspaceAfter mocks base method
+func (m *MockMySQLService) GetURLsInWorkspaceAfter(workspaceID, shortenURL string, limit uint64) ([]database.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetURLsInWorkspaceAfter", workspaceID, shortenURL, limit)
+	ret0, _ := ret[0].([]database.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetURLsInWorkspaceAfter indicates an expected call of GetURLsInWorkspaceAfter
+func (mr *MockMySQLServiceMockRecorder) GetURLsInWorkspaceAfter(workspaceID, shortenURL, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLsInWorkspaceAfter", reflect.TypeOf((*MockMySQLService)(nil).GetURLsInWorkspaceAfter), workspaceID, shortenURL, limit)
+}
+
+// ImportURL mocks base method
+func (m *MockMySQLService) ImportURL(url database.URL, creator database.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportURL", url, creator)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportURL indicates an expected call of ImportURL
+func (mr *MockMySQLServiceMockRecorder) ImportURL(url, creator interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportURL", reflect.TypeOf((*MockMySQLService)(nil).ImportURL), url, creator)
+}
+
+// GetGoogleUserWithUserID mocks base method
+func (m *MockMySQLService) GetGoogleUserWithUserID(userID string) (*database.GoogleUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGoogleUserWithUserID", userID)
+	ret0, _ := ret[0].(*database.GoogleUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGoogleUserWithUserID indicates an expected call of GetGoogleUserWithUserID
+func (mr *MockMySQLServiceMockRecorder) GetGoogleUserWithUserID(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGoogleUserWithUserID", reflect.TypeOf((*MockMySQLService)(nil).GetGoogleUserWithUserID), userID)
+}
+
+// GetURLsOfUserAfter mocks base method
+func (m *MockMySQLService) GetURLsOfUserAfter(userID, shortenURL string, limit uint64) ([]database.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetURLsOfUserAfter", userID, shortenURL, limit)
+	ret0, _ := ret[0].([]database.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetURLsOfUserAfter indicates an expected call of GetURLsOfUserAfter
+func (mr *MockMySQLServiceMockRecorder) GetURLsOfUserAfter(userID, shortenURL, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLsOfUserAfter", reflect.TypeOf((*MockMySQLService)(nil).GetURLsOfUserAfter), userID, shortenURL, limit)
+}
+
+// CreateDataExport mocks base method
+func (m *MockMySQLService) CreateDataExport(export database.DataExport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDataExport", export)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDataExport indicates an expected call of CreateDataExport
+func (mr *MockMySQLServiceMockRecorder) CreateDataExport(export interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDataExport", reflect.TypeOf((*MockMySQLService)(nil).CreateDataExport), export)
+}
+
+// GetDataExport mocks base method
+func (m *MockMySQLService) GetDataExport(id string) (*database.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataExport", id)
+	ret0, _ := ret[0].(*database.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataExport indicates an expected call of GetDataExport
+func (mr *MockMySQLServiceMockRecorder) GetDataExport(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataExport", reflect.TypeOf((*MockMySQLService)(nil).GetDataExport), id)
+}
+
+// GetDataExportsWithUser mocks base method
+func (m *MockMySQLService) GetDataExportsWithUser(userID string) ([]database.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataExportsWithUser", userID)
+	ret0, _ := ret[0].([]database.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataExportsWithUser indicates an expected call of GetDataExportsWithUser
+func (mr *MockMySQLServiceMockRecorder) GetDataExportsWithUser(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataExportsWithUser", reflect.TypeOf((*MockMySQLService)(nil).GetDataExportsWithUser), userID)
+}
+
+// GetPendingDataExports mocks base method
+func (m *MockMySQLService) GetPendingDataExports(limit uint64) ([]database.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingDataExports", limit)
+	ret0, _ := ret[0].([]database.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingDataExports indicates an expected call of GetPendingDataExports
+func (mr *MockMySQLServiceMockRecorder) GetPendingDataExports(limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingDataExports", reflect.TypeOf((*MockMySQLService)(nil).GetPendingDataExports), limit)
+}
+
+// GetDataExportArchive mocks base method
+func (m *MockMySQLService) GetDataExportArchive(id string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataExportArchive", id)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataExportArchive indicates an expected call of GetDataExportArchive
+func (mr *MockMySQLServiceMockRecorder) GetDataExportArchive(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataExportArchive", reflect.TypeOf((*MockMySQLService)(nil).GetDataExportArchive), id)
+}
+
+// CompleteDataExport mocks base method
+func (m *MockMySQLService) CompleteDataExport(id string, archive []byte, completedAt, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteDataExport", id, archive, completedAt, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteDataExport indicates an expected call of CompleteDataExport
+func (mr *MockMySQLServiceMockRecorder) CompleteDataExport(id, archive, completedAt, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteDataExport", reflect.TypeOf((*MockMySQLService)(nil).CompleteDataExport), id, archive, completedAt, expiresAt)
+}
+
+// FailDataExport mocks base method
+func (m *MockMySQLService) FailDataExport(id string, failedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailDataExport", id, failedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailDataExport indicates an expected call of FailDataExport
+func (mr *MockMySQLServiceMockRecorder) FailDataExport(id, failedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailDataExport", reflect.TypeOf((*MockMySQLService)(nil).FailDataExport), id, failedAt)
+}
+
+// DeleteDataExportsBefore mocks base method
+func (m *MockMySQLService) DeleteDataExportsBefore(createdBefore time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDataExportsBefore", createdBefore)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDataExportsBefore indicates an expected call of DeleteDataExportsBefore
+func (mr *MockMySQLServiceMockRecorder) DeleteDataExportsBefore(createdBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDataExportsBefore", reflect.TypeOf((*MockMySQLService)(nil).DeleteDataExportsBefore), createdBefore)
+}
+
+// CreateURLCheck mocks base method
+func (m *MockMySQLService) CreateURLCheck(check database.URLCheck) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateURLCheck", check)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateURLCheck indicates an expected call of CreateURLCheck
+func (mr *MockMySQLServiceMockRecorder) CreateURLCheck(check interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateURLCheck", reflect.TypeOf((*MockMySQLService)(nil).CreateURLCheck), check)
+}
+
+// GetURLChecks mocks base method
+func (m *MockMySQLService) GetURLChecks(shortenURL string, limit uint64) ([]database.URLCheck, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetURLChecks", shortenURL, limit)
+	ret0, _ := ret[0].([]database.URLCheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetURLChecks indicates an expected call of GetURLChecks
+func (mr *MockMySQLServiceMockRecorder) GetURLChecks(shortenURL, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLChecks", reflect.TypeOf((*MockMySQLService)(nil).GetURLChecks), shortenURL, limit)
+}
+
+// DeleteURLChecksBefore mocks base method
+func (m *MockMySQLService) DeleteURLChecksBefore(checkedBefore time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteURLChecksBefore", checkedBefore)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteURLChecksBefore indicates an expected call of DeleteURLChecksBefore
+func (mr *MockMySQLServiceMockRecorder) DeleteURLChecksBefore(checkedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteURLChecksBefore", reflect.TypeOf((*MockMySQLService)(nil).DeleteURLChecksBefore), checkedBefore)
+}
+
+// GetBrokenURLsToAlert mocks base method
+func (m *MockMySQLService) GetBrokenURLsToAlert(brokenBefore time.Time) ([]database.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBrokenURLsToAlert", brokenBefore)
+	ret0, _ := ret[0].([]database.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBrokenURLsToAlert indicates an expected call of GetBrokenURLsToAlert
+func (mr *MockMySQLServiceMockRecorder) GetBrokenURLsToAlert(brokenBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBrokenURLsToAlert", reflect.TypeOf((*MockMySQLService)(nil).GetBrokenURLsToAlert), brokenBefore)
+}
+
+// MarkURLsAlerted mocks base method
+func (m *MockMySQLService) MarkURLsAlerted(shortenURLs []string, alertedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkURLsAlerted", shortenURLs, alertedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkURLsAlerted indicates an expected call of MarkURLsAlerted
+func (mr *MockMySQLServiceMockRecorder) MarkURLsAlerted(shortenURLs, alertedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkURLsAlerted", reflect.TypeOf((*MockMySQLService)(nil).MarkURLsAlerted), shortenURLs, alertedAt)
+}
+
+// SetDeadLinkAlertsDisabled mocks base method
+func (m *MockMySQLService) SetDeadLinkAlertsDisabled(user database.User, disabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDeadLinkAlertsDisabled", user, disabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDeadLinkAlertsDisabled indicates an expected call of SetDeadLinkAlertsDisabled
+func (mr *MockMySQLServiceMockRecorder) SetDeadLinkAlertsDisabled(user, disabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeadLinkAlertsDisabled", reflect.TypeOf((*MockMySQLService)(nil).SetDeadLinkAlertsDisabled), user, disabled)
+}
+
+// CreateWorkspace mocks base method
+func (m *MockMySQLService) CreateWorkspace(workspace database.Workspace, owner database.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWorkspace", workspace, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWorkspace indicates an expected call of CreateWorkspace
+func (mr *MockMySQLServiceMockRecorder) CreateWorkspace(workspace, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkspace", reflect.TypeOf((*MockMySQLService)(nil).CreateWorkspace), workspace, owner)
+}
+
+// GetMembershipsWithUser mocks base method
+func (m *MockMySQLService) GetMembershipsWithUser(user database.User) ([]database.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembershipsWithUser", user)
+	ret0, _ := ret[0].([]database.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembershipsWithUser indicates an expected call of GetMembershipsWithUser
+func (mr *MockMySQLServiceMockRecorder) GetMembershipsWithUser(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembershipsWithUser", reflect.TypeOf((*MockMySQLService)(nil).GetMembershipsWithUser), user)
+}
+
+// GetWorkspaceRole mocks base method
+func (m *MockMySQLService) GetWorkspaceRole(workspaceID, userID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceRole", workspaceID, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceRole indicates an expected call of GetWorkspaceRole
+func (mr *MockMySQLServiceMockRecorder) GetWorkspaceRole(workspaceID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceRole", reflect.TypeOf((*MockMySQLService)(nil).GetWorkspaceRole), workspaceID, userID)
+}
+
+// GetWorkspaceMembers mocks base method
+func (m *MockMySQLService) GetWorkspaceMembers(workspaceID string) ([]database.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceMembers", workspaceID)
+	ret0, _ := ret[0].([]database.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceMembers indicates an expected call of GetWorkspaceMembers
+func (mr *MockMySQLServiceMockRecorder) GetWorkspaceMembers(workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceMembers", reflect.TypeOf((*MockMySQLService)(nil).GetWorkspaceMembers), workspaceID)
+}
+
+// SetWorkspaceMemberRole mocks base method
+func (m *MockMySQLService) SetWorkspaceMemberRole(workspaceID, userID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWorkspaceMemberRole", workspaceID, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWorkspaceMemberRole indicates an expected call of SetWorkspaceMemberRole
+func (mr *MockMySQLServiceMockRecorder) SetWorkspaceMemberRole(workspaceID, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWorkspaceMemberRole", reflect.TypeOf((*MockMySQLService)(nil).SetWorkspaceMemberRole), workspaceID, userID, role)
+}
+
+// DeleteWorkspaceMember mocks base method
+func (m *MockMySQLService) DeleteWorkspaceMember(workspaceID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWorkspaceMember", workspaceID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWorkspaceMember indicates an expected call of DeleteWorkspaceMember
+func (mr *MockMySQLServiceMockRecorder) DeleteWorkspaceMember(workspaceID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWorkspaceMember", reflect.TypeOf((*MockMySQLService)(nil).DeleteWorkspaceMember), workspaceID, userID)
+}
+
+// CreateWorkspaceInvitation mocks base method
+func (m *MockMySQLService) CreateWorkspaceInvitation(invitation database.WorkspaceInvitation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWorkspaceInvitation", invitation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWorkspaceInvitation indicates an expected call of CreateWorkspaceInvitation
+func (mr *MockMySQLServiceMockRecorder) CreateWorkspaceInvitation(invitation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkspaceInvitation", reflect.TypeOf((*MockMySQLService)(nil).CreateWorkspaceInvitation), invitation)
+}
+
+// AcceptWorkspaceInvitation mocks base method
+func (m *MockMySQLService) AcceptWorkspaceInvitation(tokenHash string, user database.User) (*database.WorkspaceInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptWorkspaceInvitation", tokenHash, user)
+	ret0, _ := ret[0].(*database.WorkspaceInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptWorkspaceInvitation indicates an expected call of AcceptWorkspaceInvitation
+func (mr *MockMySQLServiceMockRecorder) AcceptWorkspaceInvitation(tokenHash, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptWorkspaceInvitation", reflect.TypeOf((*MockMySQLService)(nil).AcceptWorkspaceInvitation), tokenHash, user)
+}
+
+// CreateDomain mocks base method
+func (m *MockMySQLService) CreateDomain(domain database.Domain) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDomain", domain)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDomain indicates an expected call of CreateDomain
+func (mr *MockMySQLServiceMockRecorder) CreateDomain(domain interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDomain", reflect.TypeOf((*MockMySQLService)(nil).CreateDomain), domain)
+}
+
+// GetDomain mocks base method
+func (m *MockMySQLService) GetDomain(hostname string) (*database.Domain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDomain", hostname)
+	ret0, _ := ret[0].(*database.Domain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDomain indicates an expected call of GetDomain
+func (mr *MockMySQLServiceMockRecorder) GetDomain(hostname interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDomain", reflect.TypeOf((*MockMySQLService)(nil).GetDomain), hostname)
+}
+
+// GetWorkspaceDomain mocks base method
+func (m *MockMySQLService) GetWorkspaceDomain(workspaceID, hostname string) (*database.Domain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceDomain", workspaceID, hostname)
+	ret0, _ := ret[0].(*database.Domain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceDomain indicates an expected call of GetWorkspaceDomain
+func (mr *MockMySQLServiceMockRecorder) GetWorkspaceDomain(workspaceID, hostname interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceDomain", reflect.TypeOf((*MockMySQLService)(nil).GetWorkspaceDomain), workspaceID, hostname)
+}
+
+// GetDomainsInWorkspace mocks base method
+func (m *MockMySQLService) GetDomainsInWorkspace(workspaceID string) ([]database.Domain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDomainsInWorkspace", workspaceID)
+	ret0, _ := ret[0].([]database.Domain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDomainsInWorkspace indicates an expected call of GetDomainsInWorkspace
+func (mr *MockMySQLServiceMockRecorder) GetDomainsInWorkspace(workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDomainsInWorkspace", reflect.TypeOf((*MockMySQLService)(nil).GetDomainsInWorkspace), workspaceID)
+}
+
+// MarkDomainVerified mocks base method
+func (m *MockMySQLService) MarkDomainVerified(workspaceID, hostname string, verifiedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDomainVerified", workspaceID, hostname, verifiedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDomainVerified indicates an expected call of MarkDomainVerified
+func (mr *MockMySQLServiceMockRecorder) MarkDomainVerified(workspaceID, hostname, verifiedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDomainVerified", reflect.TypeOf((*MockMySQLService)(nil).MarkDomainVerified), workspaceID, hostname, verifiedAt)
+}
+
+// CountURLsOnDomain mocks base method
+func (m *MockMySQLService) CountURLsOnDomain(hostname string) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountURLsOnDomain", hostname)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountURLsOnDomain indicates an expected call of CountURLsOnDomain
+func (mr *MockMySQLServiceMockRecorder) CountURLsOnDomain(hostname interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountURLsOnDomain", reflect.TypeOf((*MockMySQLService)(nil).CountURLsOnDomain), hostname)
+}
+
+// DeleteDomain mocks base method
+func (m *MockMySQLService) DeleteDomain(workspaceID, hostname string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDomain", workspaceID, hostname)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDomain indicates an expected call of DeleteDomain
+func (mr *MockMySQLServiceMockRecorder) DeleteDomain(workspaceID, hostname interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDomain", reflect.TypeOf((*MockMySQLService)(nil).DeleteDomain), workspaceID, hostname)
+}
+
+// SetURLRules mocks base method
+func (m *MockMySQLService) SetURLRules(shortenURL string, rules []database.RedirectRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetURLRules", shortenURL, rules)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetURLRules indicates an expected call of SetURLRules
+func (mr *MockMySQLServiceMockRecorder) SetURLRules(shortenURL, rules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetURLRules", reflect.TypeOf((*MockMySQLService)(nil).SetURLRules), shortenURL, rules)
+}
+
+// SetURLVariants mocks base method
+func (m *MockMySQLService) SetURLVariants(shortenURL string, variants []database.URLVariant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetURLVariants", shortenURL, variants)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetURLVariants indicates an expected call of SetURLVariants
+func (mr *MockMySQLServiceMockRecorder) SetURLVariants(shortenURL, variants interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetURLVariants", reflect.TypeOf((*MockMySQLService)(nil).SetURLVariants), shortenURL, variants)
+}
+
+// SetURLSchedule mocks base method
+func (m *MockMySQLService) SetURLSchedule(shortenURL string, schedule *database.Schedule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetURLSchedule", shortenURL, schedule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetURLSchedule indicates an expected call of SetURLSchedule
+func (mr *MockMySQLServiceMockRecorder) SetURLSchedule(shortenURL, schedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetURLSchedule", reflect.TypeOf((*MockMySQLService)(nil).SetURLSchedule), shortenURL, schedule)
+}
+
+// IncreaseURLVariantCount mocks base method
+func (m *MockMySQLService) IncreaseURLVariantCount(id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncreaseURLVariantCount", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncreaseURLVariantCount indicates an expected call of IncreaseURLVariantCount
+func (mr *MockMySQLServiceMockRecorder) IncreaseURLVariantCount(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseURLVariantCount", reflect.TypeOf((*MockMySQLService)(nil).IncreaseURLVariantCount), id)
+}
+
+// CreatePage mocks base method
+func (m *MockMySQLService) CreatePage(page database.Page) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePage", page)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePage indicates an expected call of CreatePage
+func (mr *MockMySQLServiceMockRecorder) CreatePage(page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePage", reflect.TypeOf((*MockMySQLService)(nil).CreatePage), page)
+}
+
+// GetPage mocks base method
+func (m *MockMySQLService) GetPage(slug string) (*database.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPage", slug)
+	ret0, _ := ret[0].(*database.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPage indicates an expected call of GetPage
+func (mr *MockMySQLServiceMockRecorder) GetPage(slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPage", reflect.TypeOf((*MockMySQLService)(nil).GetPage), slug)
+}
+
+// GetPagesInWorkspace mocks base method
+func (m *MockMySQLService) GetPagesInWorkspace(workspaceID string) ([]database.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPagesInWorkspace", workspaceID)
+	ret0, _ := ret[0].([]database.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPagesInWorkspace indicates an expected call of GetPagesInWorkspace
+func (mr *MockMySQLServiceMockRecorder) GetPagesInWorkspace(workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPagesInWorkspace", reflect.TypeOf((*MockMySQLService)(nil).GetPagesInWorkspace), workspaceID)
+}
+
+// UpdatePage mocks base method
+func (m *MockMySQLService) UpdatePage(page database.Page) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePage", page)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePage indicates an expected call of UpdatePage
+func (mr *MockMySQLServiceMockRecorder) UpdatePage(page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePage", reflect.TypeOf((*MockMySQLService)(nil).UpdatePage), page)
+}
+
+// DeletePage mocks base method
+func (m *MockMySQLService) DeletePage(slug string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePage", slug)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePage indicates an expected call of DeletePage
+func (mr *MockMySQLServiceMockRecorder) DeletePage(slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePage", reflect.TypeOf((*MockMySQLService)(nil).DeletePage), slug)
+}
+
+// CreateAuditEntry mocks base method
+func (m *MockMySQLService) CreateAuditEntry(entry database.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEntry", entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuditEntry indicates an expected call of CreateAuditEntry
+func (mr *MockMySQLServiceMockRecorder) CreateAuditEntry(entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEntry", reflect.TypeOf((*MockMySQLService)(nil).CreateAuditEntry), entry)
+}
+
+// GetAuditEntries mocks base method
+func (m *MockMySQLService) GetAuditEntries(query database.AuditQuery) ([]database.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEntries", query)
+	ret0, _ := ret[0].([]database.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEntries indicates an expected call of GetAuditEntries
+func (mr *MockMySQLServiceMockRecorder) GetAuditEntries(query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEntries", reflect.TypeOf((*MockMySQLService)(nil).GetAuditEntries), query)
+}
+
+// CreateWebhook mocks base method
+func (m *MockMySQLService) CreateWebhook(webhook database.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook
+func (mr *MockMySQLServiceMockRecorder) CreateWebhook(webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockMySQLService)(nil).CreateWebhook), webhook)
+}
+
+// GetWebhook mocks base method
+func (m *MockMySQLService) GetWebhook(id string) (*database.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", id)
+	ret0, _ := ret[0].(*database.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook
+func (mr *MockMySQLServiceMockRecorder) GetWebhook(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockMySQLService)(nil).GetWebhook), id)
+}
+
+// GetWebhooksInWorkspace mocks base method
+func (m *MockMySQLService) GetWebhooksInWorkspace(workspaceID string) ([]database.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooksInWorkspace", workspaceID)
+	ret0, _ := ret[0].([]database.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooksInWorkspace indicates an expected call of GetWebhooksInWorkspace
+func (mr *MockMySQLServiceMockRecorder) GetWebhooksInWorkspace(workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooksInWorkspace", reflect.TypeOf((*MockMySQLService)(nil).GetWebhooksInWorkspace), workspaceID)
+}
+
+// GetWebhooksWithEvent mocks base method
+func (m *MockMySQLService) GetWebhooksWithEvent(event string) ([]database.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooksWithEvent", event)
+	ret0, _ := ret[0].([]database.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooksWithEvent indicates an expected call of GetWebhooksWithEvent
+func (mr *MockMySQLServiceMockRecorder) GetWebhooksWithEvent(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooksWithEvent", reflect.TypeOf((*MockMySQLService)(nil).GetWebhooksWithEvent), event)
+}
+
+// UpdateWebhook mocks base method
+func (m *MockMySQLService) UpdateWebhook(webhook database.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhook", webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhook indicates an expected call of UpdateWebhook
+func (mr *MockMySQLServiceMockRecorder) UpdateWebhook(webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhook", reflect.TypeOf((*MockMySQLService)(nil).UpdateWebhook), webhook)
+}
+
+// DeleteWebhook mocks base method
+func (m *MockMySQLService) DeleteWebhook(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook
+func (mr *MockMySQLServiceMockRecorder) DeleteWebhook(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockMySQLService)(nil).DeleteWebhook), id)
+}
+
+// EnqueueWebhookDeliveries mocks base method
+func (m *MockMySQLService) EnqueueWebhookDeliveries(workspaceID, event, payload string) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueWebhookDeliveries", workspaceID, event, payload)
 	ret0, _ := ret[0].(uint64)
-	ret1, _ := ret[1].([]database.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueWebhookDeliveries indicates an expected call of EnqueueWebhookDeliveries
+func (mr *MockMySQLServiceMockRecorder) EnqueueWebhookDeliveries(workspaceID, event, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueWebhookDeliveries", reflect.TypeOf((*MockMySQLService)(nil).EnqueueWebhookDeliveries), workspaceID, event, payload)
+}
+
+// GetURLsReachingClicks mocks base method
+func (m *MockMySQLService) GetURLsReachingClicks(webhook database.Webhook, clicks, limit uint64) ([]database.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetURLsReachingClicks", webhook, clicks, limit)
+	ret0, _ := ret[0].([]database.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetURLsReachingClicks indicates an expected call of GetURLsReachingClicks
+func (mr *MockMySQLServiceMockRecorder) GetURLsReachingClicks(webhook, clicks, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLsReachingClicks", reflect.TypeOf((*MockMySQLService)(nil).GetURLsReachingClicks), webhook, clicks, limit)
+}
+
+// EnqueueWebhookClicksDelivery mocks base method
+func (m *MockMySQLService) EnqueueWebhookClicksDelivery(delivery database.WebhookDelivery, shortenURL string, clicks uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueWebhookClicksDelivery", delivery, shortenURL, clicks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnqueueWebhookClicksDelivery indicates an expected call of EnqueueWebhookClicksDelivery
+func (mr *MockMySQLServiceMockRecorder) EnqueueWebhookClicksDelivery(delivery, shortenURL, clicks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueWebhookClicksDelivery", reflect.TypeOf((*MockMySQLService)(nil).EnqueueWebhookClicksDelivery), delivery, shortenURL, clicks)
+}
+
+// GetDueWebhookDeliveries mocks base method
+func (m *MockMySQLService) GetDueWebhookDeliveries(now time.Time, limit uint64) ([]database.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueWebhookDeliveries", now, limit)
+	ret0, _ := ret[0].([]database.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueWebhookDeliveries indicates an expected call of GetDueWebhookDeliveries
+func (mr *MockMySQLServiceMockRecorder) GetDueWebhookDeliveries(now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueWebhookDeliveries", reflect.TypeOf((*MockMySQLService)(nil).GetDueWebhookDeliveries), now, limit)
+}
+
+// RecordWebhookDelivery mocks base method
+func (m *MockMySQLService) RecordWebhookDelivery(delivery database.WebhookDelivery, disableAfter int, at time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordWebhookDelivery", delivery, disableAfter, at)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordWebhookDelivery indicates an expected call of RecordWebhookDelivery
+func (mr *MockMySQLServiceMockRecorder) RecordWebhookDelivery(delivery, disableAfter, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordWebhookDelivery", reflect.TypeOf((*MockMySQLService)(nil).RecordWebhookDelivery), delivery, disableAfter, at)
+}
+
+// GetWebhookDeliveries mocks base method
+func (m *MockMySQLService) GetWebhookDeliveries(webhookID string, offset, limit uint64) (uint64, []database.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", webhookID, offset, limit)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].([]database.WebhookDelivery)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries
+func (mr *MockMySQLServiceMockRecorder) GetWebhookDeliveries(webhookID, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockMySQLService)(nil).GetWebhookDeliveries), webhookID, offset, limit)
+}
+
+// DeleteWebhookDeliveriesBefore mocks base method
+func (m *MockMySQLService) DeleteWebhookDeliveriesBefore(createdBefore time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookDeliveriesBefore", createdBefore)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookDeliveriesBefore indicates an expected call of DeleteWebhookDeliveriesBefore
+func (mr *MockMySQLServiceMockRecorder) DeleteWebhookDeliveriesBefore(createdBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookDeliveriesBefore", reflect.TypeOf((*MockMySQLService)(nil).DeleteWebhookDeliveriesBefore), createdBefore)
+}
+
+// CreateReport mocks base method
+func (m *MockMySQLService) CreateReport(report database.Report) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReport", report)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateReport indicates an expected call of CreateReport
+func (mr *MockMySQLServiceMockRecorder) CreateReport(report interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReport", reflect.TypeOf((*MockMySQLService)(nil).CreateReport), report)
+}
+
+// CountReporters mocks base method
+func (m *MockMySQLService) CountReporters(shortenURL string) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountReporters", shortenURL)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountReporters indicates an expected call of CountReporters
+func (mr *MockMySQLServiceMockRecorder) CountReporters(shortenURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountReporters", reflect.TypeOf((*MockMySQLService)(nil).CountReporters), shortenURL)
+}
+
+// SuspendURL mocks base method
+func (m *MockMySQLService) SuspendURL(shortenURL string, suspendedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuspendURL", shortenURL, suspendedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SuspendURL indicates an expected call of SuspendURL
+func (mr *MockMySQLServiceMockRecorder) SuspendURL(shortenURL, suspendedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuspendURL", reflect.TypeOf((*MockMySQLService)(nil).SuspendURL), shortenURL, suspendedAt)
+}
+
+// GetReports mocks base method
+func (m *MockMySQLService) GetReports(status string, offset, limit uint64) (uint64, []database.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReports", status, offset, limit)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].([]database.Report)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
 
-// GetURLsWithUser indicates an expected call of GetURLsWithUser
-func (mr *MockMySQLServiceMockRecorder) GetURLsWithUser(user, offset, limit interface{}) *gomock.Call {
+// GetReports indicates an expected call of GetReports
+func (mr *MockMySQLServiceMockRecorder) GetReports(status, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReports", reflect.TypeOf((*MockMySQLService)(nil).GetReports), status, offset, limit)
+}
+
+// ResolveReports mocks base method
+func (m *MockMySQLService) ResolveReports(shortenURL, status string, reviewer database.User, reviewedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveReports", shortenURL, status, reviewer, reviewedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResolveReports indicates an expected call of ResolveReports
+func (mr *MockMySQLServiceMockRecorder) ResolveReports(shortenURL, status, reviewer, reviewedAt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLsWithUser", reflect.TypeOf((*MockMySQLService)(nil).GetURLsWithUser), user, offset, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveReports", reflect.TypeOf((*MockMySQLService)(nil).ResolveReports), shortenURL, status, reviewer, reviewedAt)
 }
 
 // DeleteURL mocks base method
@@ -165,6 +1229,35 @@ func (mr *MockMySQLServiceMockRecorder) DeleteURL(shortenURL interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteURL", reflect.TypeOf((*MockMySQLService)(nil).DeleteURL), shortenURL)
 }
 
+// RestoreURL mocks base method
+func (m *MockMySQLService) RestoreURL(shortenURL string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreURL", shortenURL)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreURL indicates an expected call of RestoreURL
+func (mr *MockMySQLServiceMockRecorder) RestoreURL(shortenURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreURL", reflect.TypeOf((*MockMySQLService)(nil).RestoreURL), shortenURL)
+}
+
+// PurgeURLsDeletedBefore mocks base method
+func (m *MockMySQLService) PurgeURLsDeletedBefore(deletedBefore time.Time, limit uint64) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeURLsDeletedBefore", deletedBefore, limit)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeURLsDeletedBefore indicates an expected call of PurgeURLsDeletedBefore
+func (mr *MockMySQLServiceMockRecorder) PurgeURLsDeletedBefore(deletedBefore, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeURLsDeletedBefore", reflect.TypeOf((*MockMySQLService)(nil).PurgeURLsDeletedBefore), deletedBefore, limit)
+}
+
 // DeleteUser mocks base method
 func (m *MockMySQLService) DeleteUser(user database.User) error {
 	m.ctrl.T.Helper()
@@ -178,3 +1271,135 @@ func (mr *MockMySQLServiceMockRecorder) DeleteUser(user interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockMySQLService)(nil).DeleteUser), user)
 }
+
+// GetUsers mocks base method
+func (m *MockMySQLService) GetUsers(query database.UserQuery) (uint64, []database.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", query)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].([]database.User)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUsers indicates an expected call of GetUsers
+func (mr *MockMySQLServiceMockRecorder) GetUsers(query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockMySQLService)(nil).GetUsers), query)
+}
+
+// SetUserRole mocks base method
+func (m *MockMySQLService) SetUserRole(userID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserRole indicates an expected call of SetUserRole
+func (mr *MockMySQLServiceMockRecorder) SetUserRole(userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockMySQLService)(nil).SetUserRole), userID, role)
+}
+
+// SetUserDisabled mocks base method
+func (m *MockMySQLService) SetUserDisabled(userID string, disabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserDisabled", userID, disabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserDisabled indicates an expected call of SetUserDisabled
+func (mr *MockMySQLServiceMockRecorder) SetUserDisabled(userID, disabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserDisabled", reflect.TypeOf((*MockMySQLService)(nil).SetUserDisabled), userID, disabled)
+}
+
+// TransferURLs mocks base method
+func (m *MockMySQLService) TransferURLs(fromWorkspaceID, toWorkspaceID string) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferURLs", fromWorkspaceID, toWorkspaceID)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferURLs indicates an expected call of TransferURLs
+func (mr *MockMySQLServiceMockRecorder) TransferURLs(fromWorkspaceID, toWorkspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferURLs", reflect.TypeOf((*MockMySQLService)(nil).TransferURLs), fromWorkspaceID, toWorkspaceID)
+}
+
+// PurgeURL mocks base method
+func (m *MockMySQLService) PurgeURL(shortenURL string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeURL", shortenURL)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeURL indicates an expected call of PurgeURL
+func (mr *MockMySQLServiceMockRecorder) PurgeURL(shortenURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeURL", reflect.TypeOf((*MockMySQLService)(nil).PurgeURL), shortenURL)
+}
+
+// GetSystemStats mocks base method
+func (m *MockMySQLService) GetSystemStats() (database.SystemStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSystemStats")
+	ret0, _ := ret[0].(database.SystemStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSystemStats indicates an expected call of GetSystemStats
+func (mr *MockMySQLServiceMockRecorder) GetSystemStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSystemStats", reflect.TypeOf((*MockMySQLService)(nil).GetSystemStats))
+}
+
+// CountURLs mocks base method
+func (m *MockMySQLService) CountURLs() (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountURLs")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountURLs indicates an expected call of CountURLs
+func (mr *MockMySQLServiceMockRecorder) CountURLs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountURLs", reflect.TypeOf((*MockMySQLService)(nil).CountURLs))
+}
+
+// CountUsers mocks base method
+func (m *MockMySQLService) CountUsers() (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUsers")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUsers indicates an expected call of CountUsers
+func (mr *MockMySQLServiceMockRecorder) CountUsers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUsers", reflect.TypeOf((*MockMySQLService)(nil).CountUsers))
+}
+
+// Close mocks base method
+func (m *MockMySQLService) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close
+func (mr *MockMySQLServiceMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockMySQLService)(nil).Close))
+}
@@ -1,15 +1,47 @@
 package database
 
+import (
+	"net/http"
+	"time"
+)
+
 var (
 	UserTypeGoogle = "google" // Google Login
 	UserTypeLocal  = "local"  // Local account
 )
 
+var (
+	UserRoleUser  = "user"  // manages own workspaces
+	UserRoleAdmin = "admin" // administers every account
+)
+
 type User struct {
-	UserID   string
-	Email    string
-	Type     string // local: Local Account without Oauth service, google: Google Account
-	Password string
+	UserID                 string
+	Email                  string
+	Type                   string // local: Local Account without Oauth service, google: Google Account
+	Password               string
+	Role                   string // UserRoleUser if empty on creation
+	Disabled               bool   // refused to sign in by an admin
+	DeadLinkAlertsDisabled bool   // opted out of emails about broken destinations
+}
+
+// UserQuery selects users ordered by email. Search matches part of the email, empty for everyone.
+type UserQuery struct {
+	Search string
+	Offset uint64
+	Limit  uint64
+}
+
+// SystemStats sums up usage across every account.
+type SystemStats struct {
+	Users         uint64
+	DisabledUsers uint64
+	Workspaces    uint64
+	URLs          uint64
+	DeletedURLs   uint64 // in the trash, not purged yet
+	Hits          int64
+	Domains       uint64
+	Pages         uint64
 }
 
 type GoogleUser struct {
@@ -17,9 +49,393 @@ type GoogleUser struct {
 	GoogleUUID string
 }
 
+var (
+	WorkspaceRoleOwner  = "owner"  // manages members and links
+	WorkspaceRoleEditor = "editor" // manages links
+	WorkspaceRoleViewer = "viewer" // reads links
+)
+
+var workspaceRoleRanks = map[string]int{
+	WorkspaceRoleViewer: 1,
+	WorkspaceRoleEditor: 2,
+	WorkspaceRoleOwner:  3,
+}
+
+// IsWorkspaceRole tells whether role is one of the known workspace roles.
+func IsWorkspaceRole(role string) bool {
+	_, ok := workspaceRoleRanks[role]
+	return ok
+}
+
+// WorkspaceRoleAllows tells whether role grants everything required role does.
+func WorkspaceRoleAllows(role string, required string) bool {
+	return IsWorkspaceRole(role) && workspaceRoleRanks[role] >= workspaceRoleRanks[required]
+}
+
+// Workspace owns urls. Every user has a personal workspace sharing the user id.
+type Workspace struct {
+	ID        string
+	Name      string
+	Personal  bool
+	CreatedAt time.Time
+}
+
+// Membership is a workspace seen from one of its members.
+type Membership struct {
+	Workspace Workspace
+	Role      string
+}
+
+type WorkspaceMember struct {
+	WorkspaceID string
+	User        User
+	Role        string
+}
+
+// WorkspaceInvitation grants Role in workspace to whoever signs in with Email and holds the token hashed to TokenHash.
+type WorkspaceInvitation struct {
+	TokenHash   string
+	WorkspaceID string
+	Email       string
+	Role        string
+	InvitedBy   string
+	ExpiresAt   time.Time
+}
+
+// Domain is a custom hostname short urls of a workspace can be served on, once verified.
+type Domain struct {
+	Hostname          string
+	WorkspaceID       string
+	VerificationToken string    // expected in a DNS TXT record of the hostname
+	VerifiedAt        time.Time // zero until verified
+	CreatedAt         time.Time
+}
+
+var (
+	PageThemeLight  = "light"
+	PageThemeDark   = "dark"
+	PageThemeOcean  = "ocean"
+	PageThemeSunset = "sunset"
+)
+
+// PageThemes are the presets a page can be styled with.
+var PageThemes = []string{PageThemeLight, PageThemeDark, PageThemeOcean, PageThemeSunset}
+
+// Page is a public landing page at /p/Slug listing links of its workspace.
+type Page struct {
+	Slug        string
+	WorkspaceID string
+	CreatedBy   string // id of user
+	Title       string
+	Description string
+	AvatarURL   string
+	Theme       string     // one of PageThemes
+	Links       []PageLink // in display order
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type PageLink struct {
+	ShortenURL string
+	Label      string // shown instead of the title of url if set
+}
+
+// DefaultRedirectStatus is what urls redirect with unless another status was chosen.
+const DefaultRedirectStatus = http.StatusTemporaryRedirect
+
+// IsRedirectStatus tells whether status can be chosen for redirects of a url.
+func IsRedirectStatus(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// RedirectStatusOf returns the status url redirects with.
+func RedirectStatusOf(url URL) int {
+	if IsRedirectStatus(url.RedirectStatus) {
+		return url.RedirectStatus
+	}
+	return DefaultRedirectStatus
+}
+
 type URL struct {
-	OriginURL  string
-	Owner      string
+	OriginURL         string
+	Owner             string // id of owning workspace
+	CreatedBy         string // id of user
+	Domain            string // hostname of custom domain, empty for the default one
+	ShortenURL        string
+	RedirectStatus    int       // 0 for DefaultRedirectStatus
+	AnalyticsDisabled bool      // hits are not counted, so browsers may cache redirects
+	AppURI            string    // deep link mobile visitors are sent to before falling back to the web destination
+	StartsAt          time.Time // zero if active since creation
+	EndsAt            time.Time // zero if never ending
+	Schedule          *Schedule // nil if active around the clock, only loaded with a single url
+	Count             int64
+	Title             string
+	Folder            string
+	Note              string // private to the owner
+	Tags              []string
+	Rules             []RedirectRule // evaluated in order, only loaded with a single url
+	Variants          []URLVariant   // rotated by weight instead of OriginURL if any, only loaded with a single url
+	Metadata          URLMetadata
+	BrokenSince       time.Time // zero unless the latest checks found the destination broken
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	DeletedAt         time.Time // zero unless the url is in the trash
+	SuspendedAt       time.Time // zero unless disabled pending review of reports
+}
+
+var (
+	DeviceIOS     = "ios"
+	DeviceAndroid = "android"
+	DeviceDesktop = "desktop"
+)
+
+// Schedule restricts a url to recurring windows of the week. Visitors outside of them are sent to AlternateURL,
+// or not served if it is empty.
+type Schedule struct {
+	Timezone     string // IANA name of the zone windows are in
+	Windows      []ScheduleWindow
+	AlternateURL string
+}
+
+// ScheduleWindow opens a url on Days from Start until End, both in minutes since midnight.
+type ScheduleWindow struct {
+	Days  []time.Weekday
+	Start int
+	End   int // exclusive, after Start and at most 24 hours
+}
+
+// RedirectRule sends visitors matching all of its non-empty conditions to Destination rather than the origin url.
+type RedirectRule struct {
+	Device      string // one of Device*
+	Country     string // ISO 3166-1 alpha-2 code, upper case
+	Language    string // primary language subtag, lower case
+	Destination string
+}
+
+// URLVariant is one of the destinations a url splits its traffic across, receiving Weight out of the total weight
+// of its variants. ID changes whenever variants of the url are replaced.
+type URLVariant struct {
+	ID          uint64
+	Destination string
+	Weight      int
+	Hits        int64
+}
+
+// URLMetadata is fetched from the destination of url. FetchedAt is zero if it was never fetched.
+type URLMetadata struct {
+	Title       string
+	Description string
+	Image       string
+	Favicon     string
+	Status      int // http status of the destination, 0 if unreachable
+	FetchedAt   time.Time
+}
+
+// URLDetails carries user editable attributes of url. Nil fields are left unchanged, Tags replaces all tags if set.
+type URLDetails struct {
+	Title             *string
+	Folder            *string
+	Note              *string
+	Tags              *[]string
+	RedirectStatus    *int
+	AnalyticsDisabled *bool
+	AppURI            *string
+	StartsAt          *time.Time // zero removes the start
+	EndsAt            *time.Time // zero removes the end
+}
+
+// URLFilter narrows listed urls down to a tag and/or folder if set
+type URLFilter struct {
+	Tag    string
+	Folder string
+}
+
+// TagStats aggregates urls of user sharing a tag
+type TagStats struct {
+	Tag   string
+	Links uint64
+	Hits  int64
+}
+
+var (
+	URLSortCreated = "created" // newest created first
+	URLSortUpdated = "updated" // latest updated first
+	URLSortHits    = "hits"    // most visited first
+)
+
+// URLListQuery selects a page of user's urls. After is the cursor returned with the previous page.
+type URLListQuery struct {
+	URLFilter
+	Sort   string
+	Search string // matched against origin url, shorten url, title and tags
+	After  *URLCursor
+	Limit  uint64
+}
+
+// URLCursor points at the last url of a page, in the ordering of URLListQuery.Sort
+type URLCursor struct {
 	ShortenURL string
-	Count      int64
+	Time       time.Time
+	Hits       int64
+}
+
+// URLCheck is a single probe of the destination of url. Status is 0 if no response was received.
+type URLCheck struct {
+	ShortenURL string
+	Status     int
+	Error      string
+	Broken     bool
+	CheckedAt  time.Time
+}
+
+var (
+	AuditActionSignUp         = "user.sign_up"
+	AuditActionSignIn         = "user.sign_in"
+	AuditActionURLCreate      = "url.create"
+	AuditActionURLUpdate      = "url.update"
+	AuditActionURLRulesUpdate = "url.rules.update"
+	AuditActionURLVariants    = "url.variants.update"
+	AuditActionURLSchedule    = "url.schedule.update"
+	AuditActionURLDelete      = "url.delete"
+	AuditActionURLRestore     = "url.restore"
+	AuditActionURLsImport     = "url.import"
+	AuditActionDataExport     = "user.data_export"
+	AuditActionUserAdminister = "admin.user.update"
+	AuditActionURLsTransfer   = "admin.urls.transfer"
+	AuditActionURLPurge       = "admin.url.purge"
+	AuditActionReportsResolve = "admin.reports.resolve"
+)
+
+var (
+	AuditTargetUser      = "user"
+	AuditTargetURL       = "url"
+	AuditTargetWorkspace = "workspace"
+)
+
+// AuditEntry records a change made by a user. Before and After hold json snapshots of the target, empty if none.
+type AuditEntry struct {
+	ID         uint64
+	ActorID    string // id of user
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     string
+	After      string
+	IP         string
+	UserAgent  string
+	CreatedAt  time.Time
+}
+
+// AuditQuery selects audit entries, newest first. Empty fields match everything.
+type AuditQuery struct {
+	ActorID  string
+	Action   string
+	TargetID string
+	BeforeID uint64 // only entries older than this one, 0 for the newest
+	Limit    uint64
+}
+
+var (
+	ReportReasonSpam     = "spam"
+	ReportReasonPhishing = "phishing"
+	ReportReasonMalware  = "malware"
+	ReportReasonIllegal  = "illegal"
+	ReportReasonOther    = "other"
+)
+
+var ReportReasons = []string{ReportReasonSpam, ReportReasonPhishing, ReportReasonMalware, ReportReasonIllegal, ReportReasonOther}
+
+var (
+	ReportStatusOpen      = "open"      // waiting for review
+	ReportStatusDismissed = "dismissed" // url was found legitimate, reports of removed urls are purged along with them
+)
+
+// Report is a complaint of a visitor about a url.
+type Report struct {
+	ID            uint64
+	ShortenURL    string
+	Reason        string
+	Details       string
+	ReporterEmail string // empty if visitor left none
+	ReporterIP    string
+	Status        string
+	ReviewedBy    string // id of admin, empty while open
+	CreatedAt     time.Time
+	ReviewedAt    time.Time
+}
+
+var (
+	DataExportStatusPending = "pending" // waiting to be packaged
+	DataExportStatusReady   = "ready"   // archive can be downloaded until it expires
+	DataExportStatusFailed  = "failed"
+)
+
+// DataExport is a request of a user for everything held about them, packaged as a zip archive.
+// Size is the length of the archive, which is only loaded on download.
+type DataExport struct {
+	ID          string
+	UserID      string
+	Status      string
+	Size        uint64
+	CreatedAt   time.Time
+	CompletedAt time.Time // zero while pending
+	ExpiresAt   time.Time // zero while pending
+}
+
+var (
+	WebhookEventURLCreated = "url.created"
+	WebhookEventURLUpdated = "url.updated"
+	WebhookEventURLDeleted = "url.deleted"
+	WebhookEventURLClicks  = "url.clicks" // hits of a url reached one of the click thresholds of the webhook
+)
+
+// WebhookEvents lists events webhooks can subscribe to.
+var WebhookEvents = []string{WebhookEventURLCreated, WebhookEventURLUpdated, WebhookEventURLDeleted, WebhookEventURLClicks}
+
+func IsWebhookEvent(event string) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook receives events about links of a workspace, signed with Secret.
+type Webhook struct {
+	ID                  string
+	WorkspaceID         string
+	URL                 string
+	Secret              string
+	Events              []string
+	ClickThresholds     []uint64 // hits notified with WebhookEventURLClicks, once per url and threshold
+	CreatedBy           string
+	ConsecutiveFailures int       // failed attempts since the last successful delivery
+	DisabledAt          time.Time // zero while enabled, set by owners or after repeated failures
+	CreatedAt           time.Time
+}
+
+var (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusDelivered = "delivered"
+	WebhookDeliveryStatusFailed    = "failed" // retries were exhausted
+)
+
+// WebhookDelivery is an event queued for a webhook, along with the outcome of its last attempt.
+type WebhookDelivery struct {
+	ID             uint64
+	WebhookID      string
+	Event          string
+	Payload        string
+	Status         string
+	Attempts       int
+	ResponseStatus int    // of the last attempt, 0 if no response was received
+	Error          string // of the last attempt
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	DeliveredAt    time.Time
 }
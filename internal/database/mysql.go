@@ -4,7 +4,8 @@ import (
 	"fmt"
 	"github.com/jinzhu/gorm"
 	_ "github.com/jinzhu/gorm/dialects/mysql"
-	"log"
+	"github.com/sirupsen/logrus"
+	"strings"
 	"time"
 )
 
@@ -13,13 +14,95 @@ type MySQLService interface {
 	CreateGoogleUser(user User, gUser GoogleUser) error
 	GetUserWithEmail(email string) (*User, error)
 	GetUserWithID(userId string) (*User, error)
-	GetURLIfExistsWithUser(user User, oriURL string) (*URL, error)
-	CreateURL(oriURL string, shortenURL string, user User) error
+	GetURLIfExistsInWorkspace(workspaceID string, domain string, oriURL string) (*URL, error)
+	CreateURL(oriURL string, shortenURL string, domain string, workspaceID string, creator User) error
 	GetURLWithShortenURL(shortenURL string) (*URL, error)
+	GetURLsWithShortenURLs(shortenURLs []string) ([]URL, error)
+	GetDeletedURL(shortenURL string) (*URL, error)
+	GetDeletedURLsInWorkspace(workspaceID string) ([]URL, error)
 	UpdateURL(url *URL) error
-	GetURLsWithUser(user User, offset uint64, limit uint64) (uint64, []URL, error)
+	IncreaseURLCount(shortenURL string) error
+	UpdateURLDetails(shortenURL string, details URLDetails) error
+	GetURLsInWorkspace(workspaceID string, filter URLFilter, offset uint64, limit uint64) (uint64, []URL, error)
+	ListURLsInWorkspace(workspaceID string, query URLListQuery) (uint64, []URL, *URLCursor, error)
+	GetTagStatsInWorkspace(workspaceID string) ([]TagStats, error)
+	UpdateURLMetadata(shortenURL string, metadata URLMetadata) error
+	GetURLsForMetadataRefresh(fetchedBefore time.Time, limit uint64) ([]URL, error)
+	GetURLsAfter(shortenURL string, limit uint64) ([]URL, error)
+	GetURLsInWorkspaceAfter(workspaceID string, shortenURL string, limit uint64) ([]URL, error)
+	ImportURL(url URL, creator User) error
+	GetGoogleUserWithUserID(userID string) (*GoogleUser, error)
+	GetURLsOfUserAfter(userID string, shortenURL string, limit uint64) ([]URL, error)
+	CreateDataExport(export DataExport) error
+	GetDataExport(id string) (*DataExport, error)
+	GetDataExportsWithUser(userID string) ([]DataExport, error)
+	GetPendingDataExports(limit uint64) ([]DataExport, error)
+	GetDataExportArchive(id string) ([]byte, error)
+	CompleteDataExport(id string, archive []byte, completedAt time.Time, expiresAt time.Time) error
+	FailDataExport(id string, failedAt time.Time) error
+	DeleteDataExportsBefore(createdBefore time.Time) error
+	CreateURLCheck(check URLCheck) error
+	GetURLChecks(shortenURL string, limit uint64) ([]URLCheck, error)
+	DeleteURLChecksBefore(checkedBefore time.Time) error
+	GetBrokenURLsToAlert(brokenBefore time.Time) ([]URL, error)
+	MarkURLsAlerted(shortenURLs []string, alertedAt time.Time) error
+	SetDeadLinkAlertsDisabled(user User, disabled bool) error
+	CreateWorkspace(workspace Workspace, owner User) error
+	GetMembershipsWithUser(user User) ([]Membership, error)
+	GetWorkspaceRole(workspaceID string, userID string) (string, error)
+	GetWorkspaceMembers(workspaceID string) ([]WorkspaceMember, error)
+	SetWorkspaceMemberRole(workspaceID string, userID string, role string) error
+	DeleteWorkspaceMember(workspaceID string, userID string) error
+	CreateWorkspaceInvitation(invitation WorkspaceInvitation) error
+	AcceptWorkspaceInvitation(tokenHash string, user User) (*WorkspaceInvitation, error)
+	CreateDomain(domain Domain) error
+	GetDomain(hostname string) (*Domain, error)
+	GetWorkspaceDomain(workspaceID string, hostname string) (*Domain, error)
+	GetDomainsInWorkspace(workspaceID string) ([]Domain, error)
+	MarkDomainVerified(workspaceID string, hostname string, verifiedAt time.Time) error
+	CountURLsOnDomain(hostname string) (uint64, error)
+	DeleteDomain(workspaceID string, hostname string) error
+	SetURLRules(shortenURL string, rules []RedirectRule) error
+	SetURLVariants(shortenURL string, variants []URLVariant) error
+	SetURLSchedule(shortenURL string, schedule *Schedule) error
+	IncreaseURLVariantCount(id uint64) error
+	CreatePage(page Page) error
+	GetPage(slug string) (*Page, error)
+	GetPagesInWorkspace(workspaceID string) ([]Page, error)
+	UpdatePage(page Page) error
+	DeletePage(slug string) error
+	CreateAuditEntry(entry AuditEntry) error
+	GetAuditEntries(query AuditQuery) ([]AuditEntry, error)
+	CreateWebhook(webhook Webhook) error
+	GetWebhook(id string) (*Webhook, error)
+	GetWebhooksInWorkspace(workspaceID string) ([]Webhook, error)
+	GetWebhooksWithEvent(event string) ([]Webhook, error)
+	UpdateWebhook(webhook Webhook) error
+	DeleteWebhook(id string) error
+	EnqueueWebhookDeliveries(workspaceID string, event string, payload string) (uint64, error)
+	GetURLsReachingClicks(webhook Webhook, clicks uint64, limit uint64) ([]URL, error)
+	EnqueueWebhookClicksDelivery(delivery WebhookDelivery, shortenURL string, clicks uint64) error
+	GetDueWebhookDeliveries(now time.Time, limit uint64) ([]WebhookDelivery, error)
+	RecordWebhookDelivery(delivery WebhookDelivery, disableAfter int, at time.Time) (bool, error)
+	GetWebhookDeliveries(webhookID string, offset uint64, limit uint64) (uint64, []WebhookDelivery, error)
+	DeleteWebhookDeliveriesBefore(createdBefore time.Time) error
+	CreateReport(report Report) error
+	CountReporters(shortenURL string) (uint64, error)
+	SuspendURL(shortenURL string, suspendedAt time.Time) error
+	GetReports(status string, offset uint64, limit uint64) (uint64, []Report, error)
+	ResolveReports(shortenURL string, status string, reviewer User, reviewedAt time.Time) error
 	DeleteURL(shortenURL string) error
+	RestoreURL(shortenURL string) error
+	PurgeURLsDeletedBefore(deletedBefore time.Time, limit uint64) (uint64, error)
 	DeleteUser(user User) error
+	GetUsers(query UserQuery) (uint64, []User, error)
+	SetUserRole(userID string, role string) error
+	SetUserDisabled(userID string, disabled bool) error
+	TransferURLs(fromWorkspaceID string, toWorkspaceID string) (uint64, error)
+	PurgeURL(shortenURL string) error
+	GetSystemStats() (SystemStats, error)
+	CountURLs() (uint64, error)
+	CountUsers() (uint64, error)
 	Close() error
 }
 
@@ -27,11 +110,14 @@ type MySQLService interface {
  * Gorm* representing implementation of MySQLService.
  */
 type gormUser struct {
-	UserID    string `gorm:"primary_key"`
-	Email     string `gorm:"unique;not null"`
-	Type      string
-	Password  string
-	UpdatedAt time.Time
+	UserID                 string `gorm:"primary_key"`
+	Email                  string `gorm:"unique;not null"`
+	Type                   string
+	Password               string
+	Role                   string
+	Disabled               bool
+	DeadLinkAlertsDisabled bool
+	UpdatedAt              time.Time
 }
 
 type gormGoogleUser struct {
@@ -41,11 +127,107 @@ type gormGoogleUser struct {
 }
 
 type gormURL struct {
-	OriginURL  string
-	Owner      string
+	OriginURL         string
+	Owner             string
+	CreatedBy         string
+	Domain            string
+	ShortenURL        string `gorm:"primary_key"`
+	RedirectStatus    int
+	AnalyticsDisabled bool
+	AppURI            string `gorm:"type:text"`
+	StartsAt          *time.Time
+	EndsAt            *time.Time
+	Count             int64
+	Title             string
+	Folder            string
+	Note              string `gorm:"type:text"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	DeletedAt         *time.Time // soft deleted urls are hidden by gorm until restored or purged
+	SuspendedAt       *time.Time
+
+	MetaTitle         string `gorm:"type:text"`
+	MetaDescription   string `gorm:"type:text"`
+	MetaImage         string `gorm:"type:text"`
+	MetaFavicon       string `gorm:"type:text"`
+	LastStatus        int
+	MetadataFetchedAt *time.Time
+
+	BrokenSince *time.Time
+	AlertedAt   *time.Time // set once owner was told about the current breakage
+
+	ScheduleTimezone     string
+	ScheduleAlternateURL string `gorm:"type:text"`
+}
+
+func (u gormURL) toURL() URL {
+	url := URL{
+		OriginURL:         u.OriginURL,
+		Owner:             u.Owner,
+		CreatedBy:         u.CreatedBy,
+		Domain:            u.Domain,
+		ShortenURL:        u.ShortenURL,
+		RedirectStatus:    u.RedirectStatus,
+		AnalyticsDisabled: u.AnalyticsDisabled,
+		AppURI:            u.AppURI,
+		Count:             u.Count,
+		Title:             u.Title,
+		Folder:            u.Folder,
+		Note:              u.Note,
+		Tags:              []string{},
+		Metadata: URLMetadata{
+			Title:       u.MetaTitle,
+			Description: u.MetaDescription,
+			Image:       u.MetaImage,
+			Favicon:     u.MetaFavicon,
+			Status:      u.LastStatus,
+		},
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+	if u.MetadataFetchedAt != nil {
+		url.Metadata.FetchedAt = *u.MetadataFetchedAt
+	}
+	if u.BrokenSince != nil {
+		url.BrokenSince = *u.BrokenSince
+	}
+	if u.StartsAt != nil {
+		url.StartsAt = *u.StartsAt
+	}
+	if u.EndsAt != nil {
+		url.EndsAt = *u.EndsAt
+	}
+	if u.DeletedAt != nil {
+		url.DeletedAt = *u.DeletedAt
+	}
+	if u.SuspendedAt != nil {
+		url.SuspendedAt = *u.SuspendedAt
+	}
+	return url
+}
+
+type gormURLTag struct {
 	ShortenURL string `gorm:"primary_key"`
-	Count      int64
-	UpdatedAt  time.Time
+	Tag        string `gorm:"primary_key"`
+}
+
+type gormURLCheck struct {
+	ID         uint `gorm:"primary_key"`
+	ShortenURL string
+	Status     int
+	Error      string `gorm:"type:text"`
+	Broken     bool
+	CheckedAt  time.Time
+}
+
+func (c gormURLCheck) toURLCheck() URLCheck {
+	return URLCheck{
+		ShortenURL: c.ShortenURL,
+		Status:     c.Status,
+		Error:      c.Error,
+		Broken:     c.Broken,
+		CheckedAt:  c.CheckedAt,
+	}
 }
 
 type gormService struct {
@@ -56,7 +238,7 @@ func newGormService(user string, password string, host string, port string, dbNa
 	connectStr := fmt.Sprintf("%v:%v@(%v:%v)/%v?%v", user, password, host, port, dbName, dbParams)
 	db, err := gorm.Open("mysql", connectStr)
 	if err != nil {
-		log.Printf("Unable to init database connection %v\n", err)
+		logrus.WithError(err).Error("Unable to init database connection")
 		return nil, err
 	}
 	db.DB().SetConnMaxLifetime(59 * time.Second)
@@ -81,6 +263,42 @@ func (g *gormService) Init() {
 		g.db.CreateTable(&gormURL{})
 		g.db.Model(&gormURL{}).AddIndex("idx_shorten_url", "shorten_url")
 	}
+
+	// note: migrations for columns added after tables were first created
+	g.db.AutoMigrate(&gormUser{})
+	g.db.Model(&gormUser{}).Where("role = ''").UpdateColumn("role", UserRoleUser)
+	g.db.AutoMigrate(&gormURL{})
+	g.db.Model(&gormURL{}).Where("created_at IS NULL").UpdateColumn("created_at", gorm.Expr("updated_at"))
+	g.db.Model(&gormURL{}).Where("created_by = ''").UpdateColumn("created_by", gorm.Expr("owner"))
+	g.db.Model(&gormURL{}).AddIndex("idx_owner_created_at", "owner", "created_at")
+	g.db.Model(&gormURL{}).AddIndex("idx_owner_folder", "owner", "folder")
+	g.db.Model(&gormURL{}).AddIndex("idx_metadata_fetched_at", "metadata_fetched_at")
+	g.db.Model(&gormURL{}).AddIndex("idx_broken_since", "broken_since")
+	g.db.Model(&gormURL{}).AddIndex("idx_domain", "domain")
+	g.db.Model(&gormURL{}).AddIndex("idx_deleted_at", "deleted_at")
+
+	if hasURLTagTable := g.db.HasTable(&gormURLTag{}); !hasURLTagTable {
+		g.db.CreateTable(&gormURLTag{})
+		g.db.Model(&gormURLTag{}).AddIndex("idx_tag", "tag")
+	}
+
+	if hasURLCheckTable := g.db.HasTable(&gormURLCheck{}); !hasURLCheckTable {
+		g.db.CreateTable(&gormURLCheck{})
+		g.db.Model(&gormURLCheck{}).AddIndex("idx_shorten_url_checked_at", "shorten_url", "checked_at")
+		g.db.Model(&gormURLCheck{}).AddIndex("idx_checked_at", "checked_at")
+	}
+
+	g.initWorkspaces()
+	g.initDomains()
+	g.initRules()
+	g.initVariants()
+	g.initPages()
+	g.initSchedules()
+	g.initTrash()
+	g.initAudit()
+	g.initReports()
+	g.initDataExports()
+	g.initWebhooks()
 }
 
 func (g *gormService) Close() error {
@@ -88,19 +306,22 @@ func (g *gormService) Close() error {
 }
 
 func (g *gormService) CreateUser(user User) error {
-	u := gormUser{
-		UserID:    user.UserID,
-		Email:     user.Email,
-		Type:      user.Type,
-		Password:  user.Password,
-		UpdatedAt: time.Now(),
-	}
-	if err := g.db.Create(&u).Error; err != nil {
-		log.Printf("Unable to create user in table")
-		return err
-	}
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		u := gormUser{
+			UserID:    user.UserID,
+			Email:     user.Email,
+			Type:      user.Type,
+			Password:  user.Password,
+			Role:      roleOrDefault(user.Role),
+			UpdatedAt: time.Now(),
+		}
+		if err := tx.Create(&u).Error; err != nil {
+			logrus.WithError(err).Debug("Unable to create user in table")
+			return err
+		}
 
-	return nil
+		return createPersonalWorkspace(tx, user)
+	})
 }
 
 func (g *gormService) CreateGoogleUser(user User, gUser GoogleUser) error {
@@ -111,7 +332,7 @@ func (g *gormService) CreateGoogleUser(user User, gUser GoogleUser) error {
 			UpdatedAt:  time.Now(),
 		}
 		if err := tx.Create(&g).Error; err != nil {
-			log.Printf("Unable to create google user in table")
+			logrus.WithError(err).Debug("Unable to create google user in table")
 			return err
 		}
 
@@ -120,13 +341,14 @@ func (g *gormService) CreateGoogleUser(user User, gUser GoogleUser) error {
 			Email:    user.Email,
 			Type:     user.Type,
 			Password: user.Password,
+			Role:     roleOrDefault(user.Role),
 		}
 		if err := tx.Create(&u).Error; err != nil {
-			log.Printf("Unable to create user in table")
+			logrus.WithError(err).Debug("Unable to create user in table")
 			return err
 		}
 
-		return nil
+		return createPersonalWorkspace(tx, user)
 	})
 }
 
@@ -151,17 +373,32 @@ func (g *gormService) queryUser(userInfo *gormUser, execute *gorm.DB) (*User, er
 		return nil, err
 	}
 
-	return &User{
-		UserID:   userInfo.UserID,
-		Email:    userInfo.Email,
-		Type:     userInfo.Type,
-		Password: userInfo.Password,
-	}, nil
+	user := userInfo.toUser()
+	return &user, nil
 }
 
-func (g *gormService) GetURLIfExistsWithUser(user User, oriURL string) (*URL, error) {
+func (u gormUser) toUser() User {
+	return User{
+		UserID:                 u.UserID,
+		Email:                  u.Email,
+		Type:                   u.Type,
+		Password:               u.Password,
+		Role:                   roleOrDefault(u.Role),
+		Disabled:               u.Disabled,
+		DeadLinkAlertsDisabled: u.DeadLinkAlertsDisabled,
+	}
+}
+
+func roleOrDefault(role string) string {
+	if role == "" {
+		return UserRoleUser
+	}
+	return role
+}
+
+func (g *gormService) GetURLIfExistsInWorkspace(workspaceID string, domain string, oriURL string) (*URL, error) {
 	var gormURL gormURL
-	execute := g.db.Where("origin_url = ? AND owner = ?", oriURL, user.UserID).First(&gormURL)
+	execute := g.db.Where("origin_url = ? AND owner = ? AND domain = ?", oriURL, workspaceID, domain).First(&gormURL)
 
 	if execute.RecordNotFound() {
 		return nil, NewRecordNotFoundError()
@@ -171,26 +408,36 @@ func (g *gormService) GetURLIfExistsWithUser(user User, oriURL string) (*URL, er
 		return nil, err
 	}
 
-	return &URL{
-		OriginURL:  gormURL.OriginURL,
-		Owner:      gormURL.Owner,
-		ShortenURL: gormURL.ShortenURL,
-	}, nil
+	url := gormURL.toURL()
+	return &url, nil
 }
 
-func (g *gormService) CreateURL(oriURL string, shortenURL string, user User) error {
-	u := gormURL{
-		OriginURL:  oriURL,
-		Owner:      user.UserID,
-		ShortenURL: shortenURL,
-		UpdatedAt:  time.Now(),
-	}
-	if err := g.db.Create(&u).Error; err != nil {
-		log.Printf("Unable to create url in table")
-		return err
-	}
+// CreateURL stores a new url, ShortenURLTakenError returns if shortenURL was ever issued, even if deleted since.
+func (g *gormService) CreateURL(oriURL string, shortenURL string, domain string, workspaceID string, creator User) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		taken, err := isShortenURLTaken(tx, shortenURL)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return NewShortenURLTakenError()
+		}
 
-	return nil
+		u := gormURL{
+			OriginURL:  oriURL,
+			Owner:      workspaceID,
+			CreatedBy:  creator.UserID,
+			Domain:     domain,
+			ShortenURL: shortenURL,
+			UpdatedAt:  time.Now(),
+		}
+		if err := tx.Create(&u).Error; err != nil {
+			logrus.WithError(err).Debug("Unable to create url in table")
+			return err
+		}
+
+		return nil
+	})
 }
 
 func (g *gormService) GetURLWithShortenURL(shortenURL string) (*URL, error) {
@@ -205,12 +452,26 @@ func (g *gormService) GetURLWithShortenURL(shortenURL string) (*URL, error) {
 		return nil, err
 	}
 
-	return &URL{
-		OriginURL:  gormURL.OriginURL,
-		Owner:      gormURL.Owner,
-		ShortenURL: gormURL.ShortenURL,
-		Count:      gormURL.Count,
-	}, nil
+	urls := []URL{gormURL.toURL()}
+	if err := g.attachTags(urls); err != nil {
+		return nil, err
+	}
+	rules, err := g.getURLRules(shortenURL)
+	if err != nil {
+		return nil, err
+	}
+	urls[0].Rules = rules
+	variants, err := g.getURLVariants(shortenURL)
+	if err != nil {
+		return nil, err
+	}
+	urls[0].Variants = variants
+	schedule, err := g.getURLSchedule(gormURL)
+	if err != nil {
+		return nil, err
+	}
+	urls[0].Schedule = schedule
+	return &urls[0], nil
 }
 
 func (g *gormService) UpdateURL(url *URL) error {
@@ -239,36 +500,371 @@ func (g *gormService) UpdateURL(url *URL) error {
 	return nil
 }
 
-func (g *gormService) GetURLsWithUser(user User, offset uint64, limit uint64) (uint64, []URL, error) {
-	var gormUrl2 []gormURL
+func (g *gormService) IncreaseURLCount(shortenURL string) error {
+	execute := g.db.Model(&gormURL{}).Where("shorten_url = ?", shortenURL).UpdateColumn("count", gorm.Expr("count + ?", 1))
+	if err := execute.Error; err != nil {
+		return err
+	}
+
+	if execute.RowsAffected == 0 {
+		return NewRecordNotFoundError()
+	}
+
+	return nil
+}
+
+func (g *gormService) UpdateURLDetails(shortenURL string, details URLDetails) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		var count int
+		if err := tx.Model(&gormURL{}).Where("shorten_url = ?", shortenURL).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return NewRecordNotFoundError()
+		}
+
+		// note: only the columns of details are written, hits, suspensions and checks recorded meanwhile are kept
+		columns := map[string]interface{}{"updated_at": time.Now()}
+		if details.Title != nil {
+			columns["title"] = *details.Title
+		}
+		if details.Folder != nil {
+			columns["folder"] = *details.Folder
+		}
+		if details.Note != nil {
+			columns["note"] = *details.Note
+		}
+		if details.RedirectStatus != nil {
+			columns["redirect_status"] = *details.RedirectStatus
+		}
+		if details.AnalyticsDisabled != nil {
+			columns["analytics_disabled"] = *details.AnalyticsDisabled
+		}
+		if details.AppURI != nil {
+			columns["app_uri"] = *details.AppURI
+		}
+		if details.StartsAt != nil {
+			columns["starts_at"] = nilIfZero(*details.StartsAt)
+		}
+		if details.EndsAt != nil {
+			columns["ends_at"] = nilIfZero(*details.EndsAt)
+		}
+		if err := tx.Model(&gormURL{}).Where("shorten_url = ?", shortenURL).UpdateColumns(columns).Error; err != nil {
+			return err
+		}
+
+		if details.Tags == nil {
+			return nil
+		}
+		if err := tx.Where("shorten_url = ?", shortenURL).Delete(&gormURLTag{}).Error; err != nil {
+			return err
+		}
+		for _, tag := range *details.Tags {
+			if err := tx.Create(&gormURLTag{ShortenURL: shortenURL, Tag: tag}).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// attachTags fills in tags of given urls with a single query
+func (g *gormService) attachTags(urls []URL) error {
+	if len(urls) == 0 {
+		return nil
+	}
+
+	index := make(map[string]*URL, len(urls))
+	shortenURLs := make([]string, len(urls))
+	for i := range urls {
+		index[urls[i].ShortenURL] = &urls[i]
+		shortenURLs[i] = urls[i].ShortenURL
+	}
+
+	var tags []gormURLTag
+	if err := g.db.Where("shorten_url IN (?)", shortenURLs).Order("tag").Find(&tags).Error; err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if url, ok := index[tag.ShortenURL]; ok {
+			url.Tags = append(url.Tags, tag.Tag)
+		}
+	}
+
+	return nil
+}
+
+// filterURLs scopes query to urls of workspace matching filter
+func (g *gormService) filterURLs(workspaceID string, filter URLFilter) *gorm.DB {
+	filtered := g.db.Model(&gormURL{}).Where("owner = ?", workspaceID)
+	if filter.Folder != "" {
+		filtered = filtered.Where("folder = ?", filter.Folder)
+	}
+	if filter.Tag != "" {
+		filtered = filtered.Where("shorten_url IN ?", g.db.Model(&gormURLTag{}).Select("shorten_url").Where("tag = ?", filter.Tag).SubQuery())
+	}
+	return filtered
+}
+
+func (g *gormService) GetURLsInWorkspace(workspaceID string, filter URLFilter, offset uint64, limit uint64) (uint64, []URL, error) {
 	var count int
-	countExecute := g.db.Where("owner = ?", user.UserID).Find(&gormUrl2).Count(&count)
+	countExecute := g.filterURLs(workspaceID, filter).Count(&count)
 	if err := countExecute.Error; err != nil {
 		return 0, nil, err
 	}
 
 	var gormUrls []gormURL
-	queryExecute := g.db.Order("updated_at desc").Offset(offset).Where("owner = ?", user.UserID).Limit(limit).Find(&gormUrls)
+	queryExecute := g.filterURLs(workspaceID, filter).Order("updated_at desc").Offset(offset).Limit(limit).Find(&gormUrls)
 	if err := queryExecute.Error; err != nil {
 		return 0, nil, err
 	}
 
 	urls := make([]URL, len(gormUrls))
 	for i, url := range gormUrls {
-		urls[i] = URL{
-			OriginURL:  url.OriginURL,
-			Owner:      url.Owner,
-			ShortenURL: url.ShortenURL,
-			Count:      url.Count,
-		}
+		urls[i] = url.toURL()
+	}
+	if err := g.attachTags(urls); err != nil {
+		return 0, nil, err
 	}
 
 	return uint64(count), urls, nil
 }
 
-func (g *gormService) DeleteURL(shortenURL string) error {
-	var gormURL gormURL
-	execute := g.db.Unscoped().Where("shorten_url = ?", shortenURL).Delete(&gormURL)
+// urlSortColumns maps URLListQuery.Sort to the column ordered by
+var urlSortColumns = map[string]string{
+	URLSortCreated: "created_at",
+	URLSortUpdated: "updated_at",
+	URLSortHits:    "count",
+}
+
+func (g *gormService) ListURLsInWorkspace(workspaceID string, query URLListQuery) (uint64, []URL, *URLCursor, error) {
+	column, ok := urlSortColumns[query.Sort]
+	if !ok {
+		column = urlSortColumns[URLSortCreated]
+	}
+
+	filtered := g.filterURLs(workspaceID, query.URLFilter)
+	if query.Search != "" {
+		pattern := "%" + escapeLike(query.Search) + "%"
+		taggedURLs := g.db.Model(&gormURLTag{}).Select("shorten_url").Where("tag LIKE ?", pattern).SubQuery()
+		filtered = filtered.Where("origin_url LIKE ? OR shorten_url LIKE ? OR title LIKE ? OR shorten_url IN ?", pattern, pattern, pattern, taggedURLs)
+	}
+
+	var count uint64
+	if err := filtered.Count(&count).Error; err != nil {
+		return 0, nil, nil, err
+	}
+
+	page := filtered
+	if after := query.After; after != nil {
+		var value interface{}
+		if column == "count" {
+			value = after.Hits
+		} else {
+			value = after.Time
+		}
+		page = page.Where(fmt.Sprintf("%[1]v < ? OR (%[1]v = ? AND shorten_url < ?)", column), value, value, after.ShortenURL)
+	}
+
+	// fetch one more than requested to know whether another page follows
+	var gormUrls []gormURL
+	execute := page.Order(column + " desc").Order("shorten_url desc").Limit(query.Limit + 1).Find(&gormUrls)
+	if err := execute.Error; err != nil {
+		return 0, nil, nil, err
+	}
+
+	var next *URLCursor
+	if uint64(len(gormUrls)) > query.Limit {
+		gormUrls = gormUrls[:query.Limit]
+		last := gormUrls[len(gormUrls)-1]
+		next = &URLCursor{ShortenURL: last.ShortenURL, Hits: last.Count, Time: last.CreatedAt}
+		if column == "updated_at" {
+			next.Time = last.UpdatedAt
+		}
+	}
+
+	urls := make([]URL, len(gormUrls))
+	for i, url := range gormUrls {
+		urls[i] = url.toURL()
+	}
+	if err := g.attachTags(urls); err != nil {
+		return 0, nil, nil, err
+	}
+
+	return count, urls, next, nil
+}
+
+func (g *gormService) GetTagStatsInWorkspace(workspaceID string) ([]TagStats, error) {
+	rows, err := g.db.Table("gorm_url_tags t").
+		Select("t.tag, COUNT(*), COALESCE(SUM(u.count), 0)").
+		Joins("JOIN gorm_urls u ON u.shorten_url = t.shorten_url").
+		Where("u.owner = ? AND u.deleted_at IS NULL", workspaceID).
+		Group("t.tag").
+		Order("t.tag").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []TagStats{}
+	for rows.Next() {
+		var stat TagStats
+		if err := rows.Scan(&stat.Tag, &stat.Links, &stat.Hits); err != nil {
+			return nil, err
+		}
+		stats = append(stats, stat)
+	}
+
+	return stats, rows.Err()
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
+
+func (g *gormService) UpdateURLMetadata(shortenURL string, metadata URLMetadata) error {
+	fetchedAt := metadata.FetchedAt
+	// note: UpdateColumns keeps updated_at untouched as this is not an edit by user
+	execute := g.db.Model(&gormURL{}).Where("shorten_url = ?", shortenURL).UpdateColumns(map[string]interface{}{
+		"meta_title":          metadata.Title,
+		"meta_description":    metadata.Description,
+		"meta_image":          metadata.Image,
+		"meta_favicon":        metadata.Favicon,
+		"last_status":         metadata.Status,
+		"metadata_fetched_at": &fetchedAt,
+	})
+	if err := execute.Error; err != nil {
+		return err
+	}
+
+	if execute.RowsAffected == 0 {
+		return NewRecordNotFoundError()
+	}
+
+	return nil
+}
+
+func (g *gormService) GetURLsForMetadataRefresh(fetchedBefore time.Time, limit uint64) ([]URL, error) {
+	var gormUrls []gormURL
+	execute := g.db.Where("metadata_fetched_at IS NULL OR metadata_fetched_at < ?", fetchedBefore).
+		Order("metadata_fetched_at").
+		Limit(limit).
+		Find(&gormUrls)
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	urls := make([]URL, len(gormUrls))
+	for i, url := range gormUrls {
+		urls[i] = url.toURL()
+	}
+
+	return urls, nil
+}
+
+// GetURLsWithShortenURLs returns the urls found among shortenURLs, in no particular order and without tags.
+func (g *gormService) GetURLsWithShortenURLs(shortenURLs []string) ([]URL, error) {
+	if len(shortenURLs) == 0 {
+		return []URL{}, nil
+	}
+
+	var gormUrls []gormURL
+	if err := g.db.Where("shorten_url IN (?)", shortenURLs).Find(&gormUrls).Error; err != nil {
+		return nil, err
+	}
+
+	urls := make([]URL, len(gormUrls))
+	for i, url := range gormUrls {
+		urls[i] = url.toURL()
+	}
+
+	return urls, nil
+}
+
+func (g *gormService) GetURLsAfter(shortenURL string, limit uint64) ([]URL, error) {
+	var gormUrls []gormURL
+	execute := g.db.Where("shorten_url > ?", shortenURL).Order("shorten_url").Limit(limit).Find(&gormUrls)
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	urls := make([]URL, len(gormUrls))
+	for i, url := range gormUrls {
+		urls[i] = url.toURL()
+	}
+
+	return urls, nil
+}
+
+func (g *gormService) CreateURLCheck(check URLCheck) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		c := gormURLCheck{
+			ShortenURL: check.ShortenURL,
+			Status:     check.Status,
+			Error:      check.Error,
+			Broken:     check.Broken,
+			CheckedAt:  check.CheckedAt,
+		}
+		if err := tx.Create(&c).Error; err != nil {
+			return err
+		}
+
+		url := tx.Model(&gormURL{}).Where("shorten_url = ?", check.ShortenURL)
+		if check.Broken {
+			return url.Where("broken_since IS NULL").UpdateColumn("broken_since", check.CheckedAt).Error
+		}
+		return url.Where("broken_since IS NOT NULL").UpdateColumns(map[string]interface{}{
+			"broken_since": nil,
+			"alerted_at":   nil,
+		}).Error
+	})
+}
+
+func (g *gormService) GetURLChecks(shortenURL string, limit uint64) ([]URLCheck, error) {
+	var gormChecks []gormURLCheck
+	execute := g.db.Where("shorten_url = ?", shortenURL).Order("checked_at desc").Limit(limit).Find(&gormChecks)
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	checks := make([]URLCheck, len(gormChecks))
+	for i, check := range gormChecks {
+		checks[i] = check.toURLCheck()
+	}
+
+	return checks, nil
+}
+
+func (g *gormService) DeleteURLChecksBefore(checkedBefore time.Time) error {
+	return g.db.Where("checked_at < ?", checkedBefore).Delete(&gormURLCheck{}).Error
+}
+
+func (g *gormService) GetBrokenURLsToAlert(brokenBefore time.Time) ([]URL, error) {
+	var gormUrls []gormURL
+	execute := g.db.Where("broken_since < ? AND alerted_at IS NULL", brokenBefore).Order("owner").Find(&gormUrls)
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	urls := make([]URL, len(gormUrls))
+	for i, url := range gormUrls {
+		urls[i] = url.toURL()
+	}
+
+	return urls, nil
+}
+
+func (g *gormService) MarkURLsAlerted(shortenURLs []string, alertedAt time.Time) error {
+	if len(shortenURLs) == 0 {
+		return nil
+	}
+	return g.db.Model(&gormURL{}).Where("shorten_url IN (?)", shortenURLs).UpdateColumn("alerted_at", alertedAt).Error
+}
+
+func (g *gormService) SetDeadLinkAlertsDisabled(user User, disabled bool) error {
+	execute := g.db.Model(&gormUser{}).Where("user_id = ?", user.UserID).UpdateColumn("dead_link_alerts_disabled", disabled)
 	if err := execute.Error; err != nil {
 		return err
 	}
@@ -276,8 +872,19 @@ func (g *gormService) DeleteURL(shortenURL string) error {
 	return nil
 }
 
+// DeleteURL moves url to the trash, keeping everything attached to it until it is restored or purged.
+func (g *gormService) DeleteURL(shortenURL string) error {
+	return g.db.Where("shorten_url = ?", shortenURL).Delete(&gormURL{}).Error
+}
+
+// DeleteUser removes user along with the personal workspace, whose urls are moved to the trash.
+// LastOwnerError is returned while user is the only owner of a shared workspace.
 func (g *gormService) DeleteUser(user User) error {
 	return g.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkNotLastOwner(tx, user); err != nil {
+			return err
+		}
+
 		var gormUser gormUser
 		execute := tx.Unscoped().Where("user_id = ?", user.UserID).Delete(&gormUser)
 		if err := execute.Error; err != nil {
@@ -292,10 +899,36 @@ func (g *gormService) DeleteUser(user User) error {
 			}
 		}
 
-		return nil
+		if err := tx.Where("user_id = ?", user.UserID).Delete(&gormDataExport{}).Error; err != nil {
+			return err
+		}
+
+		if err := deleteWorkspaceWebhooks(tx, user.UserID); err != nil {
+			return err
+		}
+
+		return deletePersonalWorkspace(tx, user)
 	})
 }
 
+func (g *gormService) CountURLs() (uint64, error) {
+	var count uint64
+	if err := g.db.Model(&gormURL{}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (g *gormService) CountUsers() (uint64, error) {
+	var count uint64
+	if err := g.db.Model(&gormUser{}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
 type Config struct {
 	Username string
 	Password string
@@ -349,7 +982,7 @@ func NewMySQLDatabase(c Config) (MySQLService, error) {
 
 	g, err := newGormService(user, pass, host, port, dbName, dbParams)
 	if err != nil {
-		log.Printf("Unable to create an instance of Gorm")
+		logrus.WithError(err).Error("Unable to create an instance of Gorm")
 		return nil, err
 	}
 
@@ -3,10 +3,15 @@ package database_test
 import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"strconv"
+	"time"
 	"url-shortener/internal/config"
 	"url-shortener/internal/database"
 )
 
+// runSuffix keeps shorten urls of each run apart, as deleted ones are never issued again
+var runSuffix = strconv.FormatInt(time.Now().Unix(), 36)
+
 var _ = Describe("GormService (default impl of MySQLService)", func() {
 	var (
 		db          database.MySQLService
@@ -29,11 +34,13 @@ var _ = Describe("GormService (default impl of MySQLService)", func() {
 			Email:    "test1@test.com",
 			Type:     "local",
 			Password: "$2a$14$J9me9P5IdEsLE2BMU9AV1.qxBBx62y/8WK2NWEmawgfVNtX4c0hf.", // password: 1234
+			Role:     database.UserRoleUser,
 		}
 		user2 = database.User{
 			UserID: "test-user-2",
 			Email:  "xxx@gmail.com",
 			Type:   "google",
+			Role:   database.UserRoleUser,
 		}
 		googleUser2 = database.GoogleUser{
 			UserID:     "test-user-2",
@@ -41,11 +48,11 @@ var _ = Describe("GormService (default impl of MySQLService)", func() {
 		}
 		user3Email = "xyz@xyz.com"
 		url1 = "https://google.com"
-		url1S = "2sDftfgG"
+		url1S = "2sDftfgG" + runSuffix
 		url2 = "https://google.com"
-		url2S = "s2D0tf"
+		url2S = "s2D0tf" + runSuffix
 		url3 = "https://facebook.com"
-		url3S = "s4rf"
+		url3S = "s4rf" + runSuffix
 		url4 = "https://twitter.com"
 
 		env := config.ReadEnv()
@@ -83,17 +90,17 @@ var _ = Describe("GormService (default impl of MySQLService)", func() {
 	Describe("Create shorten url", func() {
 		Context("From local account", func() {
 			It("should perform successfully", func() {
-				err := db.CreateURL(url1, url1S, user1)
+				err := db.CreateURL(url1, url1S, "", user1.UserID, user1)
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
 
 		Context("From Google account", func() {
 			It("should perform successfully", func() {
-				err := db.CreateURL(url2, url2S, user2)
+				err := db.CreateURL(url2, url2S, "", user2.UserID, user2)
 				Expect(err).NotTo(HaveOccurred())
 
-				err = db.CreateURL(url3, url3S, user2)
+				err = db.CreateURL(url3, url3S, "", user2.UserID, user2)
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
@@ -136,14 +143,552 @@ var _ = Describe("GormService (default impl of MySQLService)", func() {
 		})
 	})
 
+	Describe("Edit details of shorten url", func() {
+		It("should update given fields and replace tags", func() {
+			title := "Facebook"
+			tags := []string{"social", "work"}
+			err := db.UpdateURLDetails(url3S, database.URLDetails{Title: &title, Tags: &tags})
+			Expect(err).NotTo(HaveOccurred())
+
+			_url3, err := db.GetURLWithShortenURL(url3S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(_url3.Title).To(Equal(title))
+			Expect(_url3.Tags).To(Equal(tags))
+
+			folder := "bookmarks"
+			status := 301
+			analyticsDisabled := true
+			appURI := "myapp://item/42"
+			err = db.UpdateURLDetails(url3S, database.URLDetails{Folder: &folder, RedirectStatus: &status, AnalyticsDisabled: &analyticsDisabled, AppURI: &appURI})
+			Expect(err).NotTo(HaveOccurred())
+
+			_url3, err = db.GetURLWithShortenURL(url3S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(_url3.Title).To(Equal(title))
+			Expect(_url3.Folder).To(Equal(folder))
+			Expect(_url3.Tags).To(Equal(tags))
+			Expect(_url3.RedirectStatus).To(Equal(status))
+			Expect(_url3.AnalyticsDisabled).To(Equal(true))
+			Expect(_url3.AppURI).To(Equal(appURI))
+		})
+
+		It("should leave hits and suspension alone", func() {
+			_url3, err := db.GetURLWithShortenURL(url3S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(db.SuspendURL(url3S, time.Now())).To(Succeed())
+			Expect(db.IncreaseURLCount(url3S)).To(Succeed())
+
+			note := "suspended meanwhile"
+			Expect(db.UpdateURLDetails(url3S, database.URLDetails{Note: &note})).To(Succeed())
+			edited, err := db.GetURLWithShortenURL(url3S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(edited.Note).To(Equal(note))
+			Expect(edited.Count).To(Equal(_url3.Count + 1))
+			Expect(edited.SuspendedAt.IsZero()).To(BeFalse())
+
+			_, ok := db.UpdateURLDetails("no-such-url", database.URLDetails{Note: &note}).(database.RecordNotFoundError)
+			Expect(ok).To(BeTrue())
+			Expect(db.ResolveReports(url3S, database.ReportStatusDismissed, user1, time.Now())).To(Succeed())
+		})
+
+		It("should filter by tag and aggregate hits per tag", func() {
+			total, urls, err := db.GetURLsInWorkspace(user2.UserID, database.URLFilter{Tag: "social"}, 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(uint64(1)))
+			Expect(urls[0].ShortenURL).To(Equal(url3S))
+
+			stats, err := db.GetTagStatsInWorkspace(user2.UserID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats).To(HaveLen(2))
+			Expect(stats[0].Tag).To(Equal("social"))
+			Expect(stats[0].Links).To(Equal(uint64(1)))
+		})
+	})
+
+	Describe("List shorten urls page by page", func() {
+		It("should walk through every page with cursor", func() {
+			total, page1, next, err := db.ListURLsInWorkspace(user2.UserID, database.URLListQuery{
+				Sort:  database.URLSortCreated,
+				Limit: 1,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(uint64(2)))
+			Expect(page1).To(HaveLen(1))
+			Expect(next).NotTo(BeNil())
+
+			_, page2, next, err := db.ListURLsInWorkspace(user2.UserID, database.URLListQuery{
+				Sort:  database.URLSortCreated,
+				After: next,
+				Limit: 1,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page2).To(HaveLen(1))
+			Expect(page2[0].ShortenURL).NotTo(Equal(page1[0].ShortenURL))
+			Expect(next).To(BeNil())
+		})
+
+		It("should only return urls matching search", func() {
+			total, urls, _, err := db.ListURLsInWorkspace(user2.UserID, database.URLListQuery{
+				Sort:   database.URLSortHits,
+				Search: "facebook",
+				Limit:  10,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(uint64(1)))
+			Expect(urls).To(HaveLen(1))
+			Expect(urls[0].ShortenURL).To(Equal(url3S))
+		})
+	})
+
+	Describe("Personal workspace of user", func() {
+		It("should be owned by user and share its id", func() {
+			memberships, err := db.GetMembershipsWithUser(user1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(memberships).To(HaveLen(1))
+			Expect(memberships[0].Workspace.ID).To(Equal(user1.UserID))
+			Expect(memberships[0].Workspace.Personal).To(Equal(true))
+			Expect(memberships[0].Role).To(Equal(database.WorkspaceRoleOwner))
+
+			role, err := db.GetWorkspaceRole(user1.UserID, user2.UserID)
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(database.RecordNotFoundError)
+			Expect(ok).To(Equal(true))
+			Expect(role).To(BeEmpty())
+		})
+	})
+
+	Describe("Custom domain of workspace", func() {
+		It("should be registered, verified and removed", func() {
+			hostname := "go.test.com"
+			err := db.CreateDomain(database.Domain{Hostname: hostname, WorkspaceID: user1.UserID, VerificationToken: "abc123"})
+			Expect(err).NotTo(HaveOccurred())
+
+			domain, err := db.GetWorkspaceDomain(user1.UserID, hostname)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(domain.WorkspaceID).To(Equal(user1.UserID))
+			Expect(domain.VerifiedAt.IsZero()).To(Equal(true))
+			_, err = db.GetDomain(hostname)
+			_, ok := err.(database.RecordNotFoundError)
+			Expect(ok).To(Equal(true))
+
+			err = db.MarkDomainVerified(user1.UserID, hostname, time.Now())
+			Expect(err).NotTo(HaveOccurred())
+			domains, err := db.GetDomainsInWorkspace(user1.UserID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(domains).To(HaveLen(1))
+			Expect(domains[0].VerifiedAt.IsZero()).To(Equal(false))
+
+			count, err := db.CountURLsOnDomain(hostname)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(uint64(0)))
+
+			err = db.DeleteDomain(user1.UserID, hostname)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = db.GetDomain(hostname)
+			_, ok = err.(database.RecordNotFoundError)
+			Expect(ok).To(Equal(true))
+		})
+
+		It("should go to the first workspace verifying it, dropping unverified claims of others", func() {
+			hostname := "claimed.test.com"
+			Expect(db.CreateDomain(database.Domain{Hostname: hostname, WorkspaceID: user1.UserID, VerificationToken: "squatter"})).To(Succeed())
+			Expect(db.CreateDomain(database.Domain{Hostname: hostname, WorkspaceID: user2.UserID, VerificationToken: "owner"})).To(Succeed())
+
+			Expect(db.MarkDomainVerified(user2.UserID, hostname, time.Now())).To(Succeed())
+			domain, err := db.GetDomain(hostname)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(domain.WorkspaceID).To(Equal(user2.UserID))
+			_, err = db.GetWorkspaceDomain(user1.UserID, hostname)
+			_, ok := err.(database.RecordNotFoundError)
+			Expect(ok).To(Equal(true))
+
+			Expect(db.CreateDomain(database.Domain{Hostname: hostname, WorkspaceID: user1.UserID, VerificationToken: "late"})).To(Succeed())
+			_, ok = db.MarkDomainVerified(user1.UserID, hostname, time.Now()).(database.DomainTakenError)
+			Expect(ok).To(Equal(true))
+
+			Expect(db.DeleteDomain(user1.UserID, hostname)).To(Succeed())
+			Expect(db.DeleteDomain(user2.UserID, hostname)).To(Succeed())
+		})
+	})
+
+	Describe("Redirect rules of shorten url", func() {
+		It("should be replaced and loaded in order", func() {
+			rules := []database.RedirectRule{
+				{Device: database.DeviceIOS, Destination: "https://apps.apple.com/app/id1"},
+				{Country: "DE", Language: "de", Destination: "https://example.com/de"},
+			}
+			err := db.SetURLRules(url3S, rules)
+			Expect(err).NotTo(HaveOccurred())
+
+			_url3, err := db.GetURLWithShortenURL(url3S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(_url3.Rules).To(Equal(rules))
+
+			err = db.SetURLRules(url3S, nil)
+			Expect(err).NotTo(HaveOccurred())
+			_url3, err = db.GetURLWithShortenURL(url3S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(_url3.Rules).To(BeEmpty())
+		})
+	})
+
+	Describe("Variants of shorten url", func() {
+		It("should be replaced and count hits separately", func() {
+			err := db.SetURLVariants(url3S, []database.URLVariant{
+				{Destination: "https://example.com/a", Weight: 70},
+				{Destination: "https://example.com/b", Weight: 30},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_url3, err := db.GetURLWithShortenURL(url3S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(_url3.Variants).To(HaveLen(2))
+			Expect(_url3.Variants[0].Weight).To(Equal(70))
+
+			err = db.IncreaseURLVariantCount(_url3.Variants[1].ID)
+			Expect(err).NotTo(HaveOccurred())
+			_url3, err = db.GetURLWithShortenURL(url3S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(_url3.Variants[0].Hits).To(Equal(int64(0)))
+			Expect(_url3.Variants[1].Hits).To(Equal(int64(1)))
+
+			err = db.SetURLVariants(url3S, nil)
+			Expect(err).NotTo(HaveOccurred())
+			_url3, err = db.GetURLWithShortenURL(url3S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(_url3.Variants).To(BeEmpty())
+		})
+	})
+
+	Describe("Page of workspace", func() {
+		It("should be created, updated and deleted with its links in order", func() {
+			page := database.Page{
+				Slug:        "my-links",
+				WorkspaceID: user2.UserID,
+				CreatedBy:   user2.UserID,
+				Title:       "My links",
+				Theme:       database.PageThemeDark,
+				Links: []database.PageLink{
+					{ShortenURL: url3S, Label: "Facebook"},
+				},
+			}
+			err := db.CreatePage(page)
+			Expect(err).NotTo(HaveOccurred())
+
+			stored, err := db.GetPage(page.Slug)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored.Title).To(Equal(page.Title))
+			Expect(stored.Links).To(Equal(page.Links))
+
+			page.Theme = database.PageThemeOcean
+			page.Links = []database.PageLink{}
+			err = db.UpdatePage(page)
+			Expect(err).NotTo(HaveOccurred())
+			pages, err := db.GetPagesInWorkspace(user2.UserID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pages).To(HaveLen(1))
+			Expect(pages[0].Theme).To(Equal(database.PageThemeOcean))
+
+			urls, err := db.GetURLsWithShortenURLs([]string{url3S, "missing"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(urls).To(HaveLen(1))
+
+			err = db.DeletePage(page.Slug)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = db.GetPage(page.Slug)
+			_, ok := err.(database.RecordNotFoundError)
+			Expect(ok).To(Equal(true))
+		})
+	})
+
+	Describe("Schedule of shorten url", func() {
+		It("should be replaced and removed", func() {
+			schedule := &database.Schedule{
+				Timezone: "Europe/Berlin",
+				Windows: []database.ScheduleWindow{
+					{Days: []time.Weekday{time.Monday, time.Friday}, Start: 9 * 60, End: 17 * 60},
+				},
+				AlternateURL: "https://example.com/closed",
+			}
+			err := db.SetURLSchedule(url3S, schedule)
+			Expect(err).NotTo(HaveOccurred())
+
+			_url3, err := db.GetURLWithShortenURL(url3S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(_url3.Schedule).To(Equal(schedule))
+
+			err = db.SetURLSchedule(url3S, nil)
+			Expect(err).NotTo(HaveOccurred())
+			_url3, err = db.GetURLWithShortenURL(url3S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(_url3.Schedule).To(BeNil())
+		})
+	})
+
+	Describe("Audit log", func() {
+		It("should list entries of an actor newest first page by page", func() {
+			for _, action := range []string{database.AuditActionSignIn, database.AuditActionURLCreate, database.AuditActionURLUpdate} {
+				err := db.CreateAuditEntry(database.AuditEntry{
+					ActorID:    user1.UserID,
+					Action:     action,
+					TargetType: database.AuditTargetURL,
+					TargetID:   url1S,
+					After:      `{"title":"launch"}`,
+					IP:         "203.0.113.7",
+					UserAgent:  "test",
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			entries, err := db.GetAuditEntries(database.AuditQuery{ActorID: user1.UserID, TargetID: url1S, Limit: 2})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(2))
+			Expect(entries[0].Action).To(Equal(database.AuditActionURLUpdate))
+			Expect(entries[0].After).To(Equal(`{"title":"launch"}`))
+			Expect(entries[0].IP).To(Equal("203.0.113.7"))
+			Expect(entries[1].Action).To(Equal(database.AuditActionURLCreate))
+
+			entries, err = db.GetAuditEntries(database.AuditQuery{ActorID: user1.UserID, TargetID: url1S, BeforeID: entries[1].ID, Limit: 2})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Action).To(Equal(database.AuditActionSignIn))
+
+			entries, err = db.GetAuditEntries(database.AuditQuery{ActorID: user2.UserID, TargetID: url1S, Limit: 10})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+	})
+
+	Describe("Administration", func() {
+		It("should search users, change their role and account, and sum up usage", func() {
+			total, users, err := db.GetUsers(database.UserQuery{Search: "TEST1@", Limit: 10})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(uint64(1)))
+			Expect(users[0].UserID).To(Equal(user1.UserID))
+
+			Expect(db.SetUserRole(user1.UserID, database.UserRoleAdmin)).To(Succeed())
+			Expect(db.SetUserDisabled(user2.UserID, true)).To(Succeed())
+			_user1, err := db.GetUserWithID(user1.UserID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(_user1.Role).To(Equal(database.UserRoleAdmin))
+			_user2, err := db.GetUserWithID(user2.UserID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(_user2.Disabled).To(BeTrue())
+
+			stats, err := db.GetSystemStats()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats.Users).To(BeNumerically(">=", 2))
+			Expect(stats.DisabledUsers).To(BeNumerically(">=", 1))
+			Expect(stats.URLs).To(BeNumerically(">=", 3))
+
+			Expect(db.SetUserRole(user1.UserID, database.UserRoleUser)).To(Succeed())
+			Expect(db.SetUserDisabled(user2.UserID, false)).To(Succeed())
+			_, ok := db.SetUserDisabled("no-such-user", true).(database.RecordNotFoundError)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should transfer urls on the default domain to another workspace", func() {
+			transferred, err := db.TransferURLs(user2.UserID, "test-transfer-workspace")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(transferred).To(Equal(uint64(2)))
+			url, err := db.GetURLWithShortenURL(url3S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(url.Owner).To(Equal("test-transfer-workspace"))
+
+			transferred, err = db.TransferURLs("test-transfer-workspace", user2.UserID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(transferred).To(Equal(uint64(2)))
+		})
+	})
+
+	Describe("Import and export of urls", func() {
+		It("should keep shorten url, details and hits of imported url and walk workspace in order", func() {
+			imported := database.URL{
+				OriginURL:  "https://example.com/imported",
+				Owner:      user1.UserID,
+				ShortenURL: "imported" + runSuffix,
+				Title:      "Imported",
+				Tags:       []string{"imported"},
+				Count:      42,
+				CreatedAt:  time.Date(2021, 3, 4, 12, 0, 0, 0, time.UTC),
+			}
+			Expect(db.ImportURL(imported, user1)).To(Succeed())
+			_, ok := db.ImportURL(imported, user1).(database.ShortenURLTakenError)
+			Expect(ok).To(BeTrue())
+
+			url, err := db.GetURLWithShortenURL(imported.ShortenURL)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(url.Title).To(Equal("Imported"))
+			Expect(url.Tags).To(Equal([]string{"imported"}))
+			Expect(url.Count).To(Equal(int64(42)))
+			Expect(url.CreatedAt.Year()).To(Equal(2021))
+
+			walked := map[string]bool{}
+			after := ""
+			for {
+				urls, err := db.GetURLsInWorkspaceAfter(user1.UserID, after, 1)
+				Expect(err).NotTo(HaveOccurred())
+				if len(urls) == 0 {
+					break
+				}
+				Expect(urls[0].Owner).To(Equal(user1.UserID))
+				Expect(walked).NotTo(HaveKey(urls[0].ShortenURL))
+				walked[urls[0].ShortenURL] = true
+				after = urls[0].ShortenURL
+			}
+			Expect(walked).To(HaveKey(imported.ShortenURL))
+		})
+	})
+
+	Describe("Reports of shorten url", func() {
+		It("should count distinct reporters, suspend url and lift it once dismissed", func() {
+			for _, ip := range []string{"192.0.2.1", "192.0.2.1", "192.0.2.2"} {
+				Expect(db.CreateReport(database.Report{
+					ShortenURL: url3S,
+					Reason:     database.ReportReasonSpam,
+					ReporterIP: ip,
+				})).To(Succeed())
+			}
+			reporters, err := db.CountReporters(url3S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reporters).To(Equal(uint64(2)))
+
+			total, reports, err := db.GetReports(database.ReportStatusOpen, 0, 100)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(BeNumerically(">=", 3))
+			Expect(reports[0].Status).To(Equal(database.ReportStatusOpen))
+
+			Expect(db.SuspendURL(url3S, time.Now())).To(Succeed())
+			url, err := db.GetURLWithShortenURL(url3S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(url.SuspendedAt.IsZero()).To(BeFalse())
+
+			Expect(db.ResolveReports(url3S, database.ReportStatusDismissed, user1, time.Now())).To(Succeed())
+			url, err = db.GetURLWithShortenURL(url3S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(url.SuspendedAt.IsZero()).To(BeTrue())
+			reporters, err = db.CountReporters(url3S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reporters).To(BeZero())
+		})
+	})
+
+	Describe("Data exports of user", func() {
+		It("should store the archive of a pending export once and serve it when ready", func() {
+			id := "export-" + runSuffix
+			Expect(db.CreateDataExport(database.DataExport{ID: id, UserID: user1.UserID})).To(Succeed())
+
+			pending, err := db.GetPendingDataExports(100)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(ContainElement(WithTransform(func(e database.DataExport) string { return e.ID }, Equal(id))))
+			_, err = db.GetDataExportArchive(id)
+			_, ok := err.(database.RecordNotFoundError)
+			Expect(ok).To(BeTrue())
+
+			now := time.Now()
+			Expect(db.CompleteDataExport(id, []byte("archive"), now, now.Add(time.Hour))).To(Succeed())
+			_, ok = db.CompleteDataExport(id, []byte("again"), now, now.Add(time.Hour)).(database.RecordNotFoundError)
+			Expect(ok).To(BeTrue())
+
+			exports, err := db.GetDataExportsWithUser(user1.UserID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exports[0].ID).To(Equal(id))
+			Expect(exports[0].Status).To(Equal(database.DataExportStatusReady))
+			Expect(exports[0].Size).To(Equal(uint64(7)))
+			archive, err := db.GetDataExportArchive(id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(archive)).To(Equal("archive"))
+
+			urls, err := db.GetURLsOfUserAfter(user1.UserID, "", 100)
+			Expect(err).NotTo(HaveOccurred())
+			for _, url := range urls {
+				Expect(url.Owner == user1.UserID || url.CreatedBy == user1.UserID).To(BeTrue())
+			}
+
+			Expect(db.DeleteDataExportsBefore(time.Now().Add(time.Minute))).To(Succeed())
+			_, err = db.GetDataExport(id)
+			_, ok = err.(database.RecordNotFoundError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Describe("Webhooks of workspace", func() {
+		It("should queue deliveries for subscribed webhooks and disable them after repeated failures", func() {
+			hook := database.Webhook{
+				ID:              "webhook-" + runSuffix,
+				WorkspaceID:     user1.UserID,
+				URL:             "https://example.com/hook",
+				Secret:          "secret",
+				Events:          []string{database.WebhookEventURLDeleted, database.WebhookEventURLClicks},
+				ClickThresholds: []uint64{1, 1000000},
+				CreatedBy:       user1.UserID,
+			}
+			Expect(db.CreateWebhook(hook)).To(Succeed())
+			stored, err := db.GetWebhook(hook.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored.Events).To(Equal(hook.Events))
+			Expect(stored.ClickThresholds).To(Equal(hook.ClickThresholds))
+
+			queued, err := db.EnqueueWebhookDeliveries(user1.UserID, database.WebhookEventURLCreated, "{}")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(queued).To(BeZero())
+			queued, err = db.EnqueueWebhookDeliveries(user1.UserID, database.WebhookEventURLDeleted, "{}")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(queued).To(Equal(uint64(1)))
+
+			// note: urls hit before the webhook was created are not notified
+			urls, err := db.GetURLsReachingClicks(*stored, 1, 100)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(urls).To(BeEmpty())
+
+			due, err := db.GetDueWebhookDeliveries(time.Now().Add(time.Minute), 100)
+			Expect(err).NotTo(HaveOccurred())
+			var delivery database.WebhookDelivery
+			for _, d := range due {
+				if d.WebhookID == hook.ID {
+					delivery = d
+				}
+			}
+			Expect(delivery.Event).To(Equal(database.WebhookEventURLDeleted))
+
+			delivery.Attempts = 1
+			delivery.Error = "receiver answered 500"
+			delivery.NextAttemptAt = time.Now().Add(time.Hour)
+			disabled, err := db.RecordWebhookDelivery(delivery, 2, time.Now())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(disabled).To(BeFalse())
+			disabled, err = db.RecordWebhookDelivery(delivery, 2, time.Now())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(disabled).To(BeTrue())
+
+			stored, err = db.GetWebhook(hook.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored.DisabledAt.IsZero()).To(BeFalse())
+			queued, err = db.EnqueueWebhookDeliveries(user1.UserID, database.WebhookEventURLDeleted, "{}")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(queued).To(BeZero())
+
+			total, deliveries, err := db.GetWebhookDeliveries(hook.ID, 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(uint64(1)))
+			Expect(deliveries[0].Attempts).To(Equal(1))
+			Expect(deliveries[0].Error).To(Equal("receiver answered 500"))
+
+			Expect(db.DeleteWebhook(hook.ID)).To(Succeed())
+			_, err = db.GetWebhook(hook.ID)
+			_, ok := err.(database.RecordNotFoundError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
 	Describe("Get record if exists", func() {
 		It("should not exist", func() {
-			_, err := db.GetURLIfExistsWithUser(user1, url4)
+			_, err := db.GetURLIfExistsInWorkspace(user1.UserID, "", url4)
 			Expect(err).To(HaveOccurred())
 			_, ok := err.(database.RecordNotFoundError)
 			Expect(ok).To(Equal(true))
 
-			_, err = db.GetURLIfExistsWithUser(user2, url4)
+			_, err = db.GetURLIfExistsInWorkspace(user2.UserID, "", url4)
 			Expect(err).To(HaveOccurred())
 			_, ok = err.(database.RecordNotFoundError)
 			Expect(ok).To(Equal(true))
@@ -204,6 +749,94 @@ var _ = Describe("GormService (default impl of MySQLService)", func() {
 			_, ok = err.(database.RecordNotFoundError)
 			Expect(ok).To(Equal(true))
 		})
+
+		It("should keep deleted url restorable until purged and never issue its shorten url again", func() {
+			urls, err := db.GetDeletedURLsInWorkspace(user1.UserID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(urls).To(HaveLen(1))
+			Expect(urls[0].ShortenURL).To(Equal(url1S))
+			Expect(urls[0].DeletedAt.IsZero()).To(BeFalse())
+
+			err = db.CreateURL(url4, url1S, "", user1.UserID, user1)
+			_, ok := err.(database.ShortenURLTakenError)
+			Expect(ok).To(Equal(true))
+
+			Expect(db.RestoreURL(url1S)).To(Succeed())
+			url, err := db.GetURLWithShortenURL(url1S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(url.OriginURL).To(Equal(url1))
+			Expect(url.DeletedAt.IsZero()).To(BeTrue())
+			_, err = db.GetDeletedURL(url1S)
+			_, ok = err.(database.RecordNotFoundError)
+			Expect(ok).To(Equal(true))
+
+			Expect(db.CreateReport(database.Report{
+				ShortenURL: url1S,
+				Reason:     database.ReportReasonSpam,
+				ReporterIP: "192.0.2.1",
+			})).To(Succeed())
+			hook := database.Webhook{
+				ID:              "purge-" + runSuffix,
+				WorkspaceID:     user1.UserID,
+				URL:             "https://example.com/hook",
+				Events:          []string{database.WebhookEventURLClicks},
+				ClickThresholds: []uint64{1000000},
+				CreatedBy:       user1.UserID,
+			}
+			Expect(db.CreateWebhook(hook)).To(Succeed())
+			Expect(db.EnqueueWebhookClicksDelivery(database.WebhookDelivery{
+				WebhookID:     hook.ID,
+				Event:         database.WebhookEventURLClicks,
+				Payload:       "{}",
+				NextAttemptAt: time.Now(),
+			}, url1S, 1000000)).To(Succeed())
+			Expect(db.DeleteURL(url1S)).To(Succeed())
+			purged, err := db.PurgeURLsDeletedBefore(time.Now().Add(time.Minute), 100)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(purged).To(BeNumerically(">=", 3))
+			reporters, err := db.CountReporters(url1S)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reporters).To(BeZero())
+			rows, err := database.CountRowsOfURL(db, url1S)
+			Expect(err).NotTo(HaveOccurred())
+			for table, count := range rows {
+				Expect(count).To(BeZero(), table)
+			}
+			Expect(db.DeleteWebhook(hook.ID)).To(Succeed())
+			_, err = db.GetDeletedURL(url1S)
+			_, ok = err.(database.RecordNotFoundError)
+			Expect(ok).To(Equal(true))
+
+			err = db.CreateURL(url4, url1S, "", user1.UserID, user1)
+			_, ok = err.(database.ShortenURLTakenError)
+			Expect(ok).To(Equal(true))
+		})
+	})
+
+	Describe("Delete user", func() {
+		It("should refuse while user is the last owner of a shared workspace and trash urls of personal workspace", func() {
+			user3 := database.User{UserID: "test-user-3-" + runSuffix, Email: "test3-" + runSuffix + "@test.com", Type: "local"}
+			Expect(db.CreateUser(user3)).To(Succeed())
+			team := database.Workspace{ID: "test-team-" + runSuffix, Name: "team"}
+			Expect(db.CreateWorkspace(team, user3)).To(Succeed())
+			code := "u3" + runSuffix
+			Expect(db.CreateURL("https://example.com/user3", code, "", user3.UserID, user3)).To(Succeed())
+
+			_, ok := db.DeleteUser(user3).(database.LastOwnerError)
+			Expect(ok).To(Equal(true))
+			_, err := db.GetUserWithID(user3.UserID)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(db.DeleteWorkspaceMember(team.ID, user3.UserID)).To(Succeed())
+			Expect(db.DeleteUser(user3)).To(Succeed())
+			_, err = db.GetURLWithShortenURL(code)
+			_, ok = err.(database.RecordNotFoundError)
+			Expect(ok).To(Equal(true))
+			deleted, err := db.GetDeletedURL(code)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted.Owner).To(Equal(user3.UserID))
+			Expect(db.PurgeURL(code)).To(Succeed())
+		})
 	})
 
 	AfterSuite(func() {
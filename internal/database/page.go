@@ -0,0 +1,160 @@
+package database
+
+import (
+	"github.com/jinzhu/gorm"
+	"time"
+)
+
+type gormPage struct {
+	Slug        string `gorm:"primary_key"`
+	WorkspaceID string
+	CreatedBy   string
+	Title       string
+	Description string `gorm:"type:text"`
+	AvatarURL   string `gorm:"type:text"`
+	Theme       string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type gormPageLink struct {
+	ID         uint `gorm:"primary_key"`
+	Slug       string
+	Position   int
+	ShortenURL string `gorm:"index:idx_shorten_url"`
+	Label      string
+}
+
+func (p gormPage) toPage() Page {
+	return Page{
+		Slug:        p.Slug,
+		WorkspaceID: p.WorkspaceID,
+		CreatedBy:   p.CreatedBy,
+		Title:       p.Title,
+		Description: p.Description,
+		AvatarURL:   p.AvatarURL,
+		Theme:       p.Theme,
+		Links:       []PageLink{},
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+func (g *gormService) initPages() {
+	if hasPageTable := g.db.HasTable(&gormPage{}); !hasPageTable {
+		g.db.CreateTable(&gormPage{})
+		g.db.Model(&gormPage{}).AddIndex("idx_workspace_id", "workspace_id")
+	}
+	if hasPageLinkTable := g.db.HasTable(&gormPageLink{}); !hasPageLinkTable {
+		g.db.CreateTable(&gormPageLink{})
+		g.db.Model(&gormPageLink{}).AddIndex("idx_slug_position", "slug", "position")
+	}
+}
+
+func createPageLinks(tx *gorm.DB, slug string, links []PageLink) error {
+	for i, link := range links {
+		l := gormPageLink{
+			Slug:       slug,
+			Position:   i,
+			ShortenURL: link.ShortenURL,
+			Label:      link.Label,
+		}
+		if err := tx.Create(&l).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *gormService) CreatePage(page Page) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		p := gormPage{
+			Slug:        page.Slug,
+			WorkspaceID: page.WorkspaceID,
+			CreatedBy:   page.CreatedBy,
+			Title:       page.Title,
+			Description: page.Description,
+			AvatarURL:   page.AvatarURL,
+			Theme:       page.Theme,
+		}
+		if err := tx.Create(&p).Error; err != nil {
+			return err
+		}
+		return createPageLinks(tx, page.Slug, page.Links)
+	})
+}
+
+// GetPage returns page of slug with its links.
+func (g *gormService) GetPage(slug string) (*Page, error) {
+	var p gormPage
+	execute := g.db.Where("slug = ?", slug).First(&p)
+
+	if execute.RecordNotFound() {
+		return nil, NewRecordNotFoundError()
+	}
+
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	var ls []gormPageLink
+	if err := g.db.Where("slug = ?", slug).Order("position").Find(&ls).Error; err != nil {
+		return nil, err
+	}
+
+	page := p.toPage()
+	for _, l := range ls {
+		page.Links = append(page.Links, PageLink{ShortenURL: l.ShortenURL, Label: l.Label})
+	}
+	return &page, nil
+}
+
+// GetPagesInWorkspace returns pages of workspace without their links.
+func (g *gormService) GetPagesInWorkspace(workspaceID string) ([]Page, error) {
+	var ps []gormPage
+	if err := g.db.Where("workspace_id = ?", workspaceID).Order("slug").Find(&ps).Error; err != nil {
+		return nil, err
+	}
+
+	pages := make([]Page, len(ps))
+	for i, p := range ps {
+		pages[i] = p.toPage()
+	}
+	return pages, nil
+}
+
+// UpdatePage replaces title, description, avatar, theme and links of page.
+func (g *gormService) UpdatePage(page Page) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		var p gormPage
+		execute := tx.Where("slug = ?", page.Slug).First(&p)
+		if execute.RecordNotFound() {
+			return NewRecordNotFoundError()
+		}
+		if err := execute.Error; err != nil {
+			return err
+		}
+
+		p.Title = page.Title
+		p.Description = page.Description
+		p.AvatarURL = page.AvatarURL
+		p.Theme = page.Theme
+		if err := tx.Save(&p).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("slug = ?", page.Slug).Delete(&gormPageLink{}).Error; err != nil {
+			return err
+		}
+		return createPageLinks(tx, page.Slug, page.Links)
+	})
+}
+
+func (g *gormService) DeletePage(slug string) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("slug = ?", slug).Delete(&gormPageLink{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("slug = ?", slug).Delete(&gormPage{}).Error
+	})
+}
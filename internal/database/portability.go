@@ -0,0 +1,65 @@
+package database
+
+import (
+	"github.com/jinzhu/gorm"
+	"time"
+)
+
+// GetURLsInWorkspaceAfter returns urls of workspace ordered by shortenURL, starting after given one, with their tags.
+// Exports walk through every url of a workspace with it a page at a time rather than loading all of them.
+func (g *gormService) GetURLsInWorkspaceAfter(workspaceID string, shortenURL string, limit uint64) ([]URL, error) {
+	var gormUrls []gormURL
+	execute := g.db.Where("owner = ? AND shorten_url > ?", workspaceID, shortenURL).Order("shorten_url").Limit(limit).Find(&gormUrls)
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	urls := make([]URL, len(gormUrls))
+	for i, url := range gormUrls {
+		urls[i] = url.toURL()
+	}
+	if err := g.attachTags(urls); err != nil {
+		return nil, err
+	}
+
+	return urls, nil
+}
+
+// ImportURL recreates url exported elsewhere in its workspace, keeping its shorten url, details, tags, hits and
+// creation time. Shorten urls ever issued are refused with ShortenURLTakenError, as for CreateURL.
+func (g *gormService) ImportURL(url URL, creator User) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		taken, err := isShortenURLTaken(tx, url.ShortenURL)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return NewShortenURLTakenError()
+		}
+
+		u := gormURL{
+			OriginURL:  url.OriginURL,
+			Owner:      url.Owner,
+			CreatedBy:  creator.UserID,
+			Domain:     url.Domain,
+			ShortenURL: url.ShortenURL,
+			Count:      url.Count,
+			Title:      url.Title,
+			Folder:     url.Folder,
+			Note:       url.Note,
+			CreatedAt:  url.CreatedAt,
+			UpdatedAt:  time.Now(),
+		}
+		if err := tx.Create(&u).Error; err != nil {
+			return err
+		}
+
+		for _, tag := range url.Tags {
+			if err := tx.Create(&gormURLTag{ShortenURL: url.ShortenURL, Tag: tag}).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,189 @@
+package database
+
+import (
+	"time"
+)
+
+type gormDataExport struct {
+	ID          string `gorm:"primary_key"`
+	UserID      string
+	Status      string
+	Archive     []byte `gorm:"type:longblob"`
+	Size        uint64
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time
+}
+
+// dataExportColumns are loaded when listing exports, leaving archives out.
+var dataExportColumns = "id, user_id, status, size, created_at, completed_at, expires_at"
+
+func (e gormDataExport) toDataExport() DataExport {
+	export := DataExport{
+		ID:        e.ID,
+		UserID:    e.UserID,
+		Status:    e.Status,
+		Size:      e.Size,
+		CreatedAt: e.CreatedAt,
+	}
+	if e.CompletedAt != nil {
+		export.CompletedAt = *e.CompletedAt
+	}
+	if e.ExpiresAt != nil {
+		export.ExpiresAt = *e.ExpiresAt
+	}
+	return export
+}
+
+func (g *gormService) initDataExports() {
+	if hasDataExportTable := g.db.HasTable(&gormDataExport{}); !hasDataExportTable {
+		g.db.CreateTable(&gormDataExport{})
+		g.db.Model(&gormDataExport{}).AddIndex("idx_user_id", "user_id")
+		g.db.Model(&gormDataExport{}).AddIndex("idx_status", "status")
+	}
+}
+
+// GetGoogleUserWithUserID returns the Google account linked to user.
+func (g *gormService) GetGoogleUserWithUserID(userID string) (*GoogleUser, error) {
+	var gormGoogleUser gormGoogleUser
+	execute := g.db.Where("user_id = ?", userID).First(&gormGoogleUser)
+	if execute.RecordNotFound() {
+		return nil, NewRecordNotFoundError()
+	}
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	return &GoogleUser{
+		UserID:     gormGoogleUser.UserID,
+		GoogleUUID: gormGoogleUser.GoogleUUID,
+	}, nil
+}
+
+// GetURLsOfUserAfter returns urls of the personal workspace of user or created by user, including the ones in the
+// trash, ordered by shortenURL and starting after given one. Their tags and variants are filled in.
+func (g *gormService) GetURLsOfUserAfter(userID string, shortenURL string, limit uint64) ([]URL, error) {
+	var gormUrls []gormURL
+	execute := g.db.Unscoped().
+		Where("(owner = ? OR created_by = ?) AND shorten_url > ?", userID, userID, shortenURL).
+		Order("shorten_url").
+		Limit(limit).
+		Find(&gormUrls)
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	urls := make([]URL, len(gormUrls))
+	for i, url := range gormUrls {
+		urls[i] = url.toURL()
+	}
+	if err := g.attachTags(urls); err != nil {
+		return nil, err
+	}
+	for i := range urls {
+		variants, err := g.getURLVariants(urls[i].ShortenURL)
+		if err != nil {
+			return nil, err
+		}
+		urls[i].Variants = variants
+	}
+
+	return urls, nil
+}
+
+func (g *gormService) CreateDataExport(export DataExport) error {
+	e := gormDataExport{
+		ID:     export.ID,
+		UserID: export.UserID,
+		Status: DataExportStatusPending,
+	}
+	return g.db.Create(&e).Error
+}
+
+func (g *gormService) GetDataExport(id string) (*DataExport, error) {
+	var e gormDataExport
+	execute := g.db.Select(dataExportColumns).Where("id = ?", id).First(&e)
+	if execute.RecordNotFound() {
+		return nil, NewRecordNotFoundError()
+	}
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	export := e.toDataExport()
+	return &export, nil
+}
+
+// GetDataExportsWithUser lists exports requested by user, the newest first.
+func (g *gormService) GetDataExportsWithUser(userID string) ([]DataExport, error) {
+	var es []gormDataExport
+	execute := g.db.Select(dataExportColumns).Where("user_id = ?", userID).Order("created_at desc").Find(&es)
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	exports := make([]DataExport, len(es))
+	for i, e := range es {
+		exports[i] = e.toDataExport()
+	}
+	return exports, nil
+}
+
+// GetPendingDataExports returns exports waiting to be packaged, the oldest first.
+func (g *gormService) GetPendingDataExports(limit uint64) ([]DataExport, error) {
+	var es []gormDataExport
+	execute := g.db.Select(dataExportColumns).Where("status = ?", DataExportStatusPending).Order("created_at").Limit(limit).Find(&es)
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	exports := make([]DataExport, len(es))
+	for i, e := range es {
+		exports[i] = e.toDataExport()
+	}
+	return exports, nil
+}
+
+func (g *gormService) GetDataExportArchive(id string) ([]byte, error) {
+	var e gormDataExport
+	execute := g.db.Select("archive").Where("id = ? AND status = ?", id, DataExportStatusReady).First(&e)
+	if execute.RecordNotFound() {
+		return nil, NewRecordNotFoundError()
+	}
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	return e.Archive, nil
+}
+
+// CompleteDataExport stores the archive of a pending export, to be downloaded until expiresAt.
+func (g *gormService) CompleteDataExport(id string, archive []byte, completedAt time.Time, expiresAt time.Time) error {
+	execute := g.db.Model(&gormDataExport{}).Where("id = ? AND status = ?", id, DataExportStatusPending).UpdateColumns(map[string]interface{}{
+		"status":       DataExportStatusReady,
+		"archive":      archive,
+		"size":         len(archive),
+		"completed_at": completedAt,
+		"expires_at":   expiresAt,
+	})
+	if err := execute.Error; err != nil {
+		return err
+	}
+	if execute.RowsAffected == 0 {
+		return NewRecordNotFoundError()
+	}
+	return nil
+}
+
+func (g *gormService) FailDataExport(id string, failedAt time.Time) error {
+	execute := g.db.Model(&gormDataExport{}).Where("id = ? AND status = ?", id, DataExportStatusPending).UpdateColumns(map[string]interface{}{
+		"status":       DataExportStatusFailed,
+		"completed_at": failedAt,
+	})
+	return execute.Error
+}
+
+// DeleteDataExportsBefore removes exports requested before given time along with their archives.
+func (g *gormService) DeleteDataExportsBefore(createdBefore time.Time) error {
+	return g.db.Where("created_at < ?", createdBefore).Delete(&gormDataExport{}).Error
+}
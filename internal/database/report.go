@@ -0,0 +1,118 @@
+package database
+
+import (
+	"github.com/jinzhu/gorm"
+	"time"
+)
+
+type gormReport struct {
+	ID            uint64 `gorm:"primary_key"`
+	ShortenURL    string
+	Reason        string
+	Details       string `gorm:"type:text"`
+	ReporterEmail string
+	ReporterIP    string
+	Status        string
+	ReviewedBy    string
+	CreatedAt     time.Time
+	ReviewedAt    *time.Time
+}
+
+func (r gormReport) toReport() Report {
+	report := Report{
+		ID:            r.ID,
+		ShortenURL:    r.ShortenURL,
+		Reason:        r.Reason,
+		Details:       r.Details,
+		ReporterEmail: r.ReporterEmail,
+		ReporterIP:    r.ReporterIP,
+		Status:        r.Status,
+		ReviewedBy:    r.ReviewedBy,
+		CreatedAt:     r.CreatedAt,
+	}
+	if r.ReviewedAt != nil {
+		report.ReviewedAt = *r.ReviewedAt
+	}
+	return report
+}
+
+func (g *gormService) initReports() {
+	if hasReportTable := g.db.HasTable(&gormReport{}); !hasReportTable {
+		g.db.CreateTable(&gormReport{})
+		g.db.Model(&gormReport{}).AddIndex("idx_shorten_url_status", "shorten_url", "status")
+		g.db.Model(&gormReport{}).AddIndex("idx_status_created_at", "status", "created_at")
+	}
+}
+
+func (g *gormService) CreateReport(report Report) error {
+	r := gormReport{
+		ShortenURL:    report.ShortenURL,
+		Reason:        report.Reason,
+		Details:       report.Details,
+		ReporterEmail: report.ReporterEmail,
+		ReporterIP:    report.ReporterIP,
+		Status:        ReportStatusOpen,
+	}
+	return g.db.Create(&r).Error
+}
+
+// CountReporters returns how many distinct addresses have open reports about url, so that a single visitor
+// reporting over and over does not weigh more than one.
+func (g *gormService) CountReporters(shortenURL string) (uint64, error) {
+	var count uint64
+	row := g.db.Model(&gormReport{}).
+		Select("COUNT(DISTINCT reporter_ip)").
+		Where("shorten_url = ? AND status = ?", shortenURL, ReportStatusOpen).
+		Row()
+	if err := row.Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// SuspendURL disables url pending review, unless it already is.
+func (g *gormService) SuspendURL(shortenURL string, suspendedAt time.Time) error {
+	execute := g.db.Model(&gormURL{}).Where("shorten_url = ? AND suspended_at IS NULL", shortenURL).UpdateColumn("suspended_at", suspendedAt)
+	return execute.Error
+}
+
+// GetReports lists reports with status, the oldest first so that the queue is worked through in order.
+func (g *gormService) GetReports(status string, offset uint64, limit uint64) (uint64, []Report, error) {
+	var count uint64
+	if err := g.db.Model(&gormReport{}).Where("status = ?", status).Count(&count).Error; err != nil {
+		return 0, nil, err
+	}
+
+	var gormReports []gormReport
+	execute := g.db.Where("status = ?", status).Order("created_at, id").Offset(offset).Limit(limit).Find(&gormReports)
+	if err := execute.Error; err != nil {
+		return 0, nil, err
+	}
+
+	reports := make([]Report, len(gormReports))
+	for i, report := range gormReports {
+		reports[i] = report.toReport()
+	}
+
+	return count, reports, nil
+}
+
+// ResolveReports closes every open report about url with status. Dismissing them lifts the suspension of url.
+func (g *gormService) ResolveReports(shortenURL string, status string, reviewer User, reviewedAt time.Time) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		execute := tx.Model(&gormReport{}).Where("shorten_url = ? AND status = ?", shortenURL, ReportStatusOpen).UpdateColumns(map[string]interface{}{
+			"status":      status,
+			"reviewed_by": reviewer.UserID,
+			"reviewed_at": reviewedAt,
+		})
+		if err := execute.Error; err != nil {
+			return err
+		}
+
+		if status != ReportStatusDismissed {
+			return nil
+		}
+		return tx.Unscoped().Model(&gormURL{}).Where("shorten_url = ?", shortenURL).UpdateColumn("suspended_at", nil).Error
+	})
+}
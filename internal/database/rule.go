@@ -0,0 +1,64 @@
+package database
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+type gormURLRule struct {
+	ID          uint `gorm:"primary_key"`
+	ShortenURL  string
+	Position    int
+	Device      string
+	Country     string
+	Language    string
+	Destination string `gorm:"type:text"`
+}
+
+func (g *gormService) initRules() {
+	if hasURLRuleTable := g.db.HasTable(&gormURLRule{}); !hasURLRuleTable {
+		g.db.CreateTable(&gormURLRule{})
+		g.db.Model(&gormURLRule{}).AddIndex("idx_shorten_url_position", "shorten_url", "position")
+	}
+}
+
+func (g *gormService) getURLRules(shortenURL string) ([]RedirectRule, error) {
+	var rs []gormURLRule
+	if err := g.db.Where("shorten_url = ?", shortenURL).Order("position").Find(&rs).Error; err != nil {
+		return nil, err
+	}
+
+	rules := make([]RedirectRule, len(rs))
+	for i, r := range rs {
+		rules[i] = RedirectRule{
+			Device:      r.Device,
+			Country:     r.Country,
+			Language:    r.Language,
+			Destination: r.Destination,
+		}
+	}
+	return rules, nil
+}
+
+// SetURLRules replaces rules of url, keeping their order.
+func (g *gormService) SetURLRules(shortenURL string, rules []RedirectRule) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("shorten_url = ?", shortenURL).Delete(&gormURLRule{}).Error; err != nil {
+			return err
+		}
+
+		for i, rule := range rules {
+			r := gormURLRule{
+				ShortenURL:  shortenURL,
+				Position:    i,
+				Device:      rule.Device,
+				Country:     rule.Country,
+				Language:    rule.Language,
+				Destination: rule.Destination,
+			}
+			if err := tx.Create(&r).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"github.com/jinzhu/gorm"
+	"time"
+)
+
+type gormURLScheduleWindow struct {
+	ID          uint   `gorm:"primary_key"`
+	ShortenURL  string `gorm:"index:idx_shorten_url"`
+	Position    int
+	Days        int // bit 1 << time.Weekday set for each day
+	StartMinute int
+	EndMinute   int
+}
+
+func nilIfZero(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func daysToMask(days []time.Weekday) int {
+	mask := 0
+	for _, day := range days {
+		mask |= 1 << uint(day)
+	}
+	return mask
+}
+
+func daysFromMask(mask int) []time.Weekday {
+	days := []time.Weekday{}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if mask&(1<<uint(day)) != 0 {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+func (g *gormService) initSchedules() {
+	if hasScheduleWindowTable := g.db.HasTable(&gormURLScheduleWindow{}); !hasScheduleWindowTable {
+		g.db.CreateTable(&gormURLScheduleWindow{})
+	}
+}
+
+// getURLSchedule returns the schedule of url, nil if it has no windows.
+func (g *gormService) getURLSchedule(url gormURL) (*Schedule, error) {
+	var ws []gormURLScheduleWindow
+	if err := g.db.Where("shorten_url = ?", url.ShortenURL).Order("position").Find(&ws).Error; err != nil {
+		return nil, err
+	}
+	if len(ws) == 0 {
+		return nil, nil
+	}
+
+	schedule := &Schedule{
+		Timezone:     url.ScheduleTimezone,
+		Windows:      make([]ScheduleWindow, len(ws)),
+		AlternateURL: url.ScheduleAlternateURL,
+	}
+	for i, w := range ws {
+		schedule.Windows[i] = ScheduleWindow{
+			Days:  daysFromMask(w.Days),
+			Start: w.StartMinute,
+			End:   w.EndMinute,
+		}
+	}
+	return schedule, nil
+}
+
+// SetURLSchedule replaces the schedule of url, nil making it active around the clock again.
+func (g *gormService) SetURLSchedule(shortenURL string, schedule *Schedule) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		if schedule == nil {
+			schedule = &Schedule{}
+		}
+		execute := tx.Model(&gormURL{}).Where("shorten_url = ?", shortenURL).UpdateColumns(map[string]interface{}{
+			"schedule_timezone":      schedule.Timezone,
+			"schedule_alternate_url": schedule.AlternateURL,
+		})
+		if err := execute.Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("shorten_url = ?", shortenURL).Delete(&gormURLScheduleWindow{}).Error; err != nil {
+			return err
+		}
+		for i, window := range schedule.Windows {
+			w := gormURLScheduleWindow{
+				ShortenURL:  shortenURL,
+				Position:    i,
+				Days:        daysToMask(window.Days),
+				StartMinute: window.Start,
+				EndMinute:   window.End,
+			}
+			if err := tx.Create(&w).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"github.com/jinzhu/gorm"
+	"time"
+)
+
+// gormURLTombstone remembers a purged shorten url so that it is never issued again.
+type gormURLTombstone struct {
+	ShortenURL string `gorm:"primary_key"`
+	PurgedAt   time.Time
+}
+
+func (g *gormService) initTrash() {
+	if hasURLTombstoneTable := g.db.HasTable(&gormURLTombstone{}); !hasURLTombstoneTable {
+		g.db.CreateTable(&gormURLTombstone{})
+	}
+}
+
+// isShortenURLTaken tells whether shortenURL belongs to a url, deleted or not, or to a purged one.
+func isShortenURLTaken(tx *gorm.DB, shortenURL string) (bool, error) {
+	var count int
+	if err := tx.Unscoped().Model(&gormURL{}).Where("shorten_url = ?", shortenURL).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	if err := tx.Model(&gormURLTombstone{}).Where("shorten_url = ?", shortenURL).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetDeletedURL returns url in the trash with given shortenURL.
+func (g *gormService) GetDeletedURL(shortenURL string) (*URL, error) {
+	var gormURL gormURL
+	execute := g.db.Unscoped().Where("shorten_url = ? AND deleted_at IS NOT NULL", shortenURL).First(&gormURL)
+
+	if execute.RecordNotFound() {
+		return nil, NewRecordNotFoundError()
+	}
+
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	urls := []URL{gormURL.toURL()}
+	if err := g.attachTags(urls); err != nil {
+		return nil, err
+	}
+	return &urls[0], nil
+}
+
+// GetDeletedURLsInWorkspace lists urls of workspace in the trash, the most recently deleted first.
+func (g *gormService) GetDeletedURLsInWorkspace(workspaceID string) ([]URL, error) {
+	var gormUrls []gormURL
+	execute := g.db.Unscoped().Where("owner = ? AND deleted_at IS NOT NULL", workspaceID).Order("deleted_at desc").Find(&gormUrls)
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	urls := make([]URL, len(gormUrls))
+	for i, url := range gormUrls {
+		urls[i] = url.toURL()
+	}
+	if err := g.attachTags(urls); err != nil {
+		return nil, err
+	}
+
+	return urls, nil
+}
+
+// RestoreURL takes url out of the trash.
+func (g *gormService) RestoreURL(shortenURL string) error {
+	execute := g.db.Unscoped().Model(&gormURL{}).Where("shorten_url = ? AND deleted_at IS NOT NULL", shortenURL).UpdateColumn("deleted_at", nil)
+	if err := execute.Error; err != nil {
+		return err
+	}
+
+	if execute.RowsAffected == 0 {
+		return NewRecordNotFoundError()
+	}
+
+	return nil
+}
+
+// PurgeURLsDeletedBefore permanently removes up to limit urls moved to the trash before deletedBefore, along with
+// everything attached to them, and returns how many were purged. Their shorten urls are kept as tombstones.
+func (g *gormService) PurgeURLsDeletedBefore(deletedBefore time.Time, limit uint64) (uint64, error) {
+	var gormUrls []gormURL
+	execute := g.db.Unscoped().Select("shorten_url").Where("deleted_at < ?", deletedBefore).Order("deleted_at").Limit(limit).Find(&gormUrls)
+	if err := execute.Error; err != nil {
+		return 0, err
+	}
+
+	var purged uint64
+	for _, url := range gormUrls {
+		if err := g.purgeURL(url.ShortenURL); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+func (g *gormService) purgeURL(shortenURL string) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&gormURLTombstone{ShortenURL: shortenURL, PurgedAt: time.Now()}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("shorten_url = ?", shortenURL).Delete(&gormURL{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("shorten_url = ?", shortenURL).Delete(&gormURLTag{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("shorten_url = ?", shortenURL).Delete(&gormURLRule{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("shorten_url = ?", shortenURL).Delete(&gormURLVariant{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("shorten_url = ?", shortenURL).Delete(&gormPageLink{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("shorten_url = ?", shortenURL).Delete(&gormURLScheduleWindow{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("shorten_url = ?", shortenURL).Delete(&gormURLCheck{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("shorten_url = ?", shortenURL).Delete(&gormReport{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("shorten_url = ?", shortenURL).Delete(&gormWebhookMilestone{}).Error
+	})
+}
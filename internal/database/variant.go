@@ -0,0 +1,73 @@
+package database
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+type gormURLVariant struct {
+	ID          uint64 `gorm:"primary_key"`
+	ShortenURL  string `gorm:"index:idx_shorten_url"`
+	Position    int
+	Destination string `gorm:"type:text"`
+	Weight      int
+	Hits        int64
+}
+
+func (g *gormService) initVariants() {
+	if hasURLVariantTable := g.db.HasTable(&gormURLVariant{}); !hasURLVariantTable {
+		g.db.CreateTable(&gormURLVariant{})
+	}
+}
+
+func (g *gormService) getURLVariants(shortenURL string) ([]URLVariant, error) {
+	var vs []gormURLVariant
+	if err := g.db.Where("shorten_url = ?", shortenURL).Order("position").Find(&vs).Error; err != nil {
+		return nil, err
+	}
+
+	variants := make([]URLVariant, len(vs))
+	for i, v := range vs {
+		variants[i] = URLVariant{
+			ID:          v.ID,
+			Destination: v.Destination,
+			Weight:      v.Weight,
+			Hits:        v.Hits,
+		}
+	}
+	return variants, nil
+}
+
+// SetURLVariants replaces variants of url, keeping their order. Replaced variants start over with new ids and no hits.
+func (g *gormService) SetURLVariants(shortenURL string, variants []URLVariant) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("shorten_url = ?", shortenURL).Delete(&gormURLVariant{}).Error; err != nil {
+			return err
+		}
+
+		for i, variant := range variants {
+			v := gormURLVariant{
+				ShortenURL:  shortenURL,
+				Position:    i,
+				Destination: variant.Destination,
+				Weight:      variant.Weight,
+			}
+			if err := tx.Create(&v).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (g *gormService) IncreaseURLVariantCount(id uint64) error {
+	execute := g.db.Model(&gormURLVariant{}).Where("id = ?", id).UpdateColumn("hits", gorm.Expr("hits + ?", 1))
+	if err := execute.Error; err != nil {
+		return err
+	}
+
+	if execute.RowsAffected == 0 {
+		return NewRecordNotFoundError()
+	}
+
+	return nil
+}
@@ -0,0 +1,402 @@
+package database
+
+import (
+	"github.com/jinzhu/gorm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type gormWebhook struct {
+	ID                  string `gorm:"primary_key"`
+	WorkspaceID         string
+	URL                 string `gorm:"type:text"`
+	Secret              string
+	Events              string // enclosed in commas, e.g. ",url.created,url.deleted,", to be matched with LIKE
+	ClickThresholds     string // comma separated
+	CreatedBy           string
+	ConsecutiveFailures int
+	DisabledAt          *time.Time
+	CreatedAt           time.Time
+}
+
+type gormWebhookDelivery struct {
+	ID             uint64 `gorm:"primary_key"`
+	WebhookID      string
+	Event          string
+	Payload        string `gorm:"type:text"`
+	Status         string
+	Attempts       int
+	ResponseStatus int
+	Error          string `gorm:"type:text"`
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// gormWebhookMilestone remembers a click threshold notified for a url, so that it is notified once.
+type gormWebhookMilestone struct {
+	WebhookID  string `gorm:"primary_key"`
+	ShortenURL string `gorm:"primary_key"`
+	Clicks     uint64 `gorm:"primary_key;auto_increment:false"`
+}
+
+func newGormWebhook(webhook Webhook) gormWebhook {
+	thresholds := make([]string, len(webhook.ClickThresholds))
+	for i, t := range webhook.ClickThresholds {
+		thresholds[i] = strconv.FormatUint(t, 10)
+	}
+	w := gormWebhook{
+		ID:                  webhook.ID,
+		WorkspaceID:         webhook.WorkspaceID,
+		URL:                 webhook.URL,
+		Secret:              webhook.Secret,
+		Events:              "," + strings.Join(webhook.Events, ",") + ",",
+		ClickThresholds:     strings.Join(thresholds, ","),
+		CreatedBy:           webhook.CreatedBy,
+		ConsecutiveFailures: webhook.ConsecutiveFailures,
+		CreatedAt:           webhook.CreatedAt,
+	}
+	if !webhook.DisabledAt.IsZero() {
+		disabledAt := webhook.DisabledAt
+		w.DisabledAt = &disabledAt
+	}
+	return w
+}
+
+func (w gormWebhook) toWebhook() Webhook {
+	webhook := Webhook{
+		ID:                  w.ID,
+		WorkspaceID:         w.WorkspaceID,
+		URL:                 w.URL,
+		Secret:              w.Secret,
+		Events:              []string{},
+		ClickThresholds:     []uint64{},
+		CreatedBy:           w.CreatedBy,
+		ConsecutiveFailures: w.ConsecutiveFailures,
+		CreatedAt:           w.CreatedAt,
+	}
+	for _, event := range strings.Split(w.Events, ",") {
+		if event != "" {
+			webhook.Events = append(webhook.Events, event)
+		}
+	}
+	for _, t := range strings.Split(w.ClickThresholds, ",") {
+		if clicks, err := strconv.ParseUint(t, 10, 64); err == nil {
+			webhook.ClickThresholds = append(webhook.ClickThresholds, clicks)
+		}
+	}
+	if w.DisabledAt != nil {
+		webhook.DisabledAt = *w.DisabledAt
+	}
+	return webhook
+}
+
+func (d gormWebhookDelivery) toWebhookDelivery() WebhookDelivery {
+	delivery := WebhookDelivery{
+		ID:             d.ID,
+		WebhookID:      d.WebhookID,
+		Event:          d.Event,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		Error:          d.Error,
+		NextAttemptAt:  d.NextAttemptAt,
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.DeliveredAt != nil {
+		delivery.DeliveredAt = *d.DeliveredAt
+	}
+	return delivery
+}
+
+func (g *gormService) initWebhooks() {
+	if hasWebhookTable := g.db.HasTable(&gormWebhook{}); !hasWebhookTable {
+		g.db.CreateTable(&gormWebhook{})
+		g.db.Model(&gormWebhook{}).AddIndex("idx_workspace_id", "workspace_id")
+	}
+	if hasWebhookDeliveryTable := g.db.HasTable(&gormWebhookDelivery{}); !hasWebhookDeliveryTable {
+		g.db.CreateTable(&gormWebhookDelivery{})
+		g.db.Model(&gormWebhookDelivery{}).AddIndex("idx_status_next_attempt_at", "status", "next_attempt_at")
+		g.db.Model(&gormWebhookDelivery{}).AddIndex("idx_webhook_id", "webhook_id")
+		g.db.Model(&gormWebhookDelivery{}).AddIndex("idx_created_at", "created_at")
+	}
+	if hasWebhookMilestoneTable := g.db.HasTable(&gormWebhookMilestone{}); !hasWebhookMilestoneTable {
+		g.db.CreateTable(&gormWebhookMilestone{})
+	}
+}
+
+// reachMilestones marks thresholds of webhook already reached by urls of its workspace as notified, so that
+// subscribing does not notify every url hit before.
+func reachMilestones(tx *gorm.DB, webhook Webhook) error {
+	for _, clicks := range webhook.ClickThresholds {
+		err := tx.Exec("INSERT IGNORE INTO gorm_webhook_milestones (webhook_id, shorten_url, clicks) "+
+			"SELECT ?, shorten_url, ? FROM gorm_urls WHERE owner = ? AND count >= ? AND deleted_at IS NULL",
+			webhook.ID, clicks, webhook.WorkspaceID, clicks).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *gormService) CreateWebhook(webhook Webhook) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		w := newGormWebhook(webhook)
+		if err := tx.Create(&w).Error; err != nil {
+			return err
+		}
+		return reachMilestones(tx, webhook)
+	})
+}
+
+func (g *gormService) GetWebhook(id string) (*Webhook, error) {
+	var w gormWebhook
+	execute := g.db.Where("id = ?", id).First(&w)
+	if execute.RecordNotFound() {
+		return nil, NewRecordNotFoundError()
+	}
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	webhook := w.toWebhook()
+	return &webhook, nil
+}
+
+func (g *gormService) GetWebhooksInWorkspace(workspaceID string) ([]Webhook, error) {
+	var ws []gormWebhook
+	if err := g.db.Where("workspace_id = ?", workspaceID).Order("created_at").Find(&ws).Error; err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]Webhook, len(ws))
+	for i, w := range ws {
+		webhooks[i] = w.toWebhook()
+	}
+	return webhooks, nil
+}
+
+// GetWebhooksWithEvent returns enabled webhooks subscribed to event.
+func (g *gormService) GetWebhooksWithEvent(event string) ([]Webhook, error) {
+	var ws []gormWebhook
+	execute := g.db.Where("disabled_at IS NULL AND events LIKE ?", "%,"+event+",%").Order("created_at").Find(&ws)
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]Webhook, len(ws))
+	for i, w := range ws {
+		webhooks[i] = w.toWebhook()
+	}
+	return webhooks, nil
+}
+
+// UpdateWebhook saves the endpoint, subscriptions and state of webhook. Thresholds added are only notified for
+// urls reaching them from now on.
+func (g *gormService) UpdateWebhook(webhook Webhook) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		w := newGormWebhook(webhook)
+		execute := tx.Model(&gormWebhook{}).Where("id = ?", webhook.ID).UpdateColumns(map[string]interface{}{
+			"url":                  w.URL,
+			"events":               w.Events,
+			"click_thresholds":     w.ClickThresholds,
+			"consecutive_failures": w.ConsecutiveFailures,
+			"disabled_at":          w.DisabledAt,
+		})
+		if err := execute.Error; err != nil {
+			return err
+		}
+		return reachMilestones(tx, webhook)
+	})
+}
+
+// DeleteWebhook removes webhook along with its deliveries.
+func (g *gormService) DeleteWebhook(id string) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&gormWebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("webhook_id = ?", id).Delete(&gormWebhookMilestone{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&gormWebhook{}).Error
+	})
+}
+
+func deleteWorkspaceWebhooks(tx *gorm.DB, workspaceID string) error {
+	ids := tx.Model(&gormWebhook{}).Select("id").Where("workspace_id = ?", workspaceID).SubQuery()
+	if err := tx.Where("webhook_id IN ?", ids).Delete(&gormWebhookDelivery{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("webhook_id IN ?", ids).Delete(&gormWebhookMilestone{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("workspace_id = ?", workspaceID).Delete(&gormWebhook{}).Error
+}
+
+// EnqueueWebhookDeliveries queues payload for every enabled webhook of workspace subscribed to event, returning
+// how many were queued.
+func (g *gormService) EnqueueWebhookDeliveries(workspaceID string, event string, payload string) (uint64, error) {
+	var queued uint64
+	err := g.db.Transaction(func(tx *gorm.DB) error {
+		var ws []gormWebhook
+		execute := tx.Select("id").Where("workspace_id = ? AND disabled_at IS NULL AND events LIKE ?", workspaceID, "%,"+event+",%").Find(&ws)
+		if err := execute.Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, w := range ws {
+			d := gormWebhookDelivery{
+				WebhookID:     w.ID,
+				Event:         event,
+				Payload:       payload,
+				Status:        WebhookDeliveryStatusPending,
+				NextAttemptAt: now,
+				CreatedAt:     now,
+			}
+			if err := tx.Create(&d).Error; err != nil {
+				return err
+			}
+			queued++
+		}
+		return nil
+	})
+	return queued, err
+}
+
+// GetURLsReachingClicks returns urls of the workspace of webhook hit at least clicks times, which webhook was not
+// notified about yet.
+func (g *gormService) GetURLsReachingClicks(webhook Webhook, clicks uint64, limit uint64) ([]URL, error) {
+	var gormUrls []gormURL
+	execute := g.db.
+		Where("owner = ? AND count >= ?", webhook.WorkspaceID, clicks).
+		Where("NOT EXISTS (SELECT 1 FROM gorm_webhook_milestones m "+
+			"WHERE m.webhook_id = ? AND m.shorten_url = gorm_urls.shorten_url AND m.clicks = ?)", webhook.ID, clicks).
+		Order("shorten_url").
+		Limit(limit).
+		Find(&gormUrls)
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	urls := make([]URL, len(gormUrls))
+	for i, url := range gormUrls {
+		urls[i] = url.toURL()
+	}
+	return urls, nil
+}
+
+// EnqueueWebhookClicksDelivery queues delivery about shortenURL reaching clicks, marking the threshold notified.
+func (g *gormService) EnqueueWebhookClicksDelivery(delivery WebhookDelivery, shortenURL string, clicks uint64) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		m := gormWebhookMilestone{
+			WebhookID:  delivery.WebhookID,
+			ShortenURL: shortenURL,
+			Clicks:     clicks,
+		}
+		if err := tx.Create(&m).Error; err != nil {
+			return err
+		}
+
+		d := gormWebhookDelivery{
+			WebhookID:     delivery.WebhookID,
+			Event:         delivery.Event,
+			Payload:       delivery.Payload,
+			Status:        WebhookDeliveryStatusPending,
+			NextAttemptAt: delivery.NextAttemptAt,
+		}
+		return tx.Create(&d).Error
+	})
+}
+
+// GetDueWebhookDeliveries returns pending deliveries of enabled webhooks to attempt at now, the most overdue first.
+func (g *gormService) GetDueWebhookDeliveries(now time.Time, limit uint64) ([]WebhookDelivery, error) {
+	var ds []gormWebhookDelivery
+	execute := g.db.Table("gorm_webhook_deliveries d").
+		Select("d.*").
+		Joins("JOIN gorm_webhooks w ON w.id = d.webhook_id").
+		Where("d.status = ? AND d.next_attempt_at <= ? AND w.disabled_at IS NULL", WebhookDeliveryStatusPending, now).
+		Order("d.next_attempt_at").
+		Limit(limit).
+		Find(&ds)
+	if err := execute.Error; err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]WebhookDelivery, len(ds))
+	for i, d := range ds {
+		deliveries[i] = d.toWebhookDelivery()
+	}
+	return deliveries, nil
+}
+
+// RecordWebhookDelivery saves the outcome of an attempt of delivery. Failed attempts count towards disabling its
+// webhook, which happens at and returns true once disableAfter attempts failed in a row, successful ones reset
+// the count. Webhooks are never disabled if disableAfter is 0.
+func (g *gormService) RecordWebhookDelivery(delivery WebhookDelivery, disableAfter int, at time.Time) (bool, error) {
+	disabled := false
+	err := g.db.Transaction(func(tx *gorm.DB) error {
+		columns := map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"response_status": delivery.ResponseStatus,
+			"error":           delivery.Error,
+			"next_attempt_at": delivery.NextAttemptAt,
+		}
+		if !delivery.DeliveredAt.IsZero() {
+			columns["delivered_at"] = delivery.DeliveredAt
+		}
+		if err := tx.Model(&gormWebhookDelivery{}).Where("id = ?", delivery.ID).UpdateColumns(columns).Error; err != nil {
+			return err
+		}
+
+		webhook := tx.Model(&gormWebhook{}).Where("id = ?", delivery.WebhookID)
+		if delivery.Status == WebhookDeliveryStatusDelivered {
+			return webhook.UpdateColumn("consecutive_failures", 0).Error
+		}
+		if err := webhook.UpdateColumn("consecutive_failures", gorm.Expr("consecutive_failures + ?", 1)).Error; err != nil {
+			return err
+		}
+		if disableAfter <= 0 {
+			return nil
+		}
+
+		execute := tx.Model(&gormWebhook{}).
+			Where("id = ? AND disabled_at IS NULL AND consecutive_failures >= ?", delivery.WebhookID, disableAfter).
+			UpdateColumn("disabled_at", at)
+		if err := execute.Error; err != nil {
+			return err
+		}
+		disabled = execute.RowsAffected > 0
+		return nil
+	})
+	return disabled, err
+}
+
+// GetWebhookDeliveries lists deliveries of webhook, the newest first, along with how many there are.
+func (g *gormService) GetWebhookDeliveries(webhookID string, offset uint64, limit uint64) (uint64, []WebhookDelivery, error) {
+	filtered := g.db.Model(&gormWebhookDelivery{}).Where("webhook_id = ?", webhookID)
+
+	var total uint64
+	if err := filtered.Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+
+	var ds []gormWebhookDelivery
+	if err := filtered.Order("id desc").Offset(offset).Limit(limit).Find(&ds).Error; err != nil {
+		return 0, nil, err
+	}
+
+	deliveries := make([]WebhookDelivery, len(ds))
+	for i, d := range ds {
+		deliveries[i] = d.toWebhookDelivery()
+	}
+	return total, deliveries, nil
+}
+
+func (g *gormService) DeleteWebhookDeliveriesBefore(createdBefore time.Time) error {
+	return g.db.Where("created_at < ?", createdBefore).Delete(&gormWebhookDelivery{}).Error
+}
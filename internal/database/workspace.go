@@ -0,0 +1,281 @@
+package database
+
+import (
+	"github.com/jinzhu/gorm"
+	"strings"
+	"time"
+)
+
+type gormWorkspace struct {
+	ID        string `gorm:"primary_key"`
+	Name      string
+	Personal  bool
+	CreatedAt time.Time
+}
+
+func (w gormWorkspace) toWorkspace() Workspace {
+	return Workspace{
+		ID:        w.ID,
+		Name:      w.Name,
+		Personal:  w.Personal,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+type gormWorkspaceMember struct {
+	WorkspaceID string `gorm:"primary_key"`
+	UserID      string `gorm:"primary_key"`
+	Role        string
+	CreatedAt   time.Time
+}
+
+type gormWorkspaceInvitation struct {
+	TokenHash   string `gorm:"primary_key"`
+	WorkspaceID string
+	Email       string
+	Role        string
+	InvitedBy   string
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+}
+
+func (g *gormService) initWorkspaces() {
+	if hasWorkspaceTable := g.db.HasTable(&gormWorkspace{}); !hasWorkspaceTable {
+		g.db.CreateTable(&gormWorkspace{})
+	}
+
+	if hasMemberTable := g.db.HasTable(&gormWorkspaceMember{}); !hasMemberTable {
+		g.db.CreateTable(&gormWorkspaceMember{})
+		g.db.Model(&gormWorkspaceMember{}).AddIndex("idx_user_id", "user_id")
+	}
+
+	if hasInvitationTable := g.db.HasTable(&gormWorkspaceInvitation{}); !hasInvitationTable {
+		g.db.CreateTable(&gormWorkspaceInvitation{})
+		g.db.Model(&gormWorkspaceInvitation{}).AddIndex("idx_workspace_id", "workspace_id")
+	}
+
+	// note: users registered before workspaces existed get their personal workspace here
+	g.db.Exec("INSERT IGNORE INTO gorm_workspaces (id, name, personal, created_at) SELECT user_id, email, true, NOW() FROM gorm_users")
+	g.db.Exec("INSERT IGNORE INTO gorm_workspace_members (workspace_id, user_id, role, created_at) SELECT user_id, user_id, ?, NOW() FROM gorm_users", WorkspaceRoleOwner)
+}
+
+func createPersonalWorkspace(tx *gorm.DB, user User) error {
+	w := gormWorkspace{
+		ID:       user.UserID,
+		Name:     user.Email,
+		Personal: true,
+	}
+	if err := tx.Create(&w).Error; err != nil {
+		return err
+	}
+
+	m := gormWorkspaceMember{
+		WorkspaceID: user.UserID,
+		UserID:      user.UserID,
+		Role:        WorkspaceRoleOwner,
+	}
+	return tx.Create(&m).Error
+}
+
+// checkNotLastOwner returns LastOwnerError if user is the only owner of a shared workspace.
+func checkNotLastOwner(tx *gorm.DB, user User) error {
+	var count int
+	err := tx.Table("gorm_workspace_members m").
+		Joins("JOIN gorm_workspaces w ON w.id = m.workspace_id").
+		Where("m.user_id = ? AND m.role = ? AND w.personal = ?", user.UserID, WorkspaceRoleOwner, false).
+		Where("NOT EXISTS (SELECT 1 FROM gorm_workspace_members o WHERE o.workspace_id = m.workspace_id AND o.role = ? AND o.user_id <> m.user_id)", WorkspaceRoleOwner).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return NewLastOwnerError()
+	}
+	return nil
+}
+
+func deletePersonalWorkspace(tx *gorm.DB, user User) error {
+	if err := tx.Where("user_id = ?", user.UserID).Delete(&gormWorkspaceMember{}).Error; err != nil {
+		return err
+	}
+
+	// note: trashed urls are purged after retention like any other, their codes are never issued again
+	if err := tx.Where("owner = ?", user.UserID).Delete(&gormURL{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Where("id = ? AND personal = ?", user.UserID, true).Delete(&gormWorkspace{}).Error
+}
+
+func (g *gormService) CreateWorkspace(workspace Workspace, owner User) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		w := gormWorkspace{
+			ID:   workspace.ID,
+			Name: workspace.Name,
+		}
+		if err := tx.Create(&w).Error; err != nil {
+			return err
+		}
+
+		m := gormWorkspaceMember{
+			WorkspaceID: workspace.ID,
+			UserID:      owner.UserID,
+			Role:        WorkspaceRoleOwner,
+		}
+		return tx.Create(&m).Error
+	})
+}
+
+func (g *gormService) GetMembershipsWithUser(user User) ([]Membership, error) {
+	rows, err := g.db.Table("gorm_workspace_members m").
+		Select("w.id, w.name, w.personal, w.created_at, m.role").
+		Joins("JOIN gorm_workspaces w ON w.id = m.workspace_id").
+		Where("m.user_id = ?", user.UserID).
+		Order("w.personal desc, w.name").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := []Membership{}
+	for rows.Next() {
+		var m Membership
+		if err := rows.Scan(&m.Workspace.ID, &m.Workspace.Name, &m.Workspace.Personal, &m.Workspace.CreatedAt, &m.Role); err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, m)
+	}
+
+	return memberships, rows.Err()
+}
+
+func (g *gormService) GetWorkspaceRole(workspaceID string, userID string) (string, error) {
+	var member gormWorkspaceMember
+	execute := g.db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member)
+
+	if execute.RecordNotFound() {
+		return "", NewRecordNotFoundError()
+	}
+
+	if err := execute.Error; err != nil {
+		return "", err
+	}
+
+	return member.Role, nil
+}
+
+func (g *gormService) GetWorkspaceMembers(workspaceID string) ([]WorkspaceMember, error) {
+	rows, err := g.db.Table("gorm_workspace_members m").
+		Select("m.role, u.user_id, u.email, u.type, u.dead_link_alerts_disabled").
+		Joins("JOIN gorm_users u ON u.user_id = m.user_id").
+		Where("m.workspace_id = ?", workspaceID).
+		Order("m.created_at").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []WorkspaceMember{}
+	for rows.Next() {
+		member := WorkspaceMember{WorkspaceID: workspaceID}
+		if err := rows.Scan(&member.Role, &member.User.UserID, &member.User.Email, &member.User.Type, &member.User.DeadLinkAlertsDisabled); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+func (g *gormService) SetWorkspaceMemberRole(workspaceID string, userID string, role string) error {
+	execute := g.db.Model(&gormWorkspaceMember{}).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).UpdateColumn("role", role)
+	if err := execute.Error; err != nil {
+		return err
+	}
+
+	if execute.RowsAffected == 0 {
+		return NewRecordNotFoundError()
+	}
+
+	return nil
+}
+
+func (g *gormService) DeleteWorkspaceMember(workspaceID string, userID string) error {
+	execute := g.db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&gormWorkspaceMember{})
+	if err := execute.Error; err != nil {
+		return err
+	}
+
+	if execute.RowsAffected == 0 {
+		return NewRecordNotFoundError()
+	}
+
+	return nil
+}
+
+func (g *gormService) CreateWorkspaceInvitation(invitation WorkspaceInvitation) error {
+	i := gormWorkspaceInvitation{
+		TokenHash:   invitation.TokenHash,
+		WorkspaceID: invitation.WorkspaceID,
+		Email:       strings.ToLower(invitation.Email),
+		Role:        invitation.Role,
+		InvitedBy:   invitation.InvitedBy,
+		ExpiresAt:   invitation.ExpiresAt,
+	}
+	return g.db.Create(&i).Error
+}
+
+// AcceptWorkspaceInvitation makes user a member as the invitation tells, which is consumed.
+// RecordNotFoundError returns if invitation does not exist, expired, or was sent to another email.
+func (g *gormService) AcceptWorkspaceInvitation(tokenHash string, user User) (*WorkspaceInvitation, error) {
+	var invitation *WorkspaceInvitation
+	err := g.db.Transaction(func(tx *gorm.DB) error {
+		var i gormWorkspaceInvitation
+		execute := tx.Set("gorm:query_option", "FOR UPDATE").
+			Where("token_hash = ? AND expires_at > ? AND email = ?", tokenHash, time.Now(), strings.ToLower(user.Email)).
+			First(&i)
+		if execute.RecordNotFound() {
+			return NewRecordNotFoundError()
+		}
+		if err := execute.Error; err != nil {
+			return err
+		}
+
+		// note: accepting keeps the higher of current and invited role
+		var m gormWorkspaceMember
+		execute = tx.Where("workspace_id = ? AND user_id = ?", i.WorkspaceID, user.UserID).First(&m)
+		if execute.RecordNotFound() {
+			m = gormWorkspaceMember{WorkspaceID: i.WorkspaceID, UserID: user.UserID, Role: i.Role}
+			if err := tx.Create(&m).Error; err != nil {
+				return err
+			}
+		} else if err := execute.Error; err != nil {
+			return err
+		} else if !WorkspaceRoleAllows(m.Role, i.Role) {
+			if err := tx.Model(&m).UpdateColumn("role", i.Role).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Delete(&i).Error; err != nil {
+			return err
+		}
+
+		invitation = &WorkspaceInvitation{
+			TokenHash:   i.TokenHash,
+			WorkspaceID: i.WorkspaceID,
+			Email:       i.Email,
+			Role:        i.Role,
+			InvitedBy:   i.InvitedBy,
+			ExpiresAt:   i.ExpiresAt,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+var (
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+
+	redacted = "[REDACTED]"
+
+	// sensitiveFields lists field keys whose values never reach the log output
+	sensitiveFields = map[string]bool{
+		"password":      true,
+		"code":          true,
+		"token":         true,
+		"access_token":  true,
+		"secret":        true,
+		"client_secret": true,
+		"jwt_key":       true,
+		"cookie":        true,
+		"authorization": true,
+	}
+)
+
+// Configure applies level and output format to logger and installs redaction of sensitive fields.
+func Configure(logger *logrus.Logger, level string, format string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	logger.SetLevel(lvl)
+
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	case FormatLogfmt:
+		logger.SetFormatter(&logrus.TextFormatter{DisableColors: true, FullTimestamp: true})
+	default:
+		return fmt.Errorf("unsupported log format: %v", format)
+	}
+
+	logger.AddHook(redactionHook{})
+	return nil
+}
+
+// FromContext returns the request-scoped logger set by middleware.RequestLogger, standard logger otherwise.
+func FromContext(context *gin.Context) *logrus.Entry {
+	if entry, ok := context.Value("logger").(*logrus.Entry); ok {
+		return entry
+	}
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+// Redact masks value for output, keeping only whether it was set.
+func Redact(value string) string {
+	if value == "" {
+		return ""
+	}
+	return redacted
+}
+
+type redactionHook struct{}
+
+func (redactionHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (redactionHook) Fire(entry *logrus.Entry) error {
+	// copy fields before masking since entries derived from the same logger share the map
+	data := make(logrus.Fields, len(entry.Data))
+	for key, value := range entry.Data {
+		if sensitiveFields[strings.ToLower(key)] {
+			value = redacted
+		}
+		data[key] = value
+	}
+	entry.Data = data
+	return nil
+}
@@ -0,0 +1,56 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"url-shortener/internal/logging"
+)
+
+var _ = Describe("Logging", func() {
+	var (
+		logger *logrus.Logger
+		output *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		output = &bytes.Buffer{}
+		logger = logrus.New()
+		logger.SetOutput(output)
+	})
+
+	Describe("Configure", func() {
+		It("should reject unknown level or format", func() {
+			Expect(logging.Configure(logger, "verbose", logging.FormatJSON)).To(HaveOccurred())
+			Expect(logging.Configure(logger, "info", "xml")).To(HaveOccurred())
+		})
+
+		It("should drop entries below configured level", func() {
+			Expect(logging.Configure(logger, "warn", logging.FormatJSON)).To(Succeed())
+			logger.Info("hidden")
+			Expect(output.Len()).To(Equal(0))
+			logger.Warn("shown")
+			Expect(output.String()).To(ContainSubstring("shown"))
+		})
+	})
+
+	Describe("Redaction", func() {
+		It("should mask sensitive fields without touching the parent entry", func() {
+			Expect(logging.Configure(logger, "info", logging.FormatJSON)).To(Succeed())
+			entry := logger.WithField("code", "123456")
+			entry.WithFields(logrus.Fields{
+				"password": "secret-password",
+				"email":    "test@test.com",
+			}).Info("Registration")
+
+			var line map[string]interface{}
+			Expect(json.Unmarshal(output.Bytes(), &line)).To(Succeed())
+			Expect(line["code"]).To(Equal("[REDACTED]"))
+			Expect(line["password"]).To(Equal("[REDACTED]"))
+			Expect(line["email"]).To(Equal("test@test.com"))
+			Expect(entry.Data["code"]).To(Equal("123456"))
+		})
+	})
+})
@@ -0,0 +1,494 @@
+package metrics
+
+import (
+	"time"
+	"url-shortener/internal/database"
+)
+
+// instrumentedDatabase decorates MySQLService by observing latency and errors of each method.
+type instrumentedDatabase struct {
+	next database.MySQLService
+}
+
+// InstrumentDatabase returns MySQLService reporting query metrics for given service.
+func InstrumentDatabase(db database.MySQLService) database.MySQLService {
+	return &instrumentedDatabase{next: db}
+}
+
+func observe(method string, start time.Time, err error) {
+	DatabaseQueryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	switch err.(type) {
+	case nil, database.RecordNotFoundError, database.ShortenURLTakenError:
+	default:
+		DatabaseQueryErrors.WithLabelValues(method).Inc()
+	}
+}
+
+func (i *instrumentedDatabase) CreateUser(user database.User) (err error) {
+	defer func(start time.Time) { observe("CreateUser", start, err) }(time.Now())
+	return i.next.CreateUser(user)
+}
+
+func (i *instrumentedDatabase) CreateGoogleUser(user database.User, gUser database.GoogleUser) (err error) {
+	defer func(start time.Time) { observe("CreateGoogleUser", start, err) }(time.Now())
+	return i.next.CreateGoogleUser(user, gUser)
+}
+
+func (i *instrumentedDatabase) GetUserWithEmail(email string) (_ *database.User, err error) {
+	defer func(start time.Time) { observe("GetUserWithEmail", start, err) }(time.Now())
+	return i.next.GetUserWithEmail(email)
+}
+
+func (i *instrumentedDatabase) GetUserWithID(userId string) (_ *database.User, err error) {
+	defer func(start time.Time) { observe("GetUserWithID", start, err) }(time.Now())
+	return i.next.GetUserWithID(userId)
+}
+
+func (i *instrumentedDatabase) GetURLIfExistsInWorkspace(workspaceID string, domain string, oriURL string) (_ *database.URL, err error) {
+	defer func(start time.Time) { observe("GetURLIfExistsInWorkspace", start, err) }(time.Now())
+	return i.next.GetURLIfExistsInWorkspace(workspaceID, domain, oriURL)
+}
+
+func (i *instrumentedDatabase) CreateURL(oriURL string, shortenURL string, domain string, workspaceID string, creator database.User) (err error) {
+	defer func(start time.Time) { observe("CreateURL", start, err) }(time.Now())
+	return i.next.CreateURL(oriURL, shortenURL, domain, workspaceID, creator)
+}
+
+func (i *instrumentedDatabase) GetURLWithShortenURL(shortenURL string) (_ *database.URL, err error) {
+	defer func(start time.Time) { observe("GetURLWithShortenURL", start, err) }(time.Now())
+	return i.next.GetURLWithShortenURL(shortenURL)
+}
+
+func (i *instrumentedDatabase) GetURLsWithShortenURLs(shortenURLs []string) (_ []database.URL, err error) {
+	defer func(start time.Time) { observe("GetURLsWithShortenURLs", start, err) }(time.Now())
+	return i.next.GetURLsWithShortenURLs(shortenURLs)
+}
+
+func (i *instrumentedDatabase) GetDeletedURL(shortenURL string) (_ *database.URL, err error) {
+	defer func(start time.Time) { observe("GetDeletedURL", start, err) }(time.Now())
+	return i.next.GetDeletedURL(shortenURL)
+}
+
+func (i *instrumentedDatabase) GetDeletedURLsInWorkspace(workspaceID string) (_ []database.URL, err error) {
+	defer func(start time.Time) { observe("GetDeletedURLsInWorkspace", start, err) }(time.Now())
+	return i.next.GetDeletedURLsInWorkspace(workspaceID)
+}
+
+func (i *instrumentedDatabase) UpdateURL(url *database.URL) (err error) {
+	defer func(start time.Time) { observe("UpdateURL", start, err) }(time.Now())
+	return i.next.UpdateURL(url)
+}
+
+func (i *instrumentedDatabase) IncreaseURLCount(shortenURL string) (err error) {
+	defer func(start time.Time) { observe("IncreaseURLCount", start, err) }(time.Now())
+	return i.next.IncreaseURLCount(shortenURL)
+}
+
+func (i *instrumentedDatabase) UpdateURLDetails(shortenURL string, details database.URLDetails) (err error) {
+	defer func(start time.Time) { observe("UpdateURLDetails", start, err) }(time.Now())
+	return i.next.UpdateURLDetails(shortenURL, details)
+}
+
+func (i *instrumentedDatabase) GetURLsInWorkspace(workspaceID string, filter database.URLFilter, offset uint64, limit uint64) (_ uint64, _ []database.URL, err error) {
+	defer func(start time.Time) { observe("GetURLsInWorkspace", start, err) }(time.Now())
+	return i.next.GetURLsInWorkspace(workspaceID, filter, offset, limit)
+}
+
+func (i *instrumentedDatabase) ListURLsInWorkspace(workspaceID string, query database.URLListQuery) (_ uint64, _ []database.URL, _ *database.URLCursor, err error) {
+	defer func(start time.Time) { observe("ListURLsInWorkspace", start, err) }(time.Now())
+	return i.next.ListURLsInWorkspace(workspaceID, query)
+}
+
+func (i *instrumentedDatabase) GetTagStatsInWorkspace(workspaceID string) (_ []database.TagStats, err error) {
+	defer func(start time.Time) { observe("GetTagStatsInWorkspace", start, err) }(time.Now())
+	return i.next.GetTagStatsInWorkspace(workspaceID)
+}
+
+func (i *instrumentedDatabase) UpdateURLMetadata(shortenURL string, metadata database.URLMetadata) (err error) {
+	defer func(start time.Time) { observe("UpdateURLMetadata", start, err) }(time.Now())
+	return i.next.UpdateURLMetadata(shortenURL, metadata)
+}
+
+func (i *instrumentedDatabase) GetURLsForMetadataRefresh(fetchedBefore time.Time, limit uint64) (_ []database.URL, err error) {
+	defer func(start time.Time) { observe("GetURLsForMetadataRefresh", start, err) }(time.Now())
+	return i.next.GetURLsForMetadataRefresh(fetchedBefore, limit)
+}
+
+func (i *instrumentedDatabase) GetURLsAfter(shortenURL string, limit uint64) (_ []database.URL, err error) {
+	defer func(start time.Time) { observe("GetURLsAfter", start, err) }(time.Now())
+	return i.next.GetURLsAfter(shortenURL, limit)
+}
+
+func (i *instrumentedDatabase) GetURLsInWorkspaceAfter(workspaceID string, shortenURL string, limit uint64) (_ []database.URL, err error) {
+	defer func(start time.Time) { observe("GetURLsInWorkspaceAfter", start, err) }(time.Now())
+	return i.next.GetURLsInWorkspaceAfter(workspaceID, shortenURL, limit)
+}
+
+func (i *instrumentedDatabase) ImportURL(url database.URL, creator database.User) (err error) {
+	defer func(start time.Time) { observe("ImportURL", start, err) }(time.Now())
+	return i.next.ImportURL(url, creator)
+}
+
+func (i *instrumentedDatabase) GetGoogleUserWithUserID(userID string) (_ *database.GoogleUser, err error) {
+	defer func(start time.Time) { observe("GetGoogleUserWithUserID", start, err) }(time.Now())
+	return i.next.GetGoogleUserWithUserID(userID)
+}
+
+func (i *instrumentedDatabase) GetURLsOfUserAfter(userID string, shortenURL string, limit uint64) (_ []database.URL, err error) {
+	defer func(start time.Time) { observe("GetURLsOfUserAfter", start, err) }(time.Now())
+	return i.next.GetURLsOfUserAfter(userID, shortenURL, limit)
+}
+
+func (i *instrumentedDatabase) CreateDataExport(export database.DataExport) (err error) {
+	defer func(start time.Time) { observe("CreateDataExport", start, err) }(time.Now())
+	return i.next.CreateDataExport(export)
+}
+
+func (i *instrumentedDatabase) GetDataExport(id string) (_ *database.DataExport, err error) {
+	defer func(start time.Time) { observe("GetDataExport", start, err) }(time.Now())
+	return i.next.GetDataExport(id)
+}
+
+func (i *instrumentedDatabase) GetDataExportsWithUser(userID string) (_ []database.DataExport, err error) {
+	defer func(start time.Time) { observe("GetDataExportsWithUser", start, err) }(time.Now())
+	return i.next.GetDataExportsWithUser(userID)
+}
+
+func (i *instrumentedDatabase) GetPendingDataExports(limit uint64) (_ []database.DataExport, err error) {
+	defer func(start time.Time) { observe("GetPendingDataExports", start, err) }(time.Now())
+	return i.next.GetPendingDataExports(limit)
+}
+
+func (i *instrumentedDatabase) GetDataExportArchive(id string) (_ []byte, err error) {
+	defer func(start time.Time) { observe("GetDataExportArchive", start, err) }(time.Now())
+	return i.next.GetDataExportArchive(id)
+}
+
+func (i *instrumentedDatabase) CompleteDataExport(id string, archive []byte, completedAt time.Time, expiresAt time.Time) (err error) {
+	defer func(start time.Time) { observe("CompleteDataExport", start, err) }(time.Now())
+	return i.next.CompleteDataExport(id, archive, completedAt, expiresAt)
+}
+
+func (i *instrumentedDatabase) FailDataExport(id string, failedAt time.Time) (err error) {
+	defer func(start time.Time) { observe("FailDataExport", start, err) }(time.Now())
+	return i.next.FailDataExport(id, failedAt)
+}
+
+func (i *instrumentedDatabase) DeleteDataExportsBefore(createdBefore time.Time) (err error) {
+	defer func(start time.Time) { observe("DeleteDataExportsBefore", start, err) }(time.Now())
+	return i.next.DeleteDataExportsBefore(createdBefore)
+}
+
+func (i *instrumentedDatabase) CreateURLCheck(check database.URLCheck) (err error) {
+	defer func(start time.Time) { observe("CreateURLCheck", start, err) }(time.Now())
+	return i.next.CreateURLCheck(check)
+}
+
+func (i *instrumentedDatabase) GetURLChecks(shortenURL string, limit uint64) (_ []database.URLCheck, err error) {
+	defer func(start time.Time) { observe("GetURLChecks", start, err) }(time.Now())
+	return i.next.GetURLChecks(shortenURL, limit)
+}
+
+func (i *instrumentedDatabase) DeleteURLChecksBefore(checkedBefore time.Time) (err error) {
+	defer func(start time.Time) { observe("DeleteURLChecksBefore", start, err) }(time.Now())
+	return i.next.DeleteURLChecksBefore(checkedBefore)
+}
+
+func (i *instrumentedDatabase) GetBrokenURLsToAlert(brokenBefore time.Time) (_ []database.URL, err error) {
+	defer func(start time.Time) { observe("GetBrokenURLsToAlert", start, err) }(time.Now())
+	return i.next.GetBrokenURLsToAlert(brokenBefore)
+}
+
+func (i *instrumentedDatabase) MarkURLsAlerted(shortenURLs []string, alertedAt time.Time) (err error) {
+	defer func(start time.Time) { observe("MarkURLsAlerted", start, err) }(time.Now())
+	return i.next.MarkURLsAlerted(shortenURLs, alertedAt)
+}
+
+func (i *instrumentedDatabase) SetDeadLinkAlertsDisabled(user database.User, disabled bool) (err error) {
+	defer func(start time.Time) { observe("SetDeadLinkAlertsDisabled", start, err) }(time.Now())
+	return i.next.SetDeadLinkAlertsDisabled(user, disabled)
+}
+
+func (i *instrumentedDatabase) CreateWorkspace(workspace database.Workspace, owner database.User) (err error) {
+	defer func(start time.Time) { observe("CreateWorkspace", start, err) }(time.Now())
+	return i.next.CreateWorkspace(workspace, owner)
+}
+
+func (i *instrumentedDatabase) GetMembershipsWithUser(user database.User) (_ []database.Membership, err error) {
+	defer func(start time.Time) { observe("GetMembershipsWithUser", start, err) }(time.Now())
+	return i.next.GetMembershipsWithUser(user)
+}
+
+func (i *instrumentedDatabase) GetWorkspaceRole(workspaceID string, userID string) (_ string, err error) {
+	defer func(start time.Time) { observe("GetWorkspaceRole", start, err) }(time.Now())
+	return i.next.GetWorkspaceRole(workspaceID, userID)
+}
+
+func (i *instrumentedDatabase) GetWorkspaceMembers(workspaceID string) (_ []database.WorkspaceMember, err error) {
+	defer func(start time.Time) { observe("GetWorkspaceMembers", start, err) }(time.Now())
+	return i.next.GetWorkspaceMembers(workspaceID)
+}
+
+func (i *instrumentedDatabase) SetWorkspaceMemberRole(workspaceID string, userID string, role string) (err error) {
+	defer func(start time.Time) { observe("SetWorkspaceMemberRole", start, err) }(time.Now())
+	return i.next.SetWorkspaceMemberRole(workspaceID, userID, role)
+}
+
+func (i *instrumentedDatabase) DeleteWorkspaceMember(workspaceID string, userID string) (err error) {
+	defer func(start time.Time) { observe("DeleteWorkspaceMember", start, err) }(time.Now())
+	return i.next.DeleteWorkspaceMember(workspaceID, userID)
+}
+
+func (i *instrumentedDatabase) CreateWorkspaceInvitation(invitation database.WorkspaceInvitation) (err error) {
+	defer func(start time.Time) { observe("CreateWorkspaceInvitation", start, err) }(time.Now())
+	return i.next.CreateWorkspaceInvitation(invitation)
+}
+
+func (i *instrumentedDatabase) AcceptWorkspaceInvitation(tokenHash string, user database.User) (_ *database.WorkspaceInvitation, err error) {
+	defer func(start time.Time) { observe("AcceptWorkspaceInvitation", start, err) }(time.Now())
+	return i.next.AcceptWorkspaceInvitation(tokenHash, user)
+}
+
+func (i *instrumentedDatabase) CreateDomain(domain database.Domain) (err error) {
+	defer func(start time.Time) { observe("CreateDomain", start, err) }(time.Now())
+	return i.next.CreateDomain(domain)
+}
+
+func (i *instrumentedDatabase) GetDomain(hostname string) (_ *database.Domain, err error) {
+	defer func(start time.Time) { observe("GetDomain", start, err) }(time.Now())
+	return i.next.GetDomain(hostname)
+}
+
+func (i *instrumentedDatabase) GetWorkspaceDomain(workspaceID string, hostname string) (_ *database.Domain, err error) {
+	defer func(start time.Time) { observe("GetWorkspaceDomain", start, err) }(time.Now())
+	return i.next.GetWorkspaceDomain(workspaceID, hostname)
+}
+
+func (i *instrumentedDatabase) GetDomainsInWorkspace(workspaceID string) (_ []database.Domain, err error) {
+	defer func(start time.Time) { observe("GetDomainsInWorkspace", start, err) }(time.Now())
+	return i.next.GetDomainsInWorkspace(workspaceID)
+}
+
+func (i *instrumentedDatabase) MarkDomainVerified(workspaceID string, hostname string, verifiedAt time.Time) (err error) {
+	defer func(start time.Time) { observe("MarkDomainVerified", start, err) }(time.Now())
+	return i.next.MarkDomainVerified(workspaceID, hostname, verifiedAt)
+}
+
+func (i *instrumentedDatabase) CountURLsOnDomain(hostname string) (_ uint64, err error) {
+	defer func(start time.Time) { observe("CountURLsOnDomain", start, err) }(time.Now())
+	return i.next.CountURLsOnDomain(hostname)
+}
+
+func (i *instrumentedDatabase) DeleteDomain(workspaceID string, hostname string) (err error) {
+	defer func(start time.Time) { observe("DeleteDomain", start, err) }(time.Now())
+	return i.next.DeleteDomain(workspaceID, hostname)
+}
+
+func (i *instrumentedDatabase) SetURLRules(shortenURL string, rules []database.RedirectRule) (err error) {
+	defer func(start time.Time) { observe("SetURLRules", start, err) }(time.Now())
+	return i.next.SetURLRules(shortenURL, rules)
+}
+
+func (i *instrumentedDatabase) SetURLVariants(shortenURL string, variants []database.URLVariant) (err error) {
+	defer func(start time.Time) { observe("SetURLVariants", start, err) }(time.Now())
+	return i.next.SetURLVariants(shortenURL, variants)
+}
+
+func (i *instrumentedDatabase) SetURLSchedule(shortenURL string, schedule *database.Schedule) (err error) {
+	defer func(start time.Time) { observe("SetURLSchedule", start, err) }(time.Now())
+	return i.next.SetURLSchedule(shortenURL, schedule)
+}
+
+func (i *instrumentedDatabase) IncreaseURLVariantCount(id uint64) (err error) {
+	defer func(start time.Time) { observe("IncreaseURLVariantCount", start, err) }(time.Now())
+	return i.next.IncreaseURLVariantCount(id)
+}
+
+func (i *instrumentedDatabase) CreatePage(page database.Page) (err error) {
+	defer func(start time.Time) { observe("CreatePage", start, err) }(time.Now())
+	return i.next.CreatePage(page)
+}
+
+func (i *instrumentedDatabase) GetPage(slug string) (_ *database.Page, err error) {
+	defer func(start time.Time) { observe("GetPage", start, err) }(time.Now())
+	return i.next.GetPage(slug)
+}
+
+func (i *instrumentedDatabase) GetPagesInWorkspace(workspaceID string) (_ []database.Page, err error) {
+	defer func(start time.Time) { observe("GetPagesInWorkspace", start, err) }(time.Now())
+	return i.next.GetPagesInWorkspace(workspaceID)
+}
+
+func (i *instrumentedDatabase) UpdatePage(page database.Page) (err error) {
+	defer func(start time.Time) { observe("UpdatePage", start, err) }(time.Now())
+	return i.next.UpdatePage(page)
+}
+
+func (i *instrumentedDatabase) DeletePage(slug string) (err error) {
+	defer func(start time.Time) { observe("DeletePage", start, err) }(time.Now())
+	return i.next.DeletePage(slug)
+}
+
+func (i *instrumentedDatabase) CreateAuditEntry(entry database.AuditEntry) (err error) {
+	defer func(start time.Time) { observe("CreateAuditEntry", start, err) }(time.Now())
+	return i.next.CreateAuditEntry(entry)
+}
+
+func (i *instrumentedDatabase) GetAuditEntries(query database.AuditQuery) (_ []database.AuditEntry, err error) {
+	defer func(start time.Time) { observe("GetAuditEntries", start, err) }(time.Now())
+	return i.next.GetAuditEntries(query)
+}
+
+func (i *instrumentedDatabase) CreateWebhook(webhook database.Webhook) (err error) {
+	defer func(start time.Time) { observe("CreateWebhook", start, err) }(time.Now())
+	return i.next.CreateWebhook(webhook)
+}
+
+func (i *instrumentedDatabase) GetWebhook(id string) (_ *database.Webhook, err error) {
+	defer func(start time.Time) { observe("GetWebhook", start, err) }(time.Now())
+	return i.next.GetWebhook(id)
+}
+
+func (i *instrumentedDatabase) GetWebhooksInWorkspace(workspaceID string) (_ []database.Webhook, err error) {
+	defer func(start time.Time) { observe("GetWebhooksInWorkspace", start, err) }(time.Now())
+	return i.next.GetWebhooksInWorkspace(workspaceID)
+}
+
+func (i *instrumentedDatabase) GetWebhooksWithEvent(event string) (_ []database.Webhook, err error) {
+	defer func(start time.Time) { observe("GetWebhooksWithEvent", start, err) }(time.Now())
+	return i.next.GetWebhooksWithEvent(event)
+}
+
+func (i *instrumentedDatabase) UpdateWebhook(webhook database.Webhook) (err error) {
+	defer func(start time.Time) { observe("UpdateWebhook", start, err) }(time.Now())
+	return i.next.UpdateWebhook(webhook)
+}
+
+func (i *instrumentedDatabase) DeleteWebhook(id string) (err error) {
+	defer func(start time.Time) { observe("DeleteWebhook", start, err) }(time.Now())
+	return i.next.DeleteWebhook(id)
+}
+
+func (i *instrumentedDatabase) EnqueueWebhookDeliveries(workspaceID string, event string, payload string) (_ uint64, err error) {
+	defer func(start time.Time) { observe("EnqueueWebhookDeliveries", start, err) }(time.Now())
+	return i.next.EnqueueWebhookDeliveries(workspaceID, event, payload)
+}
+
+func (i *instrumentedDatabase) GetURLsReachingClicks(webhook database.Webhook, clicks uint64, limit uint64) (_ []database.URL, err error) {
+	defer func(start time.Time) { observe("GetURLsReachingClicks", start, err) }(time.Now())
+	return i.next.GetURLsReachingClicks(webhook, clicks, limit)
+}
+
+func (i *instrumentedDatabase) EnqueueWebhookClicksDelivery(delivery database.WebhookDelivery, shortenURL string, clicks uint64) (err error) {
+	defer func(start time.Time) { observe("EnqueueWebhookClicksDelivery", start, err) }(time.Now())
+	return i.next.EnqueueWebhookClicksDelivery(delivery, shortenURL, clicks)
+}
+
+func (i *instrumentedDatabase) GetDueWebhookDeliveries(now time.Time, limit uint64) (_ []database.WebhookDelivery, err error) {
+	defer func(start time.Time) { observe("GetDueWebhookDeliveries", start, err) }(time.Now())
+	return i.next.GetDueWebhookDeliveries(now, limit)
+}
+
+func (i *instrumentedDatabase) RecordWebhookDelivery(delivery database.WebhookDelivery, disableAfter int, at time.Time) (_ bool, err error) {
+	defer func(start time.Time) { observe("RecordWebhookDelivery", start, err) }(time.Now())
+	return i.next.RecordWebhookDelivery(delivery, disableAfter, at)
+}
+
+func (i *instrumentedDatabase) GetWebhookDeliveries(webhookID string, offset uint64, limit uint64) (_ uint64, _ []database.WebhookDelivery, err error) {
+	defer func(start time.Time) { observe("GetWebhookDeliveries", start, err) }(time.Now())
+	return i.next.GetWebhookDeliveries(webhookID, offset, limit)
+}
+
+func (i *instrumentedDatabase) DeleteWebhookDeliveriesBefore(createdBefore time.Time) (err error) {
+	defer func(start time.Time) { observe("DeleteWebhookDeliveriesBefore", start, err) }(time.Now())
+	return i.next.DeleteWebhookDeliveriesBefore(createdBefore)
+}
+
+func (i *instrumentedDatabase) CreateReport(report database.Report) (err error) {
+	defer func(start time.Time) { observe("CreateReport", start, err) }(time.Now())
+	return i.next.CreateReport(report)
+}
+
+func (i *instrumentedDatabase) CountReporters(shortenURL string) (_ uint64, err error) {
+	defer func(start time.Time) { observe("CountReporters", start, err) }(time.Now())
+	return i.next.CountReporters(shortenURL)
+}
+
+func (i *instrumentedDatabase) SuspendURL(shortenURL string, suspendedAt time.Time) (err error) {
+	defer func(start time.Time) { observe("SuspendURL", start, err) }(time.Now())
+	return i.next.SuspendURL(shortenURL, suspendedAt)
+}
+
+func (i *instrumentedDatabase) GetReports(status string, offset uint64, limit uint64) (_ uint64, _ []database.Report, err error) {
+	defer func(start time.Time) { observe("GetReports", start, err) }(time.Now())
+	return i.next.GetReports(status, offset, limit)
+}
+
+func (i *instrumentedDatabase) ResolveReports(shortenURL string, status string, reviewer database.User, reviewedAt time.Time) (err error) {
+	defer func(start time.Time) { observe("ResolveReports", start, err) }(time.Now())
+	return i.next.ResolveReports(shortenURL, status, reviewer, reviewedAt)
+}
+
+func (i *instrumentedDatabase) DeleteURL(shortenURL string) (err error) {
+	defer func(start time.Time) { observe("DeleteURL", start, err) }(time.Now())
+	return i.next.DeleteURL(shortenURL)
+}
+
+func (i *instrumentedDatabase) RestoreURL(shortenURL string) (err error) {
+	defer func(start time.Time) { observe("RestoreURL", start, err) }(time.Now())
+	return i.next.RestoreURL(shortenURL)
+}
+
+func (i *instrumentedDatabase) PurgeURLsDeletedBefore(deletedBefore time.Time, limit uint64) (_ uint64, err error) {
+	defer func(start time.Time) { observe("PurgeURLsDeletedBefore", start, err) }(time.Now())
+	return i.next.PurgeURLsDeletedBefore(deletedBefore, limit)
+}
+
+func (i *instrumentedDatabase) DeleteUser(user database.User) (err error) {
+	defer func(start time.Time) { observe("DeleteUser", start, err) }(time.Now())
+	return i.next.DeleteUser(user)
+}
+
+func (i *instrumentedDatabase) GetUsers(query database.UserQuery) (_ uint64, _ []database.User, err error) {
+	defer func(start time.Time) { observe("GetUsers", start, err) }(time.Now())
+	return i.next.GetUsers(query)
+}
+
+func (i *instrumentedDatabase) SetUserRole(userID string, role string) (err error) {
+	defer func(start time.Time) { observe("SetUserRole", start, err) }(time.Now())
+	return i.next.SetUserRole(userID, role)
+}
+
+func (i *instrumentedDatabase) SetUserDisabled(userID string, disabled bool) (err error) {
+	defer func(start time.Time) { observe("SetUserDisabled", start, err) }(time.Now())
+	return i.next.SetUserDisabled(userID, disabled)
+}
+
+func (i *instrumentedDatabase) TransferURLs(fromWorkspaceID string, toWorkspaceID string) (_ uint64, err error) {
+	defer func(start time.Time) { observe("TransferURLs", start, err) }(time.Now())
+	return i.next.TransferURLs(fromWorkspaceID, toWorkspaceID)
+}
+
+func (i *instrumentedDatabase) PurgeURL(shortenURL string) (err error) {
+	defer func(start time.Time) { observe("PurgeURL", start, err) }(time.Now())
+	return i.next.PurgeURL(shortenURL)
+}
+
+func (i *instrumentedDatabase) GetSystemStats() (_ database.SystemStats, err error) {
+	defer func(start time.Time) { observe("GetSystemStats", start, err) }(time.Now())
+	return i.next.GetSystemStats()
+}
+
+func (i *instrumentedDatabase) CountURLs() (_ uint64, err error) {
+	defer func(start time.Time) { observe("CountURLs", start, err) }(time.Now())
+	return i.next.CountURLs()
+}
+
+func (i *instrumentedDatabase) CountUsers() (_ uint64, err error) {
+	defer func(start time.Time) { observe("CountUsers", start, err) }(time.Now())
+	return i.next.CountUsers()
+}
+
+func (i *instrumentedDatabase) Close() error {
+	return i.next.Close()
+}
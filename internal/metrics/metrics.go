@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var namespace = "url_shortener"
+
+var (
+	// HTTPRequests counts handled requests per gin route, method and status
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests handled, partitioned by route, method and status.",
+	}, []string{"route", "method", "status"})
+
+	// HTTPRequestDuration observes request latency per gin route, method and status
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests, partitioned by route, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	// RedirectCacheLookups counts cache lookups made when resolving a shorten url (result: hit, miss)
+	RedirectCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "redirect",
+		Name:      "cache_lookups_total",
+		Help:      "Number of cache lookups when resolving shorten urls, partitioned by result.",
+	}, []string{"result"})
+
+	// DatabaseQueryDuration observes latency per MySQLService method
+	DatabaseQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "database",
+		Name:      "query_duration_seconds",
+		Help:      "Latency of database queries, partitioned by MySQLService method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	// DatabaseQueryErrors counts failed calls per MySQLService method
+	DatabaseQueryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "database",
+		Name:      "query_errors_total",
+		Help:      "Number of failed database queries, partitioned by MySQLService method.",
+	}, []string{"method"})
+
+	// EmailsSent counts email delivery outcomes (result: success, failure, timeout)
+	EmailsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "email",
+		Name:      "sent_total",
+		Help:      "Number of emails processed by the email service, partitioned by result.",
+	}, []string{"result"})
+
+	// ActiveLinks reports the number of shorten urls stored
+	ActiveLinks = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_links",
+		Help:      "Number of shorten urls currently stored.",
+	})
+
+	// RegisteredUsers reports the number of registered users
+	RegisteredUsers = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "registered_users",
+		Help:      "Number of registered users.",
+	})
+)
+
+var (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+
+	EmailSuccess = "success"
+	EmailFailure = "failure"
+	EmailTimeout = "timeout"
+)
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"time"
+	"url-shortener/internal/database"
+)
+
+// StartStatsCollector periodically refreshes gauges reflecting stored links and users until ctx is done.
+func StartStatsCollector(ctx context.Context, db database.MySQLService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		collectStats(db)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func collectStats(db database.MySQLService) {
+	links, err := db.CountURLs()
+	if err != nil {
+		logrus.WithError(err).Warn("Unable to count urls for metrics")
+	} else {
+		ActiveLinks.Set(float64(links))
+	}
+
+	users, err := db.CountUsers()
+	if err != nil {
+		logrus.WithError(err).Warn("Unable to count users for metrics")
+	} else {
+		RegisteredUsers.Set(float64(users))
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	server "url-shortener/internal/route/error"
+)
+
+// AdminAuthorized lets through users authenticated by UserAuthenticated having the admin role.
+func AdminAuthorized() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		logger := logging.FromContext(context)
+
+		user := context.Value("user").(*database.User)
+		if user.Role != database.UserRoleAdmin {
+			logger.WithField("user_id", user.UserID).Info("User is not an admin")
+			server.Abort(context, server.ForbiddenError)
+			return
+		}
+
+		context.Next()
+	}
+}
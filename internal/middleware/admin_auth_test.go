@@ -0,0 +1,37 @@
+package middleware_test
+
+import (
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"url-shortener/internal/database"
+	"url-shortener/internal/middleware"
+)
+
+var _ = Describe("AdminAuthorized", func() {
+	serve := func(role string) int {
+		gin.SetMode(gin.TestMode)
+
+		router := gin.New()
+		router.GET("/api/v1/admin/audit", func(context *gin.Context) {
+			context.Set("user", &database.User{UserID: "u1", Role: role})
+		}, middleware.AdminAuthorized(), func(context *gin.Context) {
+			context.Status(http.StatusOK)
+		})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v1/admin/audit", nil))
+		return recorder.Code
+	}
+
+	It("should let admins through", func() {
+		Expect(serve(database.UserRoleAdmin)).To(Equal(http.StatusOK))
+	})
+
+	It("should forbid everyone else", func() {
+		Expect(serve(database.UserRoleUser)).To(Equal(http.StatusForbidden))
+		Expect(serve("")).To(Equal(http.StatusForbidden))
+	})
+})
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientAddress resolves the address of the client and exposes it to subsequent handlers as "client-ip".
+// X-Forwarded-For is only believed when the request comes from one of trustedProxies, anyone can send it otherwise.
+func ClientAddress(trustedProxies []*net.IPNet) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		context.Set("client-ip", ClientIP(context.Request, trustedProxies))
+		context.Next()
+	}
+}
+
+// ClientIP returns the peer address of request, or, if the peer is a trusted proxy, the nearest address
+// of X-Forwarded-For not belonging to trustedProxies.
+func ClientIP(request *http.Request, trustedProxies []*net.IPNet) string {
+	ip, _, err := net.SplitHostPort(strings.TrimSpace(request.RemoteAddr))
+	if err != nil {
+		ip = strings.TrimSpace(request.RemoteAddr)
+	}
+	if !isTrusted(net.ParseIP(ip), trustedProxies) {
+		return ip
+	}
+
+	// note: proxies append the address they received from, so the list is walked from the nearest hop
+	forwarded := strings.Split(strings.Join(request.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if hop == nil {
+			break
+		}
+		ip = hop.String()
+		if !isTrusted(hop, trustedProxies) {
+			break
+		}
+	}
+	return ip
+}
+
+func isTrusted(ip net.IP, trustedProxies []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
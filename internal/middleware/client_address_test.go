@@ -0,0 +1,38 @@
+package middleware_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"net"
+	"net/http/httptest"
+	"url-shortener/internal/middleware"
+)
+
+var _ = Describe("ClientIP", func() {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	trusted := []*net.IPNet{proxies}
+
+	request := func(remoteAddr string, forwardedFor ...string) string {
+		req := httptest.NewRequest("POST", "/api/v1/report/abc", nil)
+		req.RemoteAddr = remoteAddr
+		for _, value := range forwardedFor {
+			req.Header.Add("X-Forwarded-For", value)
+		}
+		return middleware.ClientIP(req, trusted)
+	}
+
+	It("should ignore X-Forwarded-For sent by untrusted peers", func() {
+		Expect(request("203.0.113.7:4321", "198.51.100.1")).To(Equal("203.0.113.7"))
+		Expect(middleware.ClientIP(httptest.NewRequest("GET", "/", nil), nil)).To(Equal("192.0.2.1"))
+	})
+
+	It("should take the nearest untrusted hop behind trusted proxies", func() {
+		Expect(request("10.0.0.2:4321", "198.51.100.1, 203.0.113.7")).To(Equal("203.0.113.7"))
+		Expect(request("10.0.0.2:4321", "198.51.100.1", "203.0.113.7, 10.0.0.3")).To(Equal("203.0.113.7"))
+	})
+
+	It("should not go past values a proxy would not have written", func() {
+		Expect(request("10.0.0.2:4321", "198.51.100.1, garbage")).To(Equal("10.0.0.2"))
+		Expect(request("10.0.0.2:4321")).To(Equal("10.0.0.2"))
+	})
+})
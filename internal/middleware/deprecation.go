@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// Deprecated marks responses of routes as deprecated, announcing their sunset and successor.
+func Deprecated(sunset time.Time, successor string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		context.Header("Deprecation", "true")
+		if !sunset.IsZero() {
+			context.Header("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+		context.Header("Link", fmt.Sprintf("<%v>; rel=\"successor-version\"", successor))
+		context.Next()
+	}
+}
@@ -0,0 +1,31 @@
+package middleware_test
+
+import (
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"time"
+	"url-shortener/internal/middleware"
+)
+
+var _ = Describe("Deprecated", func() {
+	It("should announce deprecation, sunset and successor", func() {
+		gin.SetMode(gin.TestMode)
+		sunset := time.Date(2027, 4, 30, 0, 0, 0, 0, time.UTC)
+
+		router := gin.New()
+		router.GET("/api/user/authCheck", middleware.Deprecated(sunset, "/api/v1"), func(context *gin.Context) {
+			context.Status(http.StatusOK)
+		})
+
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/user/authCheck", nil)
+		router.ServeHTTP(recorder, req)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Deprecation")).To(Equal("true"))
+		Expect(recorder.Header().Get("Sunset")).To(Equal("Fri, 30 Apr 2027 00:00:00 GMT"))
+		Expect(recorder.Header().Get("Link")).To(Equal(`</api/v1>; rel="successor-version"`))
+	})
+})
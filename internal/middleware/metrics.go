@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"time"
+	"url-shortener/internal/metrics"
+)
+
+// RouteKey names the route served by a handler of unmatched paths, e.g. the root redirect. Requests without
+// a route keep a single label so that cardinality stays bounded.
+const RouteKey = "metrics-route"
+
+// RequestMetrics records request count and latency per gin route, method and status.
+func RequestMetrics() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		start := time.Now()
+
+		context.Next()
+
+		route := context.FullPath()
+		if route == "" {
+			route = context.GetString(RouteKey)
+		}
+		if route == "" {
+			route = "unmatched" // keep label cardinality bounded for unknown paths
+		}
+		method := context.Request.Method
+		status := strconv.Itoa(context.Writer.Status())
+
+		metrics.HTTPRequests.WithLabelValues(route, method, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(route, method, status).Observe(time.Since(start).Seconds())
+	}
+}
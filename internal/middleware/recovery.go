@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"runtime/debug"
+	"url-shortener/internal/logging"
+	server "url-shortener/internal/route/error"
+)
+
+// Recovery converts panics raised by handlers into the internal error envelope.
+func Recovery() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				logging.FromContext(context).
+					WithField("panic", r).
+					WithField("stack", string(debug.Stack())).
+					Error("Recovered from panic")
+
+				if context.Writer.Written() {
+					context.Abort()
+					return
+				}
+				server.Abort(context, server.InternalError)
+			}
+		}()
+
+		context.Next()
+	}
+}
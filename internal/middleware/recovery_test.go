@@ -0,0 +1,63 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"url-shortener/internal/middleware"
+)
+
+var _ = Describe("Recovery", func() {
+	var (
+		router *gin.Engine
+		jwtKey []byte
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		jwtKey = []byte("testKey")
+
+		router = gin.New()
+		router.Use(middleware.Recovery())
+		router.GET("/panic", func(context *gin.Context) {
+			panic("unexpected")
+		})
+		router.GET("/auth", middleware.UserAuthenticated(jwtKey), func(context *gin.Context) {
+			context.Status(http.StatusOK)
+		})
+	})
+
+	It("should convert panics into error envelope", func() {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/panic", nil)
+		router.ServeHTTP(recorder, req)
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+
+		var response map[string]map[string]interface{}
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response["error"]["code"]).To(Equal("internal_error"))
+	})
+
+	It("should reject access token without issued claim instead of panicking", func() {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"email": "test@test.com",
+		}).SignedString(jwtKey)
+		Expect(err).NotTo(HaveOccurred())
+
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/auth", nil)
+		req.Header.Set("Cookie", "accessToken="+token)
+		router.ServeHTTP(recorder, req)
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+
+		var response map[string]map[string]interface{}
+		err = json.Unmarshal(recorder.Body.Bytes(), &response)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response["error"]["code"]).To(Equal("authentication_failed"))
+	})
+})
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"regexp"
+	"time"
+	"url-shortener/internal/util"
+)
+
+var (
+	RequestIDHeader = "X-Request-ID"
+
+	validRequestID = regexp.MustCompile("^[A-Za-z0-9._-]{1,64}$")
+)
+
+// RequestLogger assigns a request id to every request, echoes it on the response header,
+// and exposes a logger carrying the id to subsequent handlers.
+func RequestLogger(logger *logrus.Logger) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		start := time.Now()
+
+		requestID := context.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			id, err := util.NewUUID()
+			if err != nil {
+				logger.WithError(err).Warn("Unable to generate request id")
+			}
+			requestID = id
+		}
+		context.Header(RequestIDHeader, requestID)
+
+		entry := logger.WithField("request_id", requestID)
+		context.Set("request-id", requestID)
+		context.Set("logger", entry)
+
+		context.Next()
+
+		// note: query string is left out as it might carry oauth codes or states
+		entry.WithFields(logrus.Fields{
+			"method":     context.Request.Method,
+			"path":       context.Request.URL.Path,
+			"route":      context.FullPath(),
+			"status":     context.Writer.Status(),
+			"latency_ms": time.Since(start).Milliseconds(),
+			"client_ip":  context.GetString("client-ip"),
+		}).Info("Request handled")
+	}
+}
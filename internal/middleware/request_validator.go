@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/openapi"
+	server "url-shortener/internal/route/error"
+)
+
+// RequestValidator rejects requests whose parameters or JSON body don't conform to the operation described by doc.
+// Routes without an operation in doc are passed through.
+func RequestValidator(doc *openapi.Document) gin.HandlerFunc {
+	paths := doc.ResolvedPaths()
+
+	return func(context *gin.Context) {
+		item, ok := paths[openapi.PathFromRoute(context.FullPath())]
+		if !ok {
+			context.Next()
+			return
+		}
+		op := item.Operation(context.Request.Method)
+		if op == nil {
+			context.Next()
+			return
+		}
+
+		logger := logging.FromContext(context)
+
+		// note: only JSON bodies are validated, others such as imported files are left to stream to handlers
+		var body []byte
+		if context.Request.Body != nil && hasJSONBody(op) {
+			b, err := ioutil.ReadAll(context.Request.Body)
+			if err != nil {
+				logger.WithError(err).Error("Unable to read body properly")
+				server.Abort(context, server.InternalError)
+				return
+			}
+			body = b
+			// restore body for handlers
+			context.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		pathParams := map[string]string{}
+		for _, param := range context.Params {
+			pathParams[param.Key] = param.Value
+		}
+
+		errs, err := doc.ValidateRequest(op, openapi.Request{
+			PathParams: pathParams,
+			Query:      context.Request.URL.Query(),
+			Header:     context.Request.Header,
+			Body:       body,
+		})
+		if err != nil {
+			logger.WithError(err).Info("Request body is not valid json")
+			server.Abort(context, server.InvalidJSONStringError)
+			return
+		}
+		if len(errs) > 0 {
+			details := make([]server.FieldError, len(errs))
+			for i, e := range errs {
+				details[i] = server.FieldError{Field: e.Field, Message: e.Message}
+			}
+			logger.WithField("operation", op.OperationID).Info("Request validation failed")
+			server.Abort(context, server.ValidationError.WithDetails(details...))
+			return
+		}
+
+		context.Next()
+	}
+}
+
+func hasJSONBody(op *openapi.Operation) bool {
+	if op.RequestBody == nil {
+		return false
+	}
+	_, ok := op.RequestBody.Content["application/json"]
+	return ok
+}
@@ -4,59 +4,79 @@ import (
 	"fmt"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
-	"log"
-	"net/http"
 	"time"
 	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
 	server "url-shortener/internal/route/error"
 )
 
 func UserAuthenticated(jwtKey []byte) gin.HandlerFunc {
 	return func(context *gin.Context) {
+		logger := logging.FromContext(context)
+
 		jwtToken, err := context.Cookie("accessToken")
 		if err != nil {
-			log.Println("No accessToken found on cookie header")
-			context.AbortWithStatusJSON(http.StatusUnauthorized, server.NewResponseErrorWithMessage(server.AuthenticationError))
+			logger.Info("No accessToken found on cookie header")
+			server.Abort(context, server.AuthenticationError)
 			return
 		}
 
 		token, err := jwt.Parse(jwtToken, func(token *jwt.Token) (interface{}, error) {
 			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v\n", jwtToken)
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 			}
 
 			return jwtKey, nil
 		})
 		if err != nil {
-			log.Printf("token parsing error occurred | Reason: %v\n", err)
-			context.AbortWithStatusJSON(http.StatusUnauthorized, server.NewResponseErrorWithMessage(server.AuthenticationError))
+			logger.WithError(err).Info("token parsing error occurred")
+			server.Abort(context, server.AuthenticationError)
 			return
 		}
 
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok || !token.Valid {
-			log.Printf("claims validation failed\n")
-			context.AbortWithStatusJSON(http.StatusUnauthorized, server.NewResponseErrorWithMessage(server.AuthenticationError))
+			logger.Info("claims validation failed")
+			server.Abort(context, server.AuthenticationError)
 			return
 		}
 
-		elapsed := time.Since(time.Unix(int64((claims["issued"]).(float64)), 0)).Seconds()
+		issued, ok := claims["issued"].(float64)
+		if !ok {
+			logger.Info("claims carry no valid issued time")
+			server.Abort(context, server.AuthenticationError)
+			return
+		}
+		email, ok := claims["email"].(string)
+		if !ok {
+			logger.Info("claims carry no valid email")
+			server.Abort(context, server.AuthenticationError)
+			return
+		}
+
+		elapsed := time.Since(time.Unix(int64(issued), 0)).Seconds()
 		if elapsed > 86400*7 { // expire after 7 days
-			log.Printf("access token expired\n")
-			context.AbortWithStatusJSON(http.StatusUnauthorized, server.NewResponseErrorWithMessage(server.AuthenticationError))
+			logger.Info("access token expired")
+			server.Abort(context, server.AuthenticationError)
 			return
 		}
 
 		db := context.Value("db").(database.MySQLService)
-		user, err := db.GetUserWithEmail(claims["email"].(string))
+		user, err := db.GetUserWithEmail(email)
 		if err != nil {
 			if _, ok := err.(database.RecordNotFoundError); ok {
-				log.Printf("given email not found in database\n")
-				context.AbortWithStatusJSON(http.StatusUnauthorized, server.NewResponseErrorWithMessage(server.AuthenticationError))
+				logger.Info("given email not found in database")
+				server.Abort(context, server.AuthenticationError)
 				return
 			}
-			log.Printf("Unable to query for given email in database\n")
-			context.AbortWithStatus(http.StatusInternalServerError)
+			logger.WithError(err).Error("Unable to query for given email in database")
+			server.Abort(context, server.InternalError)
+			return
+		}
+
+		if user.Disabled {
+			logger.WithField("user_id", user.UserID).Info("account disabled")
+			server.Abort(context, server.AccountDisabledError)
 			return
 		}
 
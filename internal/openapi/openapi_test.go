@@ -0,0 +1,92 @@
+package openapi_test
+
+import (
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"url-shortener/internal/openapi"
+	"url-shortener/internal/server"
+)
+
+var _ = Describe("OpenAPI document", func() {
+	var (
+		doc    *openapi.Document
+		router *gin.Engine
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		doc = openapi.Spec()
+		router = server.SetupServer(server.ServerOptions{
+			BaseUrl:      "http://url-shortener.com:8080",
+			Domain:       "url-shortener.com",
+			HtmlTemplate: "../template",
+		})
+	})
+
+	It("should describe every registered route", func() {
+		for _, route := range router.Routes() {
+			_, ok := doc.FindOperation(route.Method, route.Path)
+			Expect(ok).To(BeTrue(), "%v %v is registered without spec entry", route.Method, route.Path)
+		}
+	})
+
+	It("should only describe registered routes", func() {
+		registered := map[string]bool{}
+		for _, route := range router.Routes() {
+			registered[route.Method+" "+openapi.PathFromRoute(route.Path)] = true
+		}
+
+		for path, item := range doc.ResolvedPaths() {
+			for method := range item.Operations() {
+				Expect(registered[method+" "+path]).To(BeTrue(), "%v %v is described but not registered", method, path)
+			}
+		}
+	})
+
+	It("should keep v1 to the routes served when v2 was introduced", func() {
+		registered := map[string]bool{}
+		for _, route := range router.Routes() {
+			registered[route.Method+" "+route.Path] = true
+		}
+
+		Expect(registered["DELETE /api/v1/user/url/r/:shorten_url"]).To(BeTrue())
+		Expect(registered["DELETE /api/user/url/r/:shorten_url"]).To(BeTrue())
+		Expect(registered["PATCH /api/v2/user/url/r/:shorten_url"]).To(BeTrue())
+		Expect(registered["PATCH /api/v1/user/url/r/:shorten_url"]).To(BeFalse())
+		Expect(registered["GET /api/v2/workspaces/"]).To(BeTrue())
+		Expect(registered["GET /api/v1/workspaces/"]).To(BeFalse())
+		Expect(registered["GET /api/workspaces/"]).To(BeFalse())
+	})
+
+	Describe("Request validation", func() {
+		It("should report missing and mistyped fields", func() {
+			op, ok := doc.FindOperation("POST", "/api/user/sign/")
+			Expect(ok).To(BeTrue())
+
+			errs, err := doc.ValidateRequest(op, openapi.Request{Body: []byte(`{"email": 1}`)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errs).To(ConsistOf(
+				openapi.ValidationError{Field: "email", Message: "must be of type string"},
+				openapi.ValidationError{Field: "password", Message: "is required"},
+			))
+		})
+
+		It("should report malformed json", func() {
+			op, ok := doc.FindOperation("POST", "/api/shortener/")
+			Expect(ok).To(BeTrue())
+
+			_, err := doc.ValidateRequest(op, openapi.Request{Body: []byte(`{"url":`)})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should validate query parameters", func() {
+			op, ok := doc.FindOperation("GET", "/api/user/url/list")
+			Expect(ok).To(BeTrue())
+
+			errs, err := doc.ValidateRequest(op, openapi.Request{Query: map[string][]string{"limit": {"ten"}}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errs).To(ConsistOf(openapi.ValidationError{Field: "limit", Message: "must be of type integer"}))
+		})
+	})
+})
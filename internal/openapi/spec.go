@@ -0,0 +1,1425 @@
+package openapi
+
+// Spec returns the OpenAPI document describing every route registered by server.SetupServer.
+func Spec() *Document {
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "URL Shortener",
+			Description: "Shorten urls and manage them with a user account.",
+			Version:     "1.0.0",
+		},
+		Paths: map[string]*PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{
+				"Error": object(map[string]*Schema{
+					"error": object(map[string]*Schema{
+						"code":    str(),
+						"message": str(),
+						"details": array(object(map[string]*Schema{
+							"field":   str(),
+							"message": str(),
+						}, "field", "message")),
+					}, "code", "message"),
+				}, "error"),
+				"Message": object(map[string]*Schema{
+					"message": str(),
+				}, "message"),
+				"Auth": object(map[string]*Schema{
+					"email":    str(),
+					"password": str(),
+				}, "email", "password"),
+				"SignUpCompletion": object(map[string]*Schema{
+					"email": str(),
+					"code":  str(),
+				}, "email", "code"),
+				"ShortenRequest": object(map[string]*Schema{
+					"url":       str(),
+					"workspace": str(),
+					"domain":    str(),
+				}, "url"),
+				"ShortenResponse": object(map[string]*Schema{
+					"url": str(),
+				}, "url"),
+				"URL": object(map[string]*Schema{
+					"origin_url":      str(),
+					"workspace":       str(),
+					"created_by":      str(),
+					"domain":          str(),
+					"shorten_url":     str(),
+					"redirect_status": integerEnum(301, 302, 307, 308),
+					"analytics":       boolean(),
+					"app_uri":         str(),
+					"starts_at":       dateTime(),
+					"ends_at":         dateTime(),
+					"hits":            integer(),
+					"title":           str(),
+					"folder":          str(),
+					"note":            str(),
+					"tags":            array(str()),
+					"metadata": object(map[string]*Schema{
+						"title":       str(),
+						"description": str(),
+						"image":       str(),
+						"favicon":     str(),
+						"status":      integer(),
+						"broken":      boolean(),
+						"fetched_at":  dateTime(),
+					}, "title", "description", "image", "favicon", "status", "broken", "fetched_at"),
+					"broken_since": dateTime(),
+					"suspended_at": dateTime(),
+					"created_at":   dateTime(),
+					"updated_at":   dateTime(),
+				}, "origin_url", "workspace", "created_by", "domain", "shorten_url", "redirect_status", "analytics", "app_uri", "hits", "title", "folder", "note", "tags", "created_at", "updated_at"),
+				"ExportedURL": object(map[string]*Schema{
+					"shorten_url": str(),
+					"origin_url":  str(),
+					"domain":      str(),
+					"title":       str(),
+					"folder":      str(),
+					"note":        str(),
+					"tags":        array(str()),
+					"hits":        integer(),
+					"created_at":  dateTime(),
+					"updated_at":  dateTime(),
+				}, "origin_url"),
+				"ImportResult": object(map[string]*Schema{
+					"imported": integer(),
+					"skipped":  integer(),
+					"conflicts": array(object(map[string]*Schema{
+						"row":         integer(),
+						"requested":   str(),
+						"shorten_url": str(),
+						"reason":      str(),
+					}, "row", "requested", "shorten_url", "reason")),
+					"failures": array(object(map[string]*Schema{
+						"row":     integer(),
+						"message": str(),
+					}, "row", "message")),
+				}, "imported", "skipped", "conflicts", "failures"),
+				"URLDetails": object(map[string]*Schema{
+					"title":           maxLength(str(), 255),
+					"folder":          maxLength(str(), 100),
+					"note":            maxLength(str(), 2000),
+					"tags":            maxItems(array(maxLength(str(), 50)), 20),
+					"redirect_status": integerEnum(301, 302, 307, 308),
+					"analytics":       boolean(),
+					"app_uri":         maxLength(str(), 2048),
+					"starts_at":       str(),
+					"ends_at":         str(),
+				}),
+				"URLChecks": object(map[string]*Schema{
+					"checks": array(object(map[string]*Schema{
+						"status":     integer(),
+						"error":      str(),
+						"broken":     boolean(),
+						"checked_at": dateTime(),
+					}, "status", "broken", "checked_at")),
+				}, "checks"),
+				"RedirectRules": object(map[string]*Schema{
+					"rules": maxItems(array(object(map[string]*Schema{
+						"device":      enum("ios", "android", "desktop"),
+						"country":     str(),
+						"language":    str(),
+						"destination": str(),
+					}, "destination")), 20),
+				}, "rules"),
+				"URLVariants": object(map[string]*Schema{
+					"variants": maxItems(array(object(map[string]*Schema{
+						"id":          integer(),
+						"destination": str(),
+						"weight":      integer(),
+						"hits":        integer(),
+					}, "destination", "weight")), 10),
+				}, "variants"),
+				"URLSchedule": object(map[string]*Schema{
+					"timezone": str(),
+					"windows": maxItems(array(object(map[string]*Schema{
+						"days":  array(enum("sun", "mon", "tue", "wed", "thu", "fri", "sat")),
+						"start": str(),
+						"end":   str(),
+					}, "days", "start", "end")), 20),
+					"alternate_url": str(),
+				}, "windows"),
+				"Preferences": object(map[string]*Schema{
+					"dead_link_alerts": boolean(),
+				}, "dead_link_alerts"),
+				"PreferencesUpdate": object(map[string]*Schema{
+					"dead_link_alerts": boolean(),
+				}),
+				"DataExport": object(map[string]*Schema{
+					"id":           str(),
+					"status":       enum("pending", "ready", "failed"),
+					"size":         integer(),
+					"created_at":   dateTime(),
+					"completed_at": dateTime(),
+					"expires_at":   dateTime(),
+				}, "id", "status", "size", "created_at"),
+				"DataExports": object(map[string]*Schema{
+					"exports": array(ref("DataExport")),
+				}, "exports"),
+				"Workspace": object(map[string]*Schema{
+					"id":         str(),
+					"name":       str(),
+					"personal":   boolean(),
+					"role":       enum("owner", "editor", "viewer"),
+					"created_at": dateTime(),
+				}, "id", "name", "personal", "role", "created_at"),
+				"Workspaces": object(map[string]*Schema{
+					"workspaces": array(ref("Workspace")),
+				}, "workspaces"),
+				"AdminUser": object(map[string]*Schema{
+					"user_id":  str(),
+					"email":    str(),
+					"type":     enum("local", "google"),
+					"role":     enum("user", "admin"),
+					"disabled": boolean(),
+				}, "user_id", "email", "type", "role", "disabled"),
+				"AdminUsers": object(map[string]*Schema{
+					"total": integer(),
+					"users": array(ref("AdminUser")),
+				}, "total", "users"),
+				"AdminUserUpdate": object(map[string]*Schema{
+					"role":     enum("user", "admin"),
+					"disabled": boolean(),
+				}),
+				"URLsTransfer": object(map[string]*Schema{
+					"to": str(),
+				}, "to"),
+				"URLsTransferred": object(map[string]*Schema{
+					"transferred": integer(),
+				}, "transferred"),
+				"SystemStats": object(map[string]*Schema{
+					"users":          integer(),
+					"disabled_users": integer(),
+					"workspaces":     integer(),
+					"urls":           integer(),
+					"deleted_urls":   integer(),
+					"hits":           integer(),
+					"domains":        integer(),
+					"pages":          integer(),
+				}, "users", "disabled_users", "workspaces", "urls", "deleted_urls", "hits", "domains", "pages"),
+				"ReportRequest": object(map[string]*Schema{
+					"reason":  enum("spam", "phishing", "malware", "illegal", "other"),
+					"details": maxLength(str(), 1000),
+					"email":   maxLength(str(), 255),
+				}, "reason"),
+				"Report": object(map[string]*Schema{
+					"id":             integer(),
+					"shorten_url":    str(),
+					"reason":         enum("spam", "phishing", "malware", "illegal", "other"),
+					"details":        str(),
+					"reporter_email": str(),
+					"reporter_ip":    str(),
+					"status":         enum("open", "dismissed"),
+					"reviewed_by":    str(),
+					"created_at":     dateTime(),
+					"reviewed_at":    dateTime(),
+				}, "id", "shorten_url", "reason", "reporter_ip", "status", "created_at"),
+				"Reports": object(map[string]*Schema{
+					"total":   integer(),
+					"reports": array(ref("Report")),
+				}, "total", "reports"),
+				"ReportsResolution": object(map[string]*Schema{
+					"decision": enum("dismiss", "remove"),
+				}, "decision"),
+				"AuditEntry": object(map[string]*Schema{
+					"id":          integer(),
+					"actor":       str(),
+					"action":      str(),
+					"target_type": enum("user", "url"),
+					"target_id":   str(),
+					"before":      {Type: "object", Description: "Snapshot of target before the change"},
+					"after":       {Type: "object", Description: "Snapshot of target after the change"},
+					"ip":          str(),
+					"user_agent":  str(),
+					"created_at":  dateTime(),
+				}, "id", "actor", "action", "target_type", "target_id", "ip", "user_agent", "created_at"),
+				"AuditEntries": object(map[string]*Schema{
+					"entries":     array(ref("AuditEntry")),
+					"next_before": integer(),
+				}, "entries"),
+				"Page": object(map[string]*Schema{
+					"slug":        str(),
+					"workspace":   str(),
+					"created_by":  str(),
+					"title":       str(),
+					"description": str(),
+					"avatar_url":  str(),
+					"theme":       enum("light", "dark", "ocean", "sunset"),
+					"links":       array(ref("PageLink")),
+					"created_at":  dateTime(),
+					"updated_at":  dateTime(),
+				}, "slug", "workspace", "created_by", "title", "description", "avatar_url", "theme", "created_at", "updated_at"),
+				"PageLink": object(map[string]*Schema{
+					"shorten_url": str(),
+					"label":       maxLength(str(), 100),
+				}, "shorten_url"),
+				"Pages": object(map[string]*Schema{
+					"pages": array(ref("Page")),
+				}, "pages"),
+				"PageRequest": object(map[string]*Schema{
+					"slug":        str(),
+					"workspace":   str(),
+					"title":       maxLength(str(), 100),
+					"description": maxLength(str(), 500),
+					"avatar_url":  maxLength(str(), 2048),
+					"theme":       enum("light", "dark", "ocean", "sunset"),
+					"links":       maxItems(array(ref("PageLink")), 50),
+				}),
+				"WorkspaceCreation": object(map[string]*Schema{
+					"name": maxLength(str(), 100),
+				}, "name"),
+				"Members": object(map[string]*Schema{
+					"members": array(object(map[string]*Schema{
+						"user_id": str(),
+						"email":   str(),
+						"role":    enum("owner", "editor", "viewer"),
+					}, "user_id", "email", "role")),
+				}, "members"),
+				"MemberUpdate": object(map[string]*Schema{
+					"role": enum("owner", "editor", "viewer"),
+				}, "role"),
+				"InvitationRequest": object(map[string]*Schema{
+					"email": str(),
+					"role":  enum("owner", "editor", "viewer"),
+				}, "email", "role"),
+				"Invitation": object(map[string]*Schema{
+					"email":      str(),
+					"role":       enum("owner", "editor", "viewer"),
+					"expires_at": dateTime(),
+					"token":      str(),
+				}, "email", "role", "expires_at"),
+				"InvitationAcceptance": object(map[string]*Schema{
+					"token": str(),
+				}, "token"),
+				"Domain": object(map[string]*Schema{
+					"hostname":    str(),
+					"verified":    boolean(),
+					"verified_at": dateTime(),
+					"created_at":  dateTime(),
+					"verification": object(map[string]*Schema{
+						"type":  enum("TXT"),
+						"name":  str(),
+						"value": str(),
+					}, "type", "name", "value"),
+				}, "hostname", "verified", "created_at", "verification"),
+				"Domains": object(map[string]*Schema{
+					"domains": array(ref("Domain")),
+				}, "domains"),
+				"DomainRequest": object(map[string]*Schema{
+					"hostname": maxLength(str(), 253),
+				}, "hostname"),
+				"WebhookCreation": object(map[string]*Schema{
+					"url":              maxLength(str(), 2048),
+					"events":           array(enum("url.created", "url.updated", "url.deleted", "url.clicks")),
+					"click_thresholds": maxItems(array(integer()), 10),
+					"enabled":          boolean(),
+				}, "url", "events"),
+				"WebhookRequest": object(map[string]*Schema{
+					"url":              maxLength(str(), 2048),
+					"events":           array(enum("url.created", "url.updated", "url.deleted", "url.clicks")),
+					"click_thresholds": maxItems(array(integer()), 10),
+					"enabled":          boolean(),
+				}),
+				"Webhook": object(map[string]*Schema{
+					"id":                   str(),
+					"url":                  str(),
+					"events":               array(enum("url.created", "url.updated", "url.deleted", "url.clicks")),
+					"click_thresholds":     array(integer()),
+					"enabled":              boolean(),
+					"disabled_at":          dateTime(),
+					"consecutive_failures": integer(),
+					"created_at":           dateTime(),
+					"secret":               {Type: "string", Description: "Signs deliveries, only returned on creation"},
+				}, "id", "url", "events", "click_thresholds", "enabled", "consecutive_failures", "created_at"),
+				"Webhooks": object(map[string]*Schema{
+					"webhooks": array(ref("Webhook")),
+				}, "webhooks"),
+				"WebhookPayload": object(map[string]*Schema{
+					"event":     enum("url.created", "url.updated", "url.deleted", "url.clicks"),
+					"workspace": str(),
+					"link": object(map[string]*Schema{
+						"shorten_url": str(),
+						"origin_url":  str(),
+						"domain":      str(),
+						"title":       str(),
+						"tags":        array(str()),
+						"hits":        integer(),
+						"created_at":  dateTime(),
+						"updated_at":  dateTime(),
+						"deleted_at":  dateTime(),
+					}, "shorten_url", "origin_url", "title", "tags", "hits", "created_at", "updated_at"),
+					"clicks":     {Type: "integer", Description: "Threshold reached, for url.clicks only"},
+					"created_at": dateTime(),
+				}, "event", "workspace", "link", "created_at"),
+				"WebhookDelivery": object(map[string]*Schema{
+					"id":              integer(),
+					"event":           str(),
+					"payload":         ref("WebhookPayload"),
+					"status":          enum("pending", "delivered", "failed"),
+					"attempts":        integer(),
+					"response_status": integer(),
+					"error":           str(),
+					"next_attempt_at": dateTime(),
+					"created_at":      dateTime(),
+					"delivered_at":    dateTime(),
+				}, "id", "event", "payload", "status", "attempts", "created_at"),
+				"WebhookDeliveries": object(map[string]*Schema{
+					"total":      integer(),
+					"deliveries": array(ref("WebhookDelivery")),
+				}, "total", "deliveries"),
+				"Tags": object(map[string]*Schema{
+					"tags": array(object(map[string]*Schema{
+						"tag":   str(),
+						"links": integer(),
+						"hits":  integer(),
+					}, "tag", "links", "hits")),
+				}, "tags"),
+				"URLs": object(map[string]*Schema{
+					"total": integer(),
+					"urls":  array(ref("URL")),
+				}, "total", "urls"),
+				"TrashedURL": allOf(ref("URL"), object(map[string]*Schema{
+					"deleted_at": dateTime(),
+					"purge_at":   dateTime(),
+				}, "deleted_at", "purge_at")),
+				"Trash": object(map[string]*Schema{
+					"urls": array(ref("TrashedURL")),
+				}, "urls"),
+				"URLsPage": object(map[string]*Schema{
+					"total":       integer(),
+					"urls":        array(ref("URL")),
+					"next_cursor": str(),
+				}, "total", "urls"),
+			},
+			SecuritySchemes: map[string]*SecurityScheme{
+				"cookieAuth": {
+					Type: "apiKey",
+					In:   "cookie",
+					Name: "accessToken",
+				},
+			},
+		},
+	}
+
+	doc.Paths["/metrics"] = &PathItem{
+		Get: &Operation{
+			OperationID: "getMetrics",
+			Summary:     "Prometheus metrics",
+			Tags:        []string{"system"},
+			Responses: map[string]*Response{
+				"200": {Description: "Metrics in Prometheus text format", Content: map[string]*MediaType{
+					"text/plain": {Schema: str()},
+				}},
+			},
+		},
+	}
+
+	for path, operationID := range map[string]string{
+		"/.well-known/apple-app-site-association": "getAppleAppSiteAssociation",
+		"/.well-known/assetlinks.json":            "getAndroidAssetLinks",
+	} {
+		doc.Paths[path] = &PathItem{
+			Get: &Operation{
+				OperationID: operationID,
+				Summary:     "Association of apps opening short urls as deep links, as configured",
+				Tags:        []string{"system"},
+				Responses: withErrors(map[string]*Response{
+					"200": jsonResponse("Association file", object(nil)),
+				}, "404"),
+			},
+		}
+	}
+
+	doc.Paths["/p/{slug}"] = &PathItem{
+		Get: &Operation{
+			OperationID: "renderPage",
+			Summary:     "Public page of links, each pointing to its short url",
+			Tags:        []string{"page"},
+			Parameters:  []Parameter{pathParam("slug")},
+			Responses: withErrors(map[string]*Response{
+				"200": {Description: "Page", Content: map[string]*MediaType{
+					"text/html": {Schema: str()},
+				}},
+			}, "404"),
+		},
+	}
+
+	doc.Paths["/api/openapi.json"] = &PathItem{
+		Get: &Operation{
+			OperationID: "getOpenAPI",
+			Summary:     "This document",
+			Tags:        []string{"system"},
+			Responses: map[string]*Response{
+				"200": jsonResponse("OpenAPI document", object(nil)),
+			},
+		},
+	}
+
+	for _, api := range apiVersions {
+		addSignPaths(doc, api)
+		addShortenerPaths(doc, api)
+		if api.version < 2 {
+			continue
+		}
+		addWorkspacePaths(doc, api)
+		addPagePaths(doc, api)
+		addAuditPaths(doc, api)
+		addAdminPaths(doc, api)
+		addReportPaths(doc, api)
+	}
+
+	return doc
+}
+
+// apiVersion describes one of the groups server.SetupServer registers api routes with.
+type apiVersion struct {
+	prefix     string
+	version    int
+	suffix     string // keeps operation ids unique across versions
+	deprecated bool
+}
+
+// note: v1 and its legacy aliases are frozen to the routes served when v2 was introduced, later ones are v2 only
+var apiVersions = []apiVersion{
+	{prefix: "/api/v1", version: 1, suffix: "V1"},
+	{prefix: "/api/v2", version: 2, suffix: "V2"},
+	{prefix: "/api", version: 1, suffix: "Legacy", deprecated: true},
+}
+
+func (a apiVersion) add(doc *Document, path string, item *PathItem) {
+	for _, op := range item.Operations() {
+		op.OperationID += a.suffix
+		op.Deprecated = a.deprecated
+	}
+	doc.Paths[a.prefix+path] = item
+}
+
+func addSignPaths(doc *Document, api apiVersion) {
+	api.add(doc, "/user/authCheck", &PathItem{
+		Get: &Operation{
+			OperationID: "authCheck",
+			Summary:     "Check whether the access token is valid",
+			Tags:        []string{"user"},
+			Security:    cookieAuth(),
+			Responses: withErrors(map[string]*Response{
+				"200": {Description: "Authenticated"},
+			}, "401"),
+		},
+	})
+
+	api.add(doc, "/user/sign/", &PathItem{
+		Post: &Operation{
+			OperationID: "signIn",
+			Summary:     "Sign in with local account",
+			Tags:        []string{"user"},
+			RequestBody: jsonBody(ref("Auth")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Signed in", object(map[string]*Schema{
+					"issueToken": str(),
+				}, "issueToken")),
+			}, "400"),
+		},
+	})
+
+	api.add(doc, "/user/sign/google/", &PathItem{
+		Get: &Operation{
+			OperationID: "signInWithGoogle",
+			Summary:     "Redirect to Google sign-in",
+			Tags:        []string{"user"},
+			Responses: map[string]*Response{
+				"302": {Description: "Redirect to Google consent page"},
+			},
+		},
+	})
+
+	api.add(doc, "/user/sign/google/callback", &PathItem{
+		Get: &Operation{
+			OperationID: "signInWithGoogleCallback",
+			Summary:     "Complete Google sign-in",
+			Tags:        []string{"user"},
+			Parameters: []Parameter{
+				queryParam("state", str(), true),
+				queryParam("code", str(), true),
+			},
+			Responses: withErrors(map[string]*Response{
+				"200": {Description: "Page handing the access token over to the opener window", Content: map[string]*MediaType{
+					"text/html": {Schema: str()},
+				}},
+			}, "400"),
+		},
+	})
+
+	api.add(doc, "/user/signup", &PathItem{
+		Post: &Operation{
+			OperationID: "signUp",
+			Summary:     "Request registration of local account",
+			Tags:        []string{"user"},
+			RequestBody: jsonBody(ref("Auth")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Registration request accepted", ref("Message")),
+			}, "400"),
+		},
+	})
+
+	api.add(doc, "/user/signup/complete", &PathItem{
+		Post: &Operation{
+			OperationID: "signUpComplete",
+			Summary:     "Complete registration with verification code",
+			Tags:        []string{"user"},
+			RequestBody: jsonBody(ref("SignUpCompletion")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Registered", ref("Message")),
+			}, "400"),
+		},
+	})
+
+	if api.version < 2 {
+		return
+	}
+
+	api.add(doc, "/user/preferences", &PathItem{
+		Get: &Operation{
+			OperationID: "getPreferences",
+			Summary:     "Get preferences of user",
+			Tags:        []string{"user"},
+			Security:    cookieAuth(),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Preferences", ref("Preferences")),
+			}, "401"),
+		},
+		Patch: &Operation{
+			OperationID: "updatePreferences",
+			Summary:     "Update preferences of user",
+			Tags:        []string{"user"},
+			Security:    cookieAuth(),
+			RequestBody: jsonBody(ref("PreferencesUpdate")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Updated preferences", ref("Preferences")),
+			}, "400", "401"),
+		},
+	})
+
+	api.add(doc, "/user/data-export", &PathItem{
+		Get: &Operation{
+			OperationID: "listDataExports",
+			Summary:     "List data exports requested by user, newest first",
+			Tags:        []string{"user"},
+			Security:    cookieAuth(),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Data exports", ref("DataExports")),
+			}, "401"),
+		},
+		Post: &Operation{
+			OperationID: "createDataExport",
+			Summary:     "Request a zip archive of everything held about user, emailed once ready",
+			Tags:        []string{"user"},
+			Security:    cookieAuth(),
+			Responses: withErrors(map[string]*Response{
+				"202": jsonResponse("Pending data export", ref("DataExport")),
+			}, "400", "401"),
+		},
+	})
+
+	api.add(doc, "/user/data-export/{export_id}", &PathItem{
+		Get: &Operation{
+			OperationID: "downloadDataExport",
+			Summary:     "Download the archive of a ready data export until it expires",
+			Tags:        []string{"user"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("export_id")},
+			Responses: withErrors(map[string]*Response{
+				"200": {Description: "Zip archive of json files", Content: map[string]*MediaType{
+					"application/zip": {Schema: &Schema{Type: "string", Format: "binary"}},
+				}},
+			}, "400", "401", "404"),
+		},
+	})
+
+	api.add(doc, "/user/invitations/accept", &PathItem{
+		Post: &Operation{
+			OperationID: "acceptInvitation",
+			Summary:     "Join workspace with invitation token",
+			Tags:        []string{"workspace"},
+			Security:    cookieAuth(),
+			RequestBody: jsonBody(ref("InvitationAcceptance")),
+			Responses: withErrors(map[string]*Response{
+				"200": {Description: "Joined"},
+			}, "400", "401", "404"),
+		},
+	})
+}
+
+func addShortenerPaths(doc *Document, api apiVersion) {
+	if api.version >= 2 {
+		api.add(doc, "/user/url/list", &PathItem{
+			Get: &Operation{
+				OperationID: "listURLs",
+				Summary:     "List urls of workspace page by page",
+				Tags:        []string{"url"},
+				Security:    cookieAuth(),
+				Parameters: []Parameter{
+					queryParam("cursor", str(), false),
+					queryParam("limit", integer(), false),
+					queryParam("sort", enum("created", "updated", "hits"), false),
+					queryParam("q", str(), false),
+					queryParam("tag", str(), false),
+					queryParam("folder", str(), false),
+					queryParam("workspace", str(), false),
+				},
+				Responses: withErrors(map[string]*Response{
+					"200": jsonResponse("Page of workspace's urls", ref("URLsPage")),
+				}, "400", "401", "404"),
+			},
+		})
+	} else {
+		api.add(doc, "/user/url/list", &PathItem{
+			Get: &Operation{
+				OperationID: "listURLs",
+				Summary:     "List urls of workspace",
+				Tags:        []string{"url"},
+				Security:    cookieAuth(),
+				Parameters: []Parameter{
+					queryParam("offset", integer(), false),
+					queryParam("limit", integer(), false),
+					queryParam("tag", str(), false),
+					queryParam("folder", str(), false),
+					queryParam("workspace", str(), false),
+				},
+				Responses: withErrors(map[string]*Response{
+					"200": jsonResponse("Urls of workspace", ref("URLs")),
+				}, "400", "401", "404"),
+			},
+		})
+	}
+
+	urlItem := &PathItem{
+		Delete: &Operation{
+			OperationID: "deleteURL",
+			Summary:     "Move shorten url to the trash",
+			Tags:        []string{"url"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("shorten_url")},
+			Responses: withErrors(map[string]*Response{
+				"200": {Description: "Deleted"},
+			}, "401", "403", "404"),
+		},
+	}
+	if api.version >= 2 {
+		urlItem.Patch = &Operation{
+			OperationID: "updateURL",
+			Summary:     "Edit title, folder, note and tags of shorten url",
+			Tags:        []string{"url"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("shorten_url")},
+			RequestBody: jsonBody(ref("URLDetails")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Updated url", ref("URL")),
+			}, "400", "401", "403", "404"),
+		}
+	}
+	api.add(doc, "/user/url/r/{shorten_url}", urlItem)
+
+	api.add(doc, "/shortener/", &PathItem{
+		Post: &Operation{
+			OperationID: "createURL",
+			Summary:     "Shorten url",
+			Tags:        []string{"url"},
+			Security:    cookieAuth(),
+			RequestBody: jsonBody(ref("ShortenRequest")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Shorten url", ref("ShortenResponse")),
+			}, "400", "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/shortener/r/{shorten_url}", &PathItem{
+		Get: &Operation{
+			OperationID: "resolveURL",
+			Summary:     "Redirect to origin url, also served at /{shorten_url}",
+			Tags:        []string{"url"},
+			Parameters:  []Parameter{pathParam("shorten_url")},
+			Responses:   withErrors(redirectResponses(), "404"),
+		},
+		Head: &Operation{
+			OperationID: "checkURL",
+			Summary:     "Redirect to origin url without counting a hit",
+			Tags:        []string{"url"},
+			Parameters:  []Parameter{pathParam("shorten_url")},
+			Responses:   withErrors(redirectResponses(), "404"),
+		},
+	})
+
+	if api.version < 2 {
+		return
+	}
+
+	api.add(doc, "/user/url/tags", &PathItem{
+		Get: &Operation{
+			OperationID: "listTags",
+			Summary:     "List tags of workspace's urls with aggregate hits",
+			Tags:        []string{"url"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{queryParam("workspace", str(), false)},
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Tags of workspace", ref("Tags")),
+			}, "401", "404"),
+		},
+	})
+
+	api.add(doc, "/user/url/trash", &PathItem{
+		Get: &Operation{
+			OperationID: "listTrash",
+			Summary:     "List deleted urls of workspace restorable until purged",
+			Tags:        []string{"url"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{queryParam("workspace", str(), false)},
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Deleted urls, the most recently deleted first", ref("Trash")),
+			}, "401", "404"),
+		},
+	})
+
+	api.add(doc, "/user/url/export", &PathItem{
+		Get: &Operation{
+			OperationID: "exportURLs",
+			Summary:     "Stream every url of workspace with hits, tags and timestamps as csv or json lines",
+			Tags:        []string{"url"},
+			Security:    cookieAuth(),
+			Parameters: []Parameter{
+				queryParam("workspace", str(), false),
+				queryParam("format", enum("csv", "jsonl"), false),
+			},
+			Responses: withErrors(map[string]*Response{
+				"200": {Description: "Exported urls, csv with a header or one ExportedURL per line", Content: map[string]*MediaType{
+					"text/csv":             {Schema: str()},
+					"application/x-ndjson": {Schema: ref("ExportedURL")},
+				}},
+			}, "400", "401", "404"),
+		},
+	})
+
+	api.add(doc, "/user/url/import", &PathItem{
+		Post: &Operation{
+			OperationID: "importURLs",
+			Summary:     "Recreate urls of an export, or of a Bitly csv export, keeping their codes when available",
+			Tags:        []string{"url"},
+			Security:    cookieAuth(),
+			Parameters: []Parameter{
+				queryParam("workspace", str(), false),
+				queryParam("format", enum("csv", "jsonl"), false),
+			},
+			RequestBody: &RequestBody{
+				Required: true,
+				Content: map[string]*MediaType{
+					"text/csv":             {Schema: str()},
+					"application/x-ndjson": {Schema: ref("ExportedURL")},
+				},
+			},
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Outcome of import", ref("ImportResult")),
+			}, "400", "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/user/url/r/{shorten_url}/restore", &PathItem{
+		Post: &Operation{
+			OperationID: "restoreURL",
+			Summary:     "Restore shorten url from the trash before it is purged",
+			Tags:        []string{"url"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("shorten_url")},
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Restored url", ref("URL")),
+			}, "400", "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/user/url/r/{shorten_url}/checks", &PathItem{
+		Get: &Operation{
+			OperationID: "listURLChecks",
+			Summary:     "List latest checks of destination of shorten url",
+			Tags:        []string{"url"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("shorten_url")},
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Checks, newest first", ref("URLChecks")),
+			}, "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/user/url/r/{shorten_url}/rules", &PathItem{
+		Get: &Operation{
+			OperationID: "listRedirectRules",
+			Summary:     "List redirect rules of shorten url in evaluation order",
+			Tags:        []string{"url"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("shorten_url")},
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Redirect rules", ref("RedirectRules")),
+			}, "401", "403", "404"),
+		},
+		Put: &Operation{
+			OperationID: "replaceRedirectRules",
+			Summary:     "Replace redirect rules of shorten url, the first rule matching device, country and language of a visitor wins",
+			Tags:        []string{"url"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("shorten_url")},
+			RequestBody: jsonBody(ref("RedirectRules")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Stored redirect rules", ref("RedirectRules")),
+			}, "400", "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/user/url/r/{shorten_url}/variants", &PathItem{
+		Get: &Operation{
+			OperationID: "listURLVariants",
+			Summary:     "List weighted destination variants of shorten url with hits of each",
+			Tags:        []string{"url"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("shorten_url")},
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Variants", ref("URLVariants")),
+			}, "401", "403", "404"),
+		},
+		Put: &Operation{
+			OperationID: "replaceURLVariants",
+			Summary:     "Replace destination variants of shorten url, visitors stick to the variant they were assigned",
+			Tags:        []string{"url"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("shorten_url")},
+			RequestBody: jsonBody(ref("URLVariants")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Stored variants", ref("URLVariants")),
+			}, "400", "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/user/url/r/{shorten_url}/schedule", &PathItem{
+		Get: &Operation{
+			OperationID: "getURLSchedule",
+			Summary:     "Get weekly windows shorten url is active in, none meaning always",
+			Tags:        []string{"url"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("shorten_url")},
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Schedule", ref("URLSchedule")),
+			}, "401", "403", "404"),
+		},
+		Put: &Operation{
+			OperationID: "replaceURLSchedule",
+			Summary:     "Replace schedule of shorten url, visitors outside of its windows are sent to the alternate url",
+			Tags:        []string{"url"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("shorten_url")},
+			RequestBody: jsonBody(ref("URLSchedule")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Stored schedule", ref("URLSchedule")),
+			}, "400", "401", "403", "404"),
+		},
+	})
+}
+
+func addPagePaths(doc *Document, api apiVersion) {
+	api.add(doc, "/pages/", &PathItem{
+		Get: &Operation{
+			OperationID: "listPages",
+			Summary:     "List pages of workspace, without their links",
+			Tags:        []string{"page"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{queryParam("workspace", str(), false)},
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Pages of workspace", ref("Pages")),
+			}, "401", "404"),
+		},
+		Post: &Operation{
+			OperationID: "createPage",
+			Summary:     "Publish a page listing urls of workspace at /p/{slug}",
+			Tags:        []string{"page"},
+			Security:    cookieAuth(),
+			RequestBody: jsonBody(ref("PageRequest")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Created page", ref("Page")),
+			}, "400", "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/pages/{slug}", &PathItem{
+		Get: &Operation{
+			OperationID: "getPage",
+			Summary:     "Get page with its links",
+			Tags:        []string{"page"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("slug")},
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Page", ref("Page")),
+			}, "401", "404"),
+		},
+		Put: &Operation{
+			OperationID: "updatePage",
+			Summary:     "Replace title, description, avatar, theme and links of page",
+			Tags:        []string{"page"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("slug")},
+			RequestBody: jsonBody(ref("PageRequest")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Updated page", ref("Page")),
+			}, "400", "401", "403", "404"),
+		},
+		Delete: &Operation{
+			OperationID: "deletePage",
+			Summary:     "Unpublish page",
+			Tags:        []string{"page"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("slug")},
+			Responses: withErrors(map[string]*Response{
+				"200": {Description: "Deleted"},
+			}, "401", "403", "404"),
+		},
+	})
+}
+
+func addAuditPaths(doc *Document, api apiVersion) {
+	filters := []Parameter{
+		queryParam("action", str(), false),
+		queryParam("target", str(), false),
+		queryParam("before", integer(), false),
+		queryParam("limit", integer(), false),
+	}
+
+	api.add(doc, "/user/audit", &PathItem{
+		Get: &Operation{
+			OperationID: "listAuditTrail",
+			Summary:     "List changes made by user, newest first",
+			Tags:        []string{"audit"},
+			Security:    cookieAuth(),
+			Parameters:  filters,
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Audit entries", ref("AuditEntries")),
+			}, "400", "401"),
+		},
+	})
+
+	api.add(doc, "/admin/audit", &PathItem{
+		Get: &Operation{
+			OperationID: "listAllAuditEntries",
+			Summary:     "List changes made by every user, newest first",
+			Tags:        []string{"admin"},
+			Security:    cookieAuth(),
+			Parameters:  append([]Parameter{queryParam("actor", str(), false)}, filters...),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Audit entries", ref("AuditEntries")),
+			}, "400", "401", "403"),
+		},
+	})
+}
+
+func addAdminPaths(doc *Document, api apiVersion) {
+	api.add(doc, "/admin/users", &PathItem{
+		Get: &Operation{
+			OperationID: "listUsers",
+			Summary:     "List users by email, optionally searched by part of the email",
+			Tags:        []string{"admin"},
+			Security:    cookieAuth(),
+			Parameters: []Parameter{
+				queryParam("q", str(), false),
+				queryParam("offset", integer(), false),
+				queryParam("limit", integer(), false),
+			},
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Users", ref("AdminUsers")),
+			}, "400", "401", "403"),
+		},
+	})
+
+	api.add(doc, "/admin/users/{user_id}", &PathItem{
+		Patch: &Operation{
+			OperationID: "updateUser",
+			Summary:     "Change role of user or disable the account",
+			Tags:        []string{"admin"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("user_id")},
+			RequestBody: jsonBody(ref("AdminUserUpdate")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Updated user", ref("AdminUser")),
+			}, "400", "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/admin/users/{user_id}/transfer", &PathItem{
+		Post: &Operation{
+			OperationID: "transferURLs",
+			Summary:     "Move urls of personal workspace of user on the default domain to another user",
+			Tags:        []string{"admin"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("user_id")},
+			RequestBody: jsonBody(ref("URLsTransfer")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Number of transferred urls", ref("URLsTransferred")),
+			}, "400", "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/admin/urls/{shorten_url}", &PathItem{
+		Delete: &Operation{
+			OperationID: "purgeURL",
+			Summary:     "Permanently remove shorten url, skipping the trash",
+			Tags:        []string{"admin"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("shorten_url")},
+			Responses: withErrors(map[string]*Response{
+				"200": {Description: "Purged"},
+			}, "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/admin/reports", &PathItem{
+		Get: &Operation{
+			OperationID: "listReports",
+			Summary:     "List reports with status, open ones by default, the oldest first",
+			Tags:        []string{"admin"},
+			Security:    cookieAuth(),
+			Parameters: []Parameter{
+				queryParam("status", enum("open", "dismissed"), false),
+				queryParam("offset", integer(), false),
+				queryParam("limit", integer(), false),
+			},
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Reports", ref("Reports")),
+			}, "400", "401", "403"),
+		},
+	})
+
+	api.add(doc, "/admin/reports/{shorten_url}/resolve", &PathItem{
+		Post: &Operation{
+			OperationID: "resolveReports",
+			Summary:     "Settle open reports of shorten url, lifting its suspension or purging it",
+			Tags:        []string{"admin"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("shorten_url")},
+			RequestBody: jsonBody(ref("ReportsResolution")),
+			Responses: withErrors(map[string]*Response{
+				"200": {Description: "Resolved"},
+			}, "400", "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/admin/stats", &PathItem{
+		Get: &Operation{
+			OperationID: "getSystemStats",
+			Summary:     "Sum up usage across every account",
+			Tags:        []string{"admin"},
+			Security:    cookieAuth(),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("System stats", ref("SystemStats")),
+			}, "401", "403"),
+		},
+	})
+}
+
+func addReportPaths(doc *Document, api apiVersion) {
+	api.add(doc, "/report/{shorten_url}", &PathItem{
+		Post: &Operation{
+			OperationID: "reportURL",
+			Summary:     "Report shorten url as abusive, suspending it once enough visitors did",
+			Tags:        []string{"report"},
+			Parameters:  []Parameter{pathParam("shorten_url")},
+			RequestBody: jsonBody(ref("ReportRequest")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Report received", ref("Message")),
+			}, "400", "404", "429"),
+		},
+	})
+}
+
+func addWorkspacePaths(doc *Document, api apiVersion) {
+	api.add(doc, "/workspaces/", &PathItem{
+		Get: &Operation{
+			OperationID: "listWorkspaces",
+			Summary:     "List workspaces user is a member of",
+			Tags:        []string{"workspace"},
+			Security:    cookieAuth(),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Workspaces with role of user", ref("Workspaces")),
+			}, "401"),
+		},
+		Post: &Operation{
+			OperationID: "createWorkspace",
+			Summary:     "Create workspace owned by user",
+			Tags:        []string{"workspace"},
+			Security:    cookieAuth(),
+			RequestBody: jsonBody(ref("WorkspaceCreation")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Created workspace", ref("Workspace")),
+			}, "400", "401"),
+		},
+	})
+
+	api.add(doc, "/workspaces/{workspace_id}/members", &PathItem{
+		Get: &Operation{
+			OperationID: "listMembers",
+			Summary:     "List members of workspace",
+			Tags:        []string{"workspace"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("workspace_id")},
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Members with their roles", ref("Members")),
+			}, "401", "404"),
+		},
+	})
+
+	api.add(doc, "/workspaces/{workspace_id}/members/{user_id}", &PathItem{
+		Patch: &Operation{
+			OperationID: "updateMember",
+			Summary:     "Change role of member",
+			Tags:        []string{"workspace"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("workspace_id"), pathParam("user_id")},
+			RequestBody: jsonBody(ref("MemberUpdate")),
+			Responses: withErrors(map[string]*Response{
+				"200": {Description: "Updated"},
+			}, "400", "401", "403", "404"),
+		},
+		Delete: &Operation{
+			OperationID: "removeMember",
+			Summary:     "Remove member, or leave workspace",
+			Tags:        []string{"workspace"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("workspace_id"), pathParam("user_id")},
+			Responses: withErrors(map[string]*Response{
+				"200": {Description: "Removed"},
+			}, "400", "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/workspaces/{workspace_id}/invitations", &PathItem{
+		Post: &Operation{
+			OperationID: "inviteMember",
+			Summary:     "Invite by email to join workspace",
+			Tags:        []string{"workspace"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("workspace_id")},
+			RequestBody: jsonBody(ref("InvitationRequest")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Invitation, token included only when email delivery is disabled", ref("Invitation")),
+			}, "400", "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/workspaces/{workspace_id}/domains", &PathItem{
+		Get: &Operation{
+			OperationID: "listDomains",
+			Summary:     "List custom domains of workspace",
+			Tags:        []string{"workspace"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("workspace_id")},
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Domains with their verification records", ref("Domains")),
+			}, "401", "404"),
+		},
+		Post: &Operation{
+			OperationID: "createDomain",
+			Summary:     "Claim custom domain, given to the first workspace verifying its DNS TXT record",
+			Tags:        []string{"workspace"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("workspace_id")},
+			RequestBody: jsonBody(ref("DomainRequest")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Registered domain", ref("Domain")),
+			}, "400", "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/workspaces/{workspace_id}/domains/{hostname}", &PathItem{
+		Delete: &Operation{
+			OperationID: "deleteDomain",
+			Summary:     "Remove custom domain no link uses",
+			Tags:        []string{"workspace"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("workspace_id"), pathParam("hostname")},
+			Responses: withErrors(map[string]*Response{
+				"200": {Description: "Removed"},
+			}, "400", "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/workspaces/{workspace_id}/domains/{hostname}/verify", &PathItem{
+		Post: &Operation{
+			OperationID: "verifyDomain",
+			Summary:     "Check DNS TXT record of custom domain",
+			Tags:        []string{"workspace"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("workspace_id"), pathParam("hostname")},
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Verified domain", ref("Domain")),
+			}, "400", "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/workspaces/{workspace_id}/webhooks", &PathItem{
+		Get: &Operation{
+			OperationID: "listWebhooks",
+			Summary:     "List webhooks of workspace",
+			Tags:        []string{"workspace"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("workspace_id")},
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Webhooks", ref("Webhooks")),
+			}, "401", "403", "404"),
+		},
+		Post: &Operation{
+			OperationID: "createWebhook",
+			Summary: "Register an endpoint receiving link events as json signed with HMAC-SHA256. The X-Webhook-Signature " +
+				"header of deliveries holds sha256= and the hex HMAC of \"<X-Webhook-Timestamp>.<body>\" keyed with the secret",
+			Tags:        []string{"workspace"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("workspace_id")},
+			RequestBody: jsonBody(ref("WebhookCreation")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Created webhook along with its secret", ref("Webhook")),
+			}, "400", "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/workspaces/{workspace_id}/webhooks/{webhook_id}", &PathItem{
+		Patch: &Operation{
+			OperationID: "updateWebhook",
+			Summary:     "Update endpoint or events of webhook, or enable it again once disabled after repeated failures",
+			Tags:        []string{"workspace"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("workspace_id"), pathParam("webhook_id")},
+			RequestBody: jsonBody(ref("WebhookRequest")),
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Updated webhook", ref("Webhook")),
+			}, "400", "401", "403", "404"),
+		},
+		Delete: &Operation{
+			OperationID: "removeWebhook",
+			Summary:     "Remove webhook along with its deliveries",
+			Tags:        []string{"workspace"},
+			Security:    cookieAuth(),
+			Parameters:  []Parameter{pathParam("workspace_id"), pathParam("webhook_id")},
+			Responses: withErrors(map[string]*Response{
+				"200": {Description: "Removed"},
+			}, "401", "403", "404"),
+		},
+	})
+
+	api.add(doc, "/workspaces/{workspace_id}/webhooks/{webhook_id}/deliveries", &PathItem{
+		Get: &Operation{
+			OperationID: "listWebhookDeliveries",
+			Summary:     "List deliveries of webhook, newest first, with the outcome of their last attempt",
+			Tags:        []string{"workspace"},
+			Security:    cookieAuth(),
+			Parameters: []Parameter{
+				pathParam("workspace_id"),
+				pathParam("webhook_id"),
+				queryParam("offset", integer(), false),
+				queryParam("limit", integer(), false),
+			},
+			Responses: withErrors(map[string]*Response{
+				"200": jsonResponse("Webhook deliveries", ref("WebhookDeliveries")),
+			}, "400", "401", "403", "404"),
+		},
+	})
+}
+
+// redirectResponses lists the statuses a url can be set to redirect with.
+func redirectResponses() map[string]*Response {
+	return map[string]*Response{
+		"301": {Description: "Permanent redirect to origin url"},
+		"302": {Description: "Redirect to origin url"},
+		"307": {Description: "Redirect to origin url, the default"},
+		"308": {Description: "Permanent redirect to origin url"},
+	}
+}
+
+func str() *Schema {
+	return &Schema{Type: "string"}
+}
+
+func integer() *Schema {
+	return &Schema{Type: "integer"}
+}
+
+func boolean() *Schema {
+	return &Schema{Type: "boolean"}
+}
+
+func dateTime() *Schema {
+	return &Schema{Type: "string", Format: "date-time"}
+}
+
+func enum(values ...interface{}) *Schema {
+	return &Schema{Type: "string", Enum: values}
+}
+
+func integerEnum(values ...interface{}) *Schema {
+	return &Schema{Type: "integer", Enum: values}
+}
+
+func array(items *Schema) *Schema {
+	return &Schema{Type: "array", Items: items}
+}
+
+func object(properties map[string]*Schema, required ...string) *Schema {
+	return &Schema{Type: "object", Properties: properties, Required: required}
+}
+
+func allOf(schemas ...*Schema) *Schema {
+	return &Schema{AllOf: schemas}
+}
+
+func maxLength(schema *Schema, max int) *Schema {
+	schema.MaxLength = &max
+	return schema
+}
+
+func maxItems(schema *Schema, max int) *Schema {
+	schema.MaxItems = &max
+	return schema
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func pathParam(name string) Parameter {
+	return Parameter{Name: name, In: "path", Required: true, Schema: str()}
+}
+
+func queryParam(name string, schema *Schema, required bool) Parameter {
+	return Parameter{Name: name, In: "query", Required: required, Schema: schema}
+}
+
+func jsonBody(schema *Schema) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content: map[string]*MediaType{
+			"application/json": {Schema: schema},
+		},
+	}
+}
+
+func jsonResponse(description string, schema *Schema) *Response {
+	return &Response{
+		Description: description,
+		Content: map[string]*MediaType{
+			"application/json": {Schema: schema},
+		},
+	}
+}
+
+func cookieAuth() []map[string][]string {
+	return []map[string][]string{{"cookieAuth": {}}}
+}
+
+// withErrors adds the error envelope response for each given status to responses.
+func withErrors(responses map[string]*Response, statuses ...string) map[string]*Response {
+	for _, status := range append(statuses, "500") {
+		responses[status] = jsonResponse("Error", ref("Error"))
+	}
+	return responses
+}
@@ -0,0 +1,221 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError describes a request field not conforming to the document.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// ErrInvalidJSON indicates the request body could not be decoded at all.
+type ErrInvalidJSON struct {
+	err error
+}
+
+func (e ErrInvalidJSON) Error() string {
+	return "invalid json body: " + e.err.Error()
+}
+
+// Request carries the parts of an incoming request relevant for validation.
+type Request struct {
+	PathParams map[string]string
+	Query      url.Values
+	Header     map[string][]string
+	Body       []byte
+}
+
+// ValidateRequest checks parameters and JSON body of req against op. A non-nil error means the body is not json.
+func (d *Document) ValidateRequest(op *Operation, req Request) ([]ValidationError, error) {
+	var errs []ValidationError
+
+	for _, param := range op.Parameters {
+		var values []string
+		switch param.In {
+		case "path":
+			if v, ok := req.PathParams[param.Name]; ok {
+				values = []string{v}
+			}
+		case "query":
+			values = req.Query[param.Name]
+		case "header":
+			for key, v := range req.Header {
+				if strings.EqualFold(key, param.Name) {
+					values = v
+				}
+			}
+		default:
+			continue
+		}
+
+		if len(values) == 0 || values[0] == "" {
+			if param.Required {
+				errs = append(errs, ValidationError{Field: param.Name, Message: "is required"})
+			}
+			continue
+		}
+
+		value, ok := coerceParameter(d.ResolveSchema(param.Schema), values[0])
+		if !ok {
+			errs = append(errs, ValidationError{Field: param.Name, Message: fmt.Sprintf("must be of type %v", param.Schema.Type)})
+			continue
+		}
+		errs = append(errs, d.validateValue(param.Schema, value, param.Name)...)
+	}
+
+	if op.RequestBody == nil {
+		return errs, nil
+	}
+	media, ok := op.RequestBody.Content["application/json"]
+	if !ok {
+		return errs, nil
+	}
+
+	if len(strings.TrimSpace(string(req.Body))) == 0 {
+		if op.RequestBody.Required {
+			errs = append(errs, ValidationError{Field: "body", Message: "is required"})
+		}
+		return errs, nil
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(req.Body, &body); err != nil {
+		return errs, ErrInvalidJSON{err: err}
+	}
+
+	return append(errs, d.validateValue(media.Schema, body, "")...), nil
+}
+
+func coerceParameter(schema *Schema, raw string) (interface{}, bool) {
+	if schema == nil {
+		return raw, true
+	}
+	switch schema.Type {
+	case "integer":
+		n, err := strconv.ParseInt(raw, 10, 64)
+		return float64(n), err == nil
+	case "number":
+		n, err := strconv.ParseFloat(raw, 64)
+		return n, err == nil
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		return b, err == nil
+	}
+	return raw, true
+}
+
+func joinField(parent string, child string) string {
+	if parent == "" {
+		return child
+	}
+	return parent + "." + child
+}
+
+func (d *Document) validateValue(schema *Schema, value interface{}, field string) []ValidationError {
+	schema = d.ResolveSchema(schema)
+	if schema == nil {
+		return nil
+	}
+	name := field
+	if name == "" {
+		name = "body"
+	}
+
+	if !matchesType(schema.Type, value) {
+		return []ValidationError{{Field: name, Message: fmt.Sprintf("must be of type %v", schema.Type)}}
+	}
+
+	var errs []ValidationError
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		errs = append(errs, ValidationError{Field: name, Message: fmt.Sprintf("must be one of %v", schema.Enum)})
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			errs = append(errs, ValidationError{Field: name, Message: fmt.Sprintf("must be at least %v characters", *schema.MinLength)})
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			errs = append(errs, ValidationError{Field: name, Message: fmt.Sprintf("must be at most %v characters", *schema.MaxLength)})
+		}
+		if schema.Pattern != "" {
+			if re, err := regexp.Compile(schema.Pattern); err == nil && !re.MatchString(v) {
+				errs = append(errs, ValidationError{Field: name, Message: fmt.Sprintf("must match pattern %v", schema.Pattern)})
+			}
+		}
+	case float64:
+		if schema.Minimum != nil && v < *schema.Minimum {
+			errs = append(errs, ValidationError{Field: name, Message: fmt.Sprintf("must be at least %v", *schema.Minimum)})
+		}
+		if schema.Maximum != nil && v > *schema.Maximum {
+			errs = append(errs, ValidationError{Field: name, Message: fmt.Sprintf("must be at most %v", *schema.Maximum)})
+		}
+	case []interface{}:
+		if schema.MaxItems != nil && len(v) > *schema.MaxItems {
+			errs = append(errs, ValidationError{Field: name, Message: fmt.Sprintf("must have at most %v items", *schema.MaxItems)})
+		}
+		for i, item := range v {
+			errs = append(errs, d.validateValue(schema.Items, item, fmt.Sprintf("%v[%v]", name, i))...)
+		}
+	case map[string]interface{}:
+		for _, required := range schema.Required {
+			if _, ok := v[required]; !ok {
+				errs = append(errs, ValidationError{Field: joinField(field, required), Message: "is required"})
+			}
+		}
+		for key, item := range v {
+			if property, ok := schema.Properties[key]; ok {
+				errs = append(errs, d.validateValue(property, item, joinField(field, key))...)
+			} else if schema.AdditionalProperties != nil {
+				errs = append(errs, d.validateValue(schema.AdditionalProperties, item, joinField(field, key))...)
+			}
+		}
+	}
+
+	return errs
+}
+
+func matchesType(t string, value interface{}) bool {
+	switch t {
+	case "":
+		return true
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return false
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if fmt.Sprintf("%v", e) == fmt.Sprintf("%v", value) {
+			return true
+		}
+	}
+	return false
+}
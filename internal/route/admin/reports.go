@@ -0,0 +1,190 @@
+package admin
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+	"url-shortener/internal/cache"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/route/audit"
+	server "url-shortener/internal/route/error"
+)
+
+var (
+	decisionDismiss = "dismiss" // url is legitimate, its suspension is lifted
+	decisionRemove  = "remove"  // url is abusive, it is purged
+)
+
+type ReportsResponse struct {
+	Total   uint64           `json:"total"`
+	Reports []ReportResponse `json:"reports"`
+}
+
+type ReportResponse struct {
+	ID            uint64     `json:"id"`
+	ShortenURL    string     `json:"shorten_url"`
+	Reason        string     `json:"reason"`
+	Details       string     `json:"details,omitempty"`
+	ReporterEmail string     `json:"reporter_email,omitempty"`
+	ReporterIP    string     `json:"reporter_ip"`
+	Status        string     `json:"status"`
+	ReviewedBy    string     `json:"reviewed_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// ResolveReportsRequest settles every open report about a url at once.
+type ResolveReportsRequest struct {
+	Decision string `json:"decision"`
+}
+
+func newReportResponse(report database.Report) ReportResponse {
+	res := ReportResponse{
+		ID:            report.ID,
+		ShortenURL:    report.ShortenURL,
+		Reason:        report.Reason,
+		Details:       report.Details,
+		ReporterEmail: report.ReporterEmail,
+		ReporterIP:    report.ReporterIP,
+		Status:        report.Status,
+		ReviewedBy:    report.ReviewedBy,
+		CreatedAt:     report.CreatedAt,
+	}
+	if !report.ReviewedAt.IsZero() {
+		res.ReviewedAt = &report.ReviewedAt
+	}
+	return res
+}
+
+// GetReportsHandler lists reports with the status in query, open ones by default, the oldest first.
+func GetReportsHandler(context *gin.Context) {
+	logger := logging.FromContext(context)
+
+	status := context.DefaultQuery("status", database.ReportStatusOpen)
+	switch status {
+	case database.ReportStatusOpen, database.ReportStatusDismissed:
+	default:
+		logger.WithField("status", status).Info("Unknown report status")
+		server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+			Field:   "status",
+			Message: "must be one of open, dismissed",
+		}))
+		return
+	}
+
+	var offset, limit uint64 = 0, 100
+	for name, value := range map[string]*uint64{"offset": &offset, "limit": &limit} {
+		param := context.Query(name)
+		if param == "" {
+			continue
+		}
+		parsed, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			logger.WithError(err).WithField(name, param).Info("Unable to decode query parameter")
+			server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+				Field:   name,
+				Message: "must be a non-negative integer",
+			}))
+			return
+		}
+		*value = parsed
+	}
+	if limit < 1 || limit > 100 {
+		limit = 100
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	total, reports, err := db.GetReports(status, offset, limit)
+	if err != nil {
+		logger.WithError(err).Error("Unable to query for reports")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	res := ReportsResponse{Total: total, Reports: make([]ReportResponse, len(reports))}
+	for i, report := range reports {
+		res.Reports[i] = newReportResponse(report)
+	}
+
+	context.JSON(http.StatusOK, res)
+}
+
+// ResolveReportsHandler settles open reports about the url in path. Dismissing them restores a suspended url,
+// removing purges the url along with its reports so that its code is never served again. The decision is kept
+// in the audit log either way.
+func ResolveReportsHandler(context *gin.Context) {
+	shortenURL := context.Param("shorten_url")
+	logger := logging.FromContext(context).WithField("shorten_url", shortenURL)
+
+	r, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		logger.WithError(err).Error("Unable to read body properly")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	var req ResolveReportsRequest
+	if err := json.Unmarshal(r, &req); err != nil {
+		logger.WithError(err).Warn("Unexpected json string")
+		server.Abort(context, server.InvalidJSONStringError)
+		return
+	}
+	switch req.Decision {
+	case decisionDismiss, decisionRemove:
+	default:
+		logger.WithField("decision", req.Decision).Info("Unknown decision")
+		server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+			Field:   "decision",
+			Message: "must be one of dismiss, remove",
+		}))
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	url, err := db.GetURLWithShortenURL(shortenURL)
+	if _, ok := err.(database.RecordNotFoundError); ok {
+		url, err = db.GetDeletedURL(shortenURL)
+	}
+	if err != nil {
+		if _, ok := err.(database.RecordNotFoundError); ok {
+			logger.Info("Given url not found in database")
+			server.Abort(context, server.NotFoundError)
+			return
+		}
+		logger.WithError(err).Error("Error occurred when querying for url")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	admin := context.Value("user").(*database.User)
+	if req.Decision == decisionRemove {
+		if err := db.PurgeURL(shortenURL); err != nil {
+			logger.WithError(err).Error("Unable to purge entity in database")
+			server.Abort(context, server.InternalError)
+			return
+		}
+	} else if err := db.ResolveReports(shortenURL, database.ReportStatusDismissed, *admin, time.Now()); err != nil {
+		logger.WithError(err).Error("Unable to resolve reports")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	cacheService := context.Value("cache-service").(cache.Service)
+	if err := cacheService.DelCachedURL(cache.DomainURL(url.Domain, shortenURL)); err != nil {
+		logger.WithError(err).Warn("Unable to evict cached entity")
+	}
+
+	audit.Record(context, admin.UserID, database.AuditActionReportsResolve, database.AuditTargetURL, shortenURL, gin.H{
+		"origin_url": url.OriginURL,
+		"workspace":  url.Owner,
+		"suspended":  !url.SuspendedAt.IsZero(),
+	}, gin.H{
+		"decision": req.Decision,
+	})
+
+	context.Status(http.StatusOK)
+}
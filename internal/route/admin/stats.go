@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	server "url-shortener/internal/route/error"
+)
+
+type StatsResponse struct {
+	Users         uint64 `json:"users"`
+	DisabledUsers uint64 `json:"disabled_users"`
+	Workspaces    uint64 `json:"workspaces"`
+	URLs          uint64 `json:"urls"`
+	DeletedURLs   uint64 `json:"deleted_urls"`
+	Hits          int64  `json:"hits"`
+	Domains       uint64 `json:"domains"`
+	Pages         uint64 `json:"pages"`
+}
+
+// GetStatsHandler sums up usage across every account.
+func GetStatsHandler(context *gin.Context) {
+	logger := logging.FromContext(context)
+
+	db := context.Value("db").(database.MySQLService)
+	stats, err := db.GetSystemStats()
+	if err != nil {
+		logger.WithError(err).Error("Unable to query for system stats")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	context.JSON(http.StatusOK, StatsResponse{
+		Users:         stats.Users,
+		DisabledUsers: stats.DisabledUsers,
+		Workspaces:    stats.Workspaces,
+		URLs:          stats.URLs,
+		DeletedURLs:   stats.DeletedURLs,
+		Hits:          stats.Hits,
+		Domains:       stats.Domains,
+		Pages:         stats.Pages,
+	})
+}
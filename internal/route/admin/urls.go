@@ -0,0 +1,54 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"url-shortener/internal/cache"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/route/audit"
+	server "url-shortener/internal/route/error"
+)
+
+// PurgeURLHandler permanently removes an abusive url right away, skipping the trash. Its code is never issued again.
+func PurgeURLHandler(context *gin.Context) {
+	shortenURL := context.Param("shorten_url")
+	logger := logging.FromContext(context).WithField("shorten_url", shortenURL)
+
+	db := context.Value("db").(database.MySQLService)
+	url, err := db.GetURLWithShortenURL(shortenURL)
+	if _, ok := err.(database.RecordNotFoundError); ok {
+		url, err = db.GetDeletedURL(shortenURL)
+	}
+	if err != nil {
+		if _, ok := err.(database.RecordNotFoundError); ok {
+			logger.Info("Given url not found in database")
+			server.Abort(context, server.NotFoundError)
+			return
+		}
+		logger.WithError(err).Error("Error occurred when querying for url")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	if err := db.PurgeURL(shortenURL); err != nil {
+		logger.WithError(err).Error("Unable to purge entity in database")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	cacheService := context.Value("cache-service").(cache.Service)
+	if err := cacheService.DelCachedURL(cache.DomainURL(url.Domain, shortenURL)); err != nil {
+		logger.WithError(err).Warn("Unable to evict cached entity")
+	}
+
+	admin := context.Value("user").(*database.User)
+	audit.Record(context, admin.UserID, database.AuditActionURLPurge, database.AuditTargetURL, shortenURL, gin.H{
+		"origin_url": url.OriginURL,
+		"workspace":  url.Owner,
+		"domain":     url.Domain,
+		"hits":       url.Count,
+	}, nil)
+
+	context.Status(http.StatusOK)
+}
@@ -0,0 +1,233 @@
+package admin
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/route/audit"
+	server "url-shortener/internal/route/error"
+)
+
+type UsersResponse struct {
+	Total uint64         `json:"total"`
+	Users []UserResponse `json:"users"`
+}
+
+type UserResponse struct {
+	UserID   string `json:"user_id"`
+	Email    string `json:"email"`
+	Type     string `json:"type"`
+	Role     string `json:"role"`
+	Disabled bool   `json:"disabled"`
+}
+
+// UserRequest changes the role of a user or disables the account, omitted fields are left untouched.
+type UserRequest struct {
+	Role     *string `json:"role"`
+	Disabled *bool   `json:"disabled"`
+}
+
+type TransferRequest struct {
+	To string `json:"to"` // id of user receiving the urls
+}
+
+type TransferResponse struct {
+	Transferred uint64 `json:"transferred"`
+}
+
+func newUserResponse(user database.User) UserResponse {
+	return UserResponse{
+		UserID:   user.UserID,
+		Email:    user.Email,
+		Type:     user.Type,
+		Role:     user.Role,
+		Disabled: user.Disabled,
+	}
+}
+
+// getUser returns user with given id, aborting if there is none.
+func getUser(context *gin.Context, userID string) (*database.User, bool) {
+	logger := logging.FromContext(context).WithField("target_user_id", userID)
+
+	db := context.Value("db").(database.MySQLService)
+	user, err := db.GetUserWithID(userID)
+	if err != nil {
+		if _, ok := err.(database.RecordNotFoundError); ok {
+			logger.Info("User not found in database")
+			server.Abort(context, server.NotFoundError)
+			return nil, false
+		}
+		logger.WithError(err).Error("Unable to query for user")
+		server.Abort(context, server.InternalError)
+		return nil, false
+	}
+
+	return user, true
+}
+
+// GetUsersHandler lists every user by email, optionally searched by part of the email.
+func GetUsersHandler(context *gin.Context) {
+	logger := logging.FromContext(context)
+
+	query := database.UserQuery{
+		Search: strings.TrimSpace(context.Query("q")),
+		Limit:  100,
+	}
+	for name, value := range map[string]*uint64{"offset": &query.Offset, "limit": &query.Limit} {
+		param := context.Query(name)
+		if param == "" {
+			continue
+		}
+		parsed, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			logger.WithError(err).WithField(name, param).Info("Unable to decode query parameter")
+			server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+				Field:   name,
+				Message: "must be a non-negative integer",
+			}))
+			return
+		}
+		*value = parsed
+	}
+	if query.Limit < 1 || query.Limit > 100 {
+		query.Limit = 100
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	total, users, err := db.GetUsers(query)
+	if err != nil {
+		logger.WithError(err).Error("Unable to query for users")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	res := UsersResponse{Total: total, Users: make([]UserResponse, len(users))}
+	for i, user := range users {
+		res.Users[i] = newUserResponse(user)
+	}
+
+	context.JSON(http.StatusOK, res)
+}
+
+// UpdateUserHandler changes the role of a user or disables the account. Admins cannot do so to themselves,
+// so that there is always someone left to undo it.
+func UpdateUserHandler(context *gin.Context) {
+	userID := context.Param("user_id")
+	logger := logging.FromContext(context).WithField("target_user_id", userID)
+
+	r, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		logger.WithError(err).Error("Unable to read body properly")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	var req UserRequest
+	if err := json.Unmarshal(r, &req); err != nil {
+		logger.WithError(err).Warn("Unexpected json string")
+		server.Abort(context, server.InvalidJSONStringError)
+		return
+	}
+	if req.Role != nil && *req.Role != database.UserRoleUser && *req.Role != database.UserRoleAdmin {
+		logger.WithField("role", *req.Role).Info("Unknown role")
+		server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+			Field:   "role",
+			Message: "must be one of user, admin",
+		}))
+		return
+	}
+
+	admin := context.Value("user").(*database.User)
+	if userID == admin.UserID {
+		logger.Info("Refused to administer oneself")
+		server.Abort(context, server.RequestError.WithMessage("Admins cannot change their own role or account"))
+		return
+	}
+
+	stored, ok := getUser(context, userID)
+	if !ok {
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	updated := *stored
+	if req.Role != nil {
+		if err := db.SetUserRole(userID, *req.Role); err != nil {
+			logger.WithError(err).Error("Unable to update role of user")
+			server.Abort(context, server.InternalError)
+			return
+		}
+		updated.Role = *req.Role
+	}
+	if req.Disabled != nil {
+		if err := db.SetUserDisabled(userID, *req.Disabled); err != nil {
+			logger.WithError(err).Error("Unable to update account of user")
+			server.Abort(context, server.InternalError)
+			return
+		}
+		updated.Disabled = *req.Disabled
+	}
+
+	audit.Record(context, admin.UserID, database.AuditActionUserAdminister, database.AuditTargetUser, userID,
+		newUserResponse(*stored), newUserResponse(updated))
+
+	context.JSON(http.StatusOK, newUserResponse(updated))
+}
+
+// TransferURLsHandler moves urls of the personal workspace of a user to the personal workspace of another one.
+// Urls on custom domains are left in place, as the domains belong to the workspace.
+func TransferURLsHandler(context *gin.Context) {
+	userID := context.Param("user_id")
+	logger := logging.FromContext(context).WithField("target_user_id", userID)
+
+	r, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		logger.WithError(err).Error("Unable to read body properly")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	var req TransferRequest
+	if err := json.Unmarshal(r, &req); err != nil {
+		logger.WithError(err).Warn("Unexpected json string")
+		server.Abort(context, server.InvalidJSONStringError)
+		return
+	}
+	req.To = strings.TrimSpace(req.To)
+	if req.To == "" || req.To == userID {
+		logger.WithField("to", req.To).Info("Invalid transfer recipient")
+		server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+			Field:   "to",
+			Message: "must be the id of another user",
+		}))
+		return
+	}
+
+	if _, ok := getUser(context, userID); !ok {
+		return
+	}
+	if _, ok := getUser(context, req.To); !ok {
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	transferred, err := db.TransferURLs(userID, req.To)
+	if err != nil {
+		logger.WithError(err).Error("Unable to transfer urls")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	admin := context.Value("user").(*database.User)
+	audit.Record(context, admin.UserID, database.AuditActionURLsTransfer, database.AuditTargetUser, userID, nil, gin.H{
+		"to":          req.To,
+		"transferred": transferred,
+	})
+
+	context.JSON(http.StatusOK, TransferResponse{Transferred: transferred})
+}
@@ -0,0 +1,159 @@
+package audit
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	server "url-shortener/internal/route/error"
+)
+
+type AuditEntriesResponse struct {
+	Entries    []AuditEntryResponse `json:"entries"`
+	NextBefore uint64               `json:"next_before,omitempty"` // pass as before to get older entries
+}
+
+type AuditEntryResponse struct {
+	ID         uint64          `json:"id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+const maxAuditEntries = 100
+
+// Record appends an entry about actor acting on target to the audit log, along with where the request came from.
+// before and after are snapshots of target marshalled as json, nil if there is none.
+// An entry failing to be stored is logged and lost, the request it is about still succeeds.
+func Record(context *gin.Context, actorID string, action string, targetType string, targetID string, before interface{}, after interface{}) {
+	logger := logging.FromContext(context).WithField("action", action)
+
+	entry := database.AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		IP:         context.GetString("client-ip"), // resolved by middleware.ClientAddress, not forgeable through X-Forwarded-For
+		UserAgent:  context.Request.UserAgent(),
+	}
+	var err error
+	if entry.Before, err = snapshot(before); err != nil {
+		logger.WithError(err).Warn("Unable to encode audit snapshot")
+	}
+	if entry.After, err = snapshot(after); err != nil {
+		logger.WithError(err).Warn("Unable to encode audit snapshot")
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	if err := db.CreateAuditEntry(entry); err != nil {
+		logger.WithError(err).Error("Unable to record audit entry")
+	}
+}
+
+func snapshot(v interface{}) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func newAuditEntryResponse(entry database.AuditEntry) AuditEntryResponse {
+	res := AuditEntryResponse{
+		ID:         entry.ID,
+		Actor:      entry.ActorID,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		IP:         entry.IP,
+		UserAgent:  entry.UserAgent,
+		CreatedAt:  entry.CreatedAt,
+	}
+	if entry.Before != "" {
+		res.Before = json.RawMessage(entry.Before)
+	}
+	if entry.After != "" {
+		res.After = json.RawMessage(entry.After)
+	}
+	return res
+}
+
+// GetAuditTrailHandler lists changes made by user, newest first.
+func GetAuditTrailHandler(context *gin.Context) {
+	user := context.Value("user").(*database.User)
+	respondAuditEntries(context, user.UserID)
+}
+
+// GetAllAuditEntriesHandler lists changes made by every user, newest first, optionally narrowed to an actor.
+func GetAllAuditEntriesHandler(context *gin.Context) {
+	respondAuditEntries(context, strings.TrimSpace(context.Query("actor")))
+}
+
+func respondAuditEntries(context *gin.Context, actorID string) {
+	logger := logging.FromContext(context)
+
+	query := database.AuditQuery{
+		ActorID:  actorID,
+		Action:   strings.TrimSpace(context.Query("action")),
+		TargetID: strings.TrimSpace(context.Query("target")),
+		Limit:    maxAuditEntries,
+	}
+
+	if paramBefore := context.Query("before"); paramBefore != "" {
+		before, err := strconv.ParseUint(paramBefore, 10, 64)
+		if err != nil {
+			logger.WithError(err).WithField("before", paramBefore).Info("Unable to decode query parameter before")
+			server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+				Field:   "before",
+				Message: "must be a non-negative integer",
+			}))
+			return
+		}
+		query.BeforeID = before
+	}
+	if paramLimit := context.Query("limit"); paramLimit != "" {
+		limit, err := strconv.ParseUint(paramLimit, 10, 64)
+		if err != nil {
+			logger.WithError(err).WithField("limit", paramLimit).Info("Unable to decode query parameter limit")
+			server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+				Field:   "limit",
+				Message: "must be a non-negative integer",
+			}))
+			return
+		}
+		if limit >= 1 && limit <= maxAuditEntries {
+			query.Limit = limit
+		}
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	entries, err := db.GetAuditEntries(query)
+	if err != nil {
+		logger.WithError(err).Error("Unable to query for audit entries")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	res := AuditEntriesResponse{Entries: make([]AuditEntryResponse, len(entries))}
+	for i, entry := range entries {
+		res.Entries[i] = newAuditEntryResponse(entry)
+	}
+	if uint64(len(entries)) == query.Limit {
+		res.NextBefore = entries[len(entries)-1].ID
+	}
+
+	context.JSON(http.StatusOK, res)
+}
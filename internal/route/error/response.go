@@ -1,19 +1,74 @@
 package error
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// APIError is the error model returned by every API. Code is stable and meant for machines,
+// Message is meant for humans and may change.
+type APIError struct {
+	Status  int          `json:"-"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+// FieldError describes a validation failure of a single request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e APIError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// WithDetails returns a copy of e carrying field-level validation details.
+func (e APIError) WithDetails(details ...FieldError) APIError {
+	e.Details = append(append([]FieldError{}, e.Details...), details...)
+	return e
+}
+
+// WithMessage returns a copy of e with a more specific human-readable message.
+func (e APIError) WithMessage(message string) APIError {
+	e.Message = message
+	return e
+}
+
+func newAPIError(status int, code string, message string) APIError {
+	return APIError{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
 
 var (
-	InvalidJSONStringError  = "Invalid json string"
-	AuthenticationError     = "Authentication failed"
-	EmailValidationError    = "Email validation failed"
-	PasswordValidationError = "Password validation failed"
-	CodeValidationError     = "Code validation failed"
-	AlreadyRegisteredError  = "Already registered"
-	RequestError            = "Invalid request"
+	InvalidJSONStringError  = newAPIError(http.StatusBadRequest, "invalid_json", "Invalid json string")
+	AuthenticationError     = newAPIError(http.StatusUnauthorized, "authentication_failed", "Authentication failed")
+	CredentialsError        = newAPIError(http.StatusBadRequest, "invalid_credentials", "Authentication failed")
+	EmailValidationError    = newAPIError(http.StatusBadRequest, "invalid_email", "Email validation failed")
+	PasswordValidationError = newAPIError(http.StatusBadRequest, "invalid_password", "Password validation failed")
+	CodeValidationError     = newAPIError(http.StatusBadRequest, "invalid_verification_code", "Code validation failed")
+	AlreadyRegisteredError  = newAPIError(http.StatusBadRequest, "already_registered", "Already registered")
+	RequestError            = newAPIError(http.StatusBadRequest, "invalid_request", "Invalid request")
+	ValidationError         = newAPIError(http.StatusBadRequest, "validation_failed", "Request validation failed")
+	ForbiddenError          = newAPIError(http.StatusForbidden, "forbidden", "Not permitted")
+	AccountDisabledError    = newAPIError(http.StatusForbidden, "account_disabled", "Account disabled")
+	NotFoundError           = newAPIError(http.StatusNotFound, "not_found", "Resource not found")
+	TooManyRequestsError    = newAPIError(http.StatusTooManyRequests, "too_many_requests", "Too many requests")
+	InternalError           = newAPIError(http.StatusInternalServerError, "internal_error", "Internal server error")
 )
 
-func NewResponseErrorWithMessage(error string) gin.H {
+// NewResponseError returns the JSON envelope for given error.
+func NewResponseError(err APIError) gin.H {
 	return gin.H{
-		"error": error,
+		"error": err,
 	}
 }
+
+// Abort stops the handler chain, responding with the status and envelope of given error.
+func Abort(context *gin.Context, err APIError) {
+	context.AbortWithStatusJSON(err.Status, NewResponseError(err))
+}
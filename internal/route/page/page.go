@@ -0,0 +1,374 @@
+package page
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	url2 "net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	server "url-shortener/internal/route/error"
+	"url-shortener/internal/route/workspace"
+)
+
+const (
+	maxTitleLength       = 100
+	maxDescriptionLength = 500
+	maxAvatarURLLength   = 2048
+	maxLinks             = 50
+	maxLabelLength       = 100
+)
+
+var slugPattern = regexp.MustCompile("^[a-z0-9][a-z0-9-]{1,38}[a-z0-9]$")
+
+// PageRequest describes a page. Slug and workspace are only read on creation, the other fields replace the stored ones.
+type PageRequest struct {
+	Slug        string            `json:"slug"`
+	Workspace   string            `json:"workspace"` // defaults to personal workspace of user
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	AvatarURL   string            `json:"avatar_url"`
+	Theme       string            `json:"theme"` // defaults to light
+	Links       []PageLinkRequest `json:"links"`
+}
+
+type PageLinkRequest struct {
+	ShortenURL string `json:"shorten_url"`
+	Label      string `json:"label"`
+}
+
+type PageResponse struct {
+	Slug        string            `json:"slug"`
+	Workspace   string            `json:"workspace"`
+	CreatedBy   string            `json:"created_by"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	AvatarURL   string            `json:"avatar_url"`
+	Theme       string            `json:"theme"`
+	Links       []PageLinkRequest `json:"links,omitempty"` // omitted in lists
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+type PagesResponse struct {
+	Pages []PageResponse `json:"pages"`
+}
+
+func newPageResponse(p database.Page) PageResponse {
+	links := make([]PageLinkRequest, len(p.Links))
+	for i, link := range p.Links {
+		links[i] = PageLinkRequest{ShortenURL: link.ShortenURL, Label: link.Label}
+	}
+	return PageResponse{
+		Slug:        p.Slug,
+		Workspace:   p.WorkspaceID,
+		CreatedBy:   p.CreatedBy,
+		Title:       p.Title,
+		Description: p.Description,
+		AvatarURL:   p.AvatarURL,
+		Theme:       p.Theme,
+		Links:       links,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+func isTheme(theme string) bool {
+	for _, t := range database.PageThemes {
+		if t == theme {
+			return true
+		}
+	}
+	return false
+}
+
+// validate normalizes the request in place and returns every field violating the limits, the slug excluded.
+func (r *PageRequest) validate() []server.FieldError {
+	var errs []server.FieldError
+	checkLength := func(field string, value *string, max int) {
+		*value = strings.TrimSpace(*value)
+		if utf8.RuneCountInString(*value) > max {
+			errs = append(errs, server.FieldError{Field: field, Message: fmt.Sprintf("must be at most %v characters", max)})
+		}
+	}
+	checkLength("title", &r.Title, maxTitleLength)
+	checkLength("description", &r.Description, maxDescriptionLength)
+	checkLength("avatar_url", &r.AvatarURL, maxAvatarURLLength)
+
+	if r.AvatarURL != "" {
+		if u, err := url2.Parse(r.AvatarURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, server.FieldError{Field: "avatar_url", Message: "must be a valid http or https url"})
+		}
+	}
+
+	r.Theme = strings.ToLower(strings.TrimSpace(r.Theme))
+	if r.Theme == "" {
+		r.Theme = database.PageThemeLight
+	}
+	if !isTheme(r.Theme) {
+		errs = append(errs, server.FieldError{Field: "theme", Message: "must be one of " + strings.Join(database.PageThemes, ", ")})
+	}
+
+	if len(r.Links) > maxLinks {
+		errs = append(errs, server.FieldError{Field: "links", Message: fmt.Sprintf("must have at most %v items", maxLinks)})
+	}
+	for i := range r.Links {
+		r.Links[i].ShortenURL = strings.TrimSpace(r.Links[i].ShortenURL)
+		if r.Links[i].ShortenURL == "" {
+			errs = append(errs, server.FieldError{Field: fmt.Sprintf("links[%v].shorten_url", i), Message: "must not be empty"})
+		}
+		checkLength(fmt.Sprintf("links[%v].label", i), &r.Links[i].Label, maxLabelLength)
+	}
+
+	return errs
+}
+
+// readPageRequest decodes and validates the body, aborting if it is not a valid page.
+func readPageRequest(context *gin.Context, logger *logrus.Entry) (*PageRequest, bool) {
+	r, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		logger.WithError(err).Error("Unable to read body properly")
+		server.Abort(context, server.InternalError)
+		return nil, false
+	}
+
+	var req PageRequest
+	if err := json.Unmarshal(r, &req); err != nil {
+		logger.WithError(err).Warn("Unexpected json string")
+		server.Abort(context, server.InvalidJSONStringError)
+		return nil, false
+	}
+	if errs := req.validate(); len(errs) > 0 {
+		logger.Info("Invalid page")
+		server.Abort(context, server.ValidationError.WithDetails(errs...))
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// pageLinks converts links of req, aborting unless each of them is a url of workspace.
+func pageLinks(context *gin.Context, logger *logrus.Entry, workspaceID string, req *PageRequest) ([]database.PageLink, bool) {
+	shortenURLs := make([]string, len(req.Links))
+	for i, link := range req.Links {
+		shortenURLs[i] = link.ShortenURL
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	urls, err := db.GetURLsWithShortenURLs(shortenURLs)
+	if err != nil {
+		logger.WithError(err).Error("Unable to query for urls of page")
+		server.Abort(context, server.InternalError)
+		return nil, false
+	}
+	owned := map[string]bool{}
+	for _, url := range urls {
+		owned[url.ShortenURL] = url.Owner == workspaceID
+	}
+
+	var errs []server.FieldError
+	links := make([]database.PageLink, len(req.Links))
+	for i, link := range req.Links {
+		if !owned[link.ShortenURL] {
+			errs = append(errs, server.FieldError{Field: fmt.Sprintf("links[%v].shorten_url", i), Message: "must be a url of workspace"})
+		}
+		links[i] = database.PageLink{ShortenURL: link.ShortenURL, Label: link.Label}
+	}
+	if len(errs) > 0 {
+		logger.Info("Page links to urls out of workspace")
+		server.Abort(context, server.ValidationError.WithDetails(errs...))
+		return nil, false
+	}
+
+	return links, true
+}
+
+// getWorkspacePage queries page given in path, aborting unless user holds required role in its workspace.
+func getWorkspacePage(context *gin.Context, logger *logrus.Entry, required string) (*database.Page, bool) {
+	db := context.Value("db").(database.MySQLService)
+	p, err := db.GetPage(context.Param("slug"))
+	if err != nil {
+		if _, ok := err.(database.RecordNotFoundError); ok {
+			logger.Info("Page not found")
+			server.Abort(context, server.NotFoundError)
+			return nil, false
+		}
+		logger.WithError(err).Error("Unable to query for page")
+		server.Abort(context, server.InternalError)
+		return nil, false
+	}
+	if !workspace.Authorize(context, logger, p.WorkspaceID, required) {
+		return nil, false
+	}
+
+	return p, true
+}
+
+func GetPagesHandler(context *gin.Context) {
+	workspaceID := workspace.FromQuery(context)
+	logger := logging.FromContext(context).WithField("workspace_id", workspaceID)
+	if !workspace.Authorize(context, logger, workspaceID, database.WorkspaceRoleViewer) {
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	pages, err := db.GetPagesInWorkspace(workspaceID)
+	if err != nil {
+		logger.WithError(err).Error("Unable to query for pages of workspace")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	resPages := make([]PageResponse, len(pages))
+	for i, p := range pages {
+		resPages[i] = newPageResponse(p)
+	}
+
+	context.JSON(http.StatusOK, PagesResponse{Pages: resPages})
+}
+
+// CreatePageHandler publishes a page of links at a slug not taken yet, in a workspace user is an editor of.
+func CreatePageHandler(context *gin.Context) {
+	logger := logging.FromContext(context)
+
+	req, ok := readPageRequest(context, logger)
+	if !ok {
+		return
+	}
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if !slugPattern.MatchString(slug) {
+		logger.WithField("slug", slug).Info("Invalid page slug")
+		server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+			Field:   "slug",
+			Message: "must be 3 to 40 lower case letters, digits or inner hyphens",
+		}))
+		return
+	}
+	logger = logger.WithField("slug", slug)
+
+	user := context.Value("user").(*database.User)
+	workspaceID := strings.TrimSpace(req.Workspace)
+	if workspaceID == "" {
+		workspaceID = user.UserID
+	}
+	if !workspace.Authorize(context, logger, workspaceID, database.WorkspaceRoleEditor) {
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	if _, err := db.GetPage(slug); err == nil {
+		logger.Info("Page slug already taken")
+		server.Abort(context, server.RequestError.WithMessage("Slug is already taken"))
+		return
+	} else if _, ok := err.(database.RecordNotFoundError); !ok {
+		logger.WithError(err).Error("Unable to query for page")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	links, ok := pageLinks(context, logger, workspaceID, req)
+	if !ok {
+		return
+	}
+
+	p := database.Page{
+		Slug:        slug,
+		WorkspaceID: workspaceID,
+		CreatedBy:   user.UserID,
+		Title:       req.Title,
+		Description: req.Description,
+		AvatarURL:   req.AvatarURL,
+		Theme:       req.Theme,
+		Links:       links,
+	}
+	if err := db.CreatePage(p); err != nil {
+		logger.WithError(err).Error("Unable to create page")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	stored, err := db.GetPage(slug)
+	if err != nil {
+		logger.WithError(err).Error("Error occurred when querying for created page")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	context.JSON(http.StatusOK, newPageResponse(*stored))
+}
+
+func GetPageHandler(context *gin.Context) {
+	logger := logging.FromContext(context).WithField("slug", context.Param("slug"))
+
+	p, ok := getWorkspacePage(context, logger, database.WorkspaceRoleViewer)
+	if !ok {
+		return
+	}
+
+	context.JSON(http.StatusOK, newPageResponse(*p))
+}
+
+// UpdatePageHandler replaces content of a page in a workspace user is an editor of.
+func UpdatePageHandler(context *gin.Context) {
+	logger := logging.FromContext(context).WithField("slug", context.Param("slug"))
+
+	req, ok := readPageRequest(context, logger)
+	if !ok {
+		return
+	}
+	p, ok := getWorkspacePage(context, logger, database.WorkspaceRoleEditor)
+	if !ok {
+		return
+	}
+	links, ok := pageLinks(context, logger, p.WorkspaceID, req)
+	if !ok {
+		return
+	}
+
+	p.Title = req.Title
+	p.Description = req.Description
+	p.AvatarURL = req.AvatarURL
+	p.Theme = req.Theme
+	p.Links = links
+
+	db := context.Value("db").(database.MySQLService)
+	if err := db.UpdatePage(*p); err != nil {
+		logger.WithError(err).Error("Unable to update page")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	stored, err := db.GetPage(p.Slug)
+	if err != nil {
+		logger.WithError(err).Error("Error occurred when querying for updated page")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	context.JSON(http.StatusOK, newPageResponse(*stored))
+}
+
+func RemovePageHandler(context *gin.Context) {
+	logger := logging.FromContext(context).WithField("slug", context.Param("slug"))
+
+	p, ok := getWorkspacePage(context, logger, database.WorkspaceRoleEditor)
+	if !ok {
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	if err := db.DeletePage(p.Slug); err != nil {
+		logger.WithError(err).Error("Unable to delete page")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	context.Status(http.StatusOK)
+}
@@ -0,0 +1,102 @@
+package page
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	url2 "net/url"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	server "url-shortener/internal/route/error"
+)
+
+const pageTemplate = "page.tmpl"
+
+type renderedLink struct {
+	Title string
+	Href  string
+}
+
+// shortLink prints url as served at the root of its domain, so that visits are counted like any other.
+func shortLink(baseURL string, url database.URL) string {
+	if url.Domain != "" {
+		if u, err := url2.Parse(baseURL); err == nil {
+			baseURL = u.Scheme + "://" + url.Domain
+		}
+	}
+	return baseURL + "/" + url.ShortenURL
+}
+
+// linkTitle is what a link is shown as, its label or else the best known title of url.
+func linkTitle(link database.PageLink, url database.URL) string {
+	switch {
+	case link.Label != "":
+		return link.Label
+	case url.Title != "":
+		return url.Title
+	case url.Metadata.Title != "":
+		return url.Metadata.Title
+	}
+	return url.OriginURL
+}
+
+// RenderPageHandler serves the public page of slug in path. Links point to the short urls under baseURL rather than
+// their destinations. Links to urls deleted or moved out of the workspace since the page was saved are left out.
+func RenderPageHandler(baseURL string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		slug := context.Param("slug")
+		logger := logging.FromContext(context).WithField("slug", slug)
+
+		db := context.Value("db").(database.MySQLService)
+		p, err := db.GetPage(slug)
+		if err != nil {
+			if _, ok := err.(database.RecordNotFoundError); ok {
+				logger.Info("Page not found")
+				server.Abort(context, server.NotFoundError)
+				return
+			}
+			logger.WithError(err).Error("Unable to query for page")
+			server.Abort(context, server.InternalError)
+			return
+		}
+
+		shortenURLs := make([]string, len(p.Links))
+		for i, link := range p.Links {
+			shortenURLs[i] = link.ShortenURL
+		}
+		urls, err := db.GetURLsWithShortenURLs(shortenURLs)
+		if err != nil {
+			logger.WithError(err).Error("Unable to query for urls of page")
+			server.Abort(context, server.InternalError)
+			return
+		}
+		byShortenURL := map[string]database.URL{}
+		for _, url := range urls {
+			if url.Owner == p.WorkspaceID {
+				byShortenURL[url.ShortenURL] = url
+			}
+		}
+
+		links := []renderedLink{}
+		for _, link := range p.Links {
+			url, ok := byShortenURL[link.ShortenURL]
+			if !ok {
+				continue
+			}
+			links = append(links, renderedLink{Title: linkTitle(link, url), Href: shortLink(baseURL, url)})
+		}
+
+		title := p.Title
+		if title == "" {
+			title = p.Slug
+		}
+
+		context.Header("Cache-Control", "public, max-age=60")
+		context.HTML(http.StatusOK, pageTemplate, gin.H{
+			"title":       title,
+			"description": p.Description,
+			"avatarURL":   p.AvatarURL,
+			"theme":       p.Theme,
+			"links":       links,
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"url-shortener/internal/cache"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	server "url-shortener/internal/route/error"
+	"url-shortener/internal/util"
+)
+
+const (
+	maxDetailsLength = 1000
+	rateLimitWindow  = time.Hour
+)
+
+// Request is a complaint sent about a url by anyone who came across it.
+type Request struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details"`
+	Email   string `json:"email"` // optional, to be contacted about the outcome
+}
+
+// validate trims the report in place and returns a field error unless its reason is known, its details short
+// enough and its email, if any, valid.
+func (r *Request) validate() []server.FieldError {
+	var errs []server.FieldError
+	r.Reason = strings.ToLower(strings.TrimSpace(r.Reason))
+	r.Details = strings.TrimSpace(r.Details)
+	r.Email = strings.TrimSpace(r.Email)
+
+	known := false
+	for _, reason := range database.ReportReasons {
+		known = known || r.Reason == reason
+	}
+	if !known {
+		errs = append(errs, server.FieldError{Field: "reason", Message: "must be one of " + strings.Join(database.ReportReasons, ", ")})
+	}
+	if utf8.RuneCountInString(r.Details) > maxDetailsLength {
+		errs = append(errs, server.FieldError{Field: "details", Message: fmt.Sprintf("must be at most %v characters", maxDetailsLength)})
+	}
+	if r.Email != "" && !util.CheckEmailIfValid(r.Email) {
+		errs = append(errs, server.FieldError{Field: "email", Message: "must be a valid email"})
+	}
+
+	return errs
+}
+
+// ReportURLHandler files a report about the url in path. Each address may send up to rateLimit reports an hour,
+// which keeps the endpoint usable without an account or captcha, and each url may receive up to urlRateLimit,
+// so that a flood of reports only builds up so fast whatever addresses it comes from. Once suspendThreshold
+// distinct addresses have open reports about a url, it is suspended until an admin reviews them, its visitors
+// getting a warning page. Addresses are the ones resolved by middleware.ClientAddress.
+func ReportURLHandler(rateLimit int64, urlRateLimit int64, suspendThreshold uint64) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		shortenURL := context.Param("shorten_url")
+		ip := context.GetString("client-ip")
+		logger := logging.FromContext(context).WithField("shorten_url", shortenURL)
+
+		r, err := ioutil.ReadAll(context.Request.Body)
+		if err != nil {
+			logger.WithError(err).Error("Unable to read body properly")
+			server.Abort(context, server.InternalError)
+			return
+		}
+
+		var req Request
+		if err := json.Unmarshal(r, &req); err != nil {
+			logger.WithError(err).Warn("Unexpected json string")
+			server.Abort(context, server.InvalidJSONStringError)
+			return
+		}
+		if errs := req.validate(); len(errs) > 0 {
+			logger.Info("Invalid report")
+			server.Abort(context, server.ValidationError.WithDetails(errs...))
+			return
+		}
+
+		cacheService := context.Value("cache-service").(cache.Service)
+		// note: reports are let through if the count is unavailable, the threshold only counts distinct addresses anyway
+		if sent, err := cacheService.CountReportsFrom(ip, rateLimitWindow); err != nil {
+			logger.WithError(err).Warn("Unable to count reports of address")
+		} else if sent > rateLimit {
+			logger.WithField("sent", sent).Info("Too many reports from address")
+			context.Header("Retry-After", strconv.Itoa(int(rateLimitWindow.Seconds())))
+			server.Abort(context, server.TooManyRequestsError)
+			return
+		}
+
+		db := context.Value("db").(database.MySQLService)
+		url, err := db.GetURLWithShortenURL(shortenURL)
+		if err != nil {
+			if _, ok := err.(database.RecordNotFoundError); ok {
+				logger.Info("Given url not found in database")
+				server.Abort(context, server.NotFoundError)
+				return
+			}
+			logger.WithError(err).Error("Error occurred when querying for url")
+			server.Abort(context, server.InternalError)
+			return
+		}
+
+		if received, err := cacheService.CountReportsAbout(url.ShortenURL, rateLimitWindow); err != nil {
+			logger.WithError(err).Warn("Unable to count reports of url")
+		} else if received > urlRateLimit {
+			logger.WithField("received", received).Warn("Too many reports about url")
+			context.Header("Retry-After", strconv.Itoa(int(rateLimitWindow.Seconds())))
+			server.Abort(context, server.TooManyRequestsError)
+			return
+		}
+
+		err = db.CreateReport(database.Report{
+			ShortenURL:    url.ShortenURL,
+			Reason:        req.Reason,
+			Details:       req.Details,
+			ReporterEmail: req.Email,
+			ReporterIP:    ip,
+		})
+		if err != nil {
+			logger.WithError(err).Error("Unable to create report")
+			server.Abort(context, server.InternalError)
+			return
+		}
+
+		if url.SuspendedAt.IsZero() {
+			suspend(context, *url, suspendThreshold)
+		}
+
+		context.JSON(http.StatusOK, gin.H{
+			"message": "Thank you, the link will be reviewed",
+		})
+	}
+}
+
+// suspend disables url pending review if enough addresses reported it. The report is kept either way,
+// so failures are only logged.
+func suspend(context *gin.Context, url database.URL, threshold uint64) {
+	logger := logging.FromContext(context).WithField("shorten_url", url.ShortenURL)
+	db := context.Value("db").(database.MySQLService)
+
+	reporters, err := db.CountReporters(url.ShortenURL)
+	if err != nil {
+		logger.WithError(err).Error("Unable to count reporters of url")
+		return
+	}
+	if reporters < threshold {
+		return
+	}
+
+	if err := db.SuspendURL(url.ShortenURL, time.Now()); err != nil {
+		logger.WithError(err).Error("Unable to suspend url")
+		return
+	}
+	logger.WithField("reporters", reporters).Warn("Url suspended pending review")
+
+	cacheService := context.Value("cache-service").(cache.Service)
+	if err := cacheService.DelCachedURL(cache.DomainURL(url.Domain, url.ShortenURL)); err != nil {
+		logger.WithError(err).Warn("Unable to evict cached entity")
+	}
+}
@@ -0,0 +1,55 @@
+package shortener
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"regexp"
+	"strings"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/middleware"
+	server "url-shortener/internal/route/error"
+)
+
+// RootRoute is how redirects served at the root are labelled in metrics.
+const RootRoute = "/:code"
+
+// reservedPaths are first path segments of other routes, never resolved as codes at the root.
+var reservedPaths = map[string]bool{
+	"api":         true,
+	"metrics":     true,
+	"p":           true,
+	"static":      true,
+	".well-known": true,
+	"favicon.ico": true,
+	"robots.txt":  true,
+}
+
+var codePattern = regexp.MustCompile("^[0-9A-Za-z]+$")
+
+// IsReservedCode tells whether code can not be served at the root because another route owns the path.
+func IsReservedCode(code string) bool {
+	return reservedPaths[strings.ToLower(code)]
+}
+
+// IsServableCode tells whether code is made of the characters codes are drawn from and served at the root.
+func IsServableCode(code string) bool {
+	return codePattern.MatchString(code) && !IsReservedCode(code)
+}
+
+// RootRedirectHandler serves GET and HEAD /:code with redirect, for paths no other route matches.
+// gin does not allow a wildcard next to static routes at the root, so it is meant to be the NoRoute handler.
+func RootRedirectHandler(redirect gin.HandlerFunc) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		method := context.Request.Method
+		code := strings.TrimPrefix(context.Request.URL.Path, "/")
+		if (method != http.MethodGet && method != http.MethodHead) || !IsServableCode(code) {
+			logging.FromContext(context).Info("No route matched")
+			server.Abort(context, server.NotFoundError)
+			return
+		}
+
+		context.Set(middleware.RouteKey, RootRoute)
+		context.Params = append(context.Params, gin.Param{Key: "shorten_url", Value: code})
+		redirect(context)
+	}
+}
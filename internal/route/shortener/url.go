@@ -4,77 +4,393 @@ import (
 	"crypto/rand"
 	"encoding/json"
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"html/template"
 	"io/ioutil"
-	"log"
 	"math/big"
+	"net"
 	"net/http"
 	url2 "net/url"
+	"strconv"
 	"strings"
 	"time"
+	"url-shortener/internal/cache"
+	"url-shortener/internal/clock"
 	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/metrics"
+	"url-shortener/internal/route/audit"
 	server "url-shortener/internal/route/error"
+	"url-shortener/internal/route/workspace"
+	"url-shortener/internal/service/targeting"
+	"url-shortener/internal/service/webhook"
 	"url-shortener/internal/util"
 )
 
 type ShortenReq struct {
-	URL string `json:"url"`
+	URL       string `json:"url"`
+	Workspace string `json:"workspace"` // defaults to personal workspace of user
+	Domain    string `json:"domain"`    // verified custom domain of workspace, defaults to the default domain
 }
 
-func GetShortenUrlHandler(context *gin.Context) {
-	shortenUrl := context.Param("shorten_url")
+var cachedURLExpiration = time.Hour
 
-	db := context.Value("db").(database.MySQLService)
+const (
+	variantCookiePrefix = "variant_"
+	variantCookieMaxAge = 30 * 24 * 60 * 60
+
+	deepLinkTemplate        = "deep_link.tmpl"
+	notYetAvailableTemplate = "not_yet_available.tmpl"
+	suspendedTemplate       = "suspended.tmpl"
+	deepLinkTimeout         = 1500 * time.Millisecond // how long the app is given to open before falling back to the web
+
+	MaxCreateAttempts = 5 // random codes drawn before giving up on ones already issued
+)
+
+// redirect is how a url is answered, cached as json. Entries cached before statuses were configurable hold the url only.
+type redirect struct {
+	Status       int    `json:"status"`
+	URL          string `json:"url"`
+	CacheControl string `json:"cache_control"`
+	Uncounted    bool   `json:"uncounted,omitempty"`
+	Suspended    bool   `json:"suspended,omitempty"` // visitors are warned instead of redirected
+
+	Rules    []database.RedirectRule `json:"rules,omitempty"`    // evaluated per request before URL
+	Variants []database.URLVariant   `json:"variants,omitempty"` // rotated instead of URL if no rule matches
+	AppURI   string                  `json:"app_uri,omitempty"`
+	StartsAt *time.Time              `json:"starts_at,omitempty"`
+	EndsAt   *time.Time              `json:"ends_at,omitempty"`
+	Schedule *database.Schedule      `json:"schedule,omitempty"`
+}
+
+func newRedirect(url database.URL) redirect {
+	r := redirect{
+		Status:       database.RedirectStatusOf(url),
+		URL:          url.OriginURL,
+		CacheControl: cacheControl(url),
+		Uncounted:    url.AnalyticsDisabled,
+		Suspended:    !url.SuspendedAt.IsZero(),
+		Rules:        url.Rules,
+		Variants:     url.Variants,
+		AppURI:       url.AppURI,
+		Schedule:     url.Schedule,
+	}
+	if !url.StartsAt.IsZero() {
+		r.StartsAt = &url.StartsAt
+	}
+	if !url.EndsAt.IsZero() {
+		r.EndsAt = &url.EndsAt
+	}
+	return r
+}
+
+func encodeCachedRedirect(r redirect) string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func decodeCachedRedirect(value string) redirect {
+	var r redirect
+	if err := json.Unmarshal([]byte(value), &r); err != nil || !database.IsRedirectStatus(r.Status) {
+		return newRedirect(database.URL{OriginURL: value})
+	}
+	return r
+}
 
-	url, err := db.GetURLWithShortenURL(shortenUrl)
+// cacheControl tells browsers and proxies how long they may reuse the redirect of url. Redirects of urls counting
+// hits are revalidated every time, so that each visit reaches the service. Otherwise urls with a temporary status
+// may still be edited and are cached briefly, permanent ones for a day. Urls with redirect rules depend on the
+// visitor and are never shared, neither are the ones with variants, which are kept per visitor, or deep links.
+// Urls active only for some time must not be reused past it, neither must suspended ones once reviewed.
+func cacheControl(url database.URL) string {
+	if !url.SuspendedAt.IsZero() || !url.AnalyticsDisabled || len(url.Rules) > 0 || len(url.Variants) > 0 || url.AppURI != "" ||
+		!url.StartsAt.IsZero() || !url.EndsAt.IsZero() || url.Schedule != nil {
+		return "private, no-cache"
+	}
+	switch database.RedirectStatusOf(url) {
+	case http.StatusMovedPermanently, http.StatusPermanentRedirect:
+		return "public, max-age=86400"
+	}
+	return "public, max-age=300"
+}
+
+// respond redirects to the destination of the first rule matching the visitor. Without a match the visitor is sent
+// to its variant of shortenURL, remembered with a cookie, or to URL if there are no variants. geo is only consulted
+// by rules depending on the country. Before that, urls not active at now are answered with not found, and visitors
+// outside of the schedule are sent to its alternate url. Visitors of suspended urls get a warning page instead,
+// letting them follow the destination at their own risk. The id of the variant visitor was sent to is returned,
+// 0 if none, and whether the visitor was redirected at all.
+func (r redirect) respond(context *gin.Context, shortenURL string, geo targeting.GeoLocator, now time.Time) (uint64, bool) {
+	context.Header("Cache-Control", r.CacheControl)
+	logger := logging.FromContext(context).WithField("shorten_url", shortenURL)
+
+	if r.Suspended {
+		logger.Info("Given url suspended pending review")
+		context.HTML(http.StatusOK, suspendedTemplate, gin.H{
+			"destination": r.URL,
+		})
+		return 0, false
+	}
+
+	var startsAt, endsAt time.Time
+	if r.StartsAt != nil {
+		startsAt = *r.StartsAt
+	}
+	if r.EndsAt != nil {
+		endsAt = *r.EndsAt
+	}
+	switch targeting.AvailabilityAt(startsAt, endsAt, now) {
+	case targeting.NotYetAvailable:
+		logger.Info("Given url not active yet")
+		context.HTML(http.StatusNotFound, notYetAvailableTemplate, gin.H{
+			"startsAt": startsAt.UTC().Format(time.RFC1123),
+		})
+		return 0, false
+	case targeting.Ended:
+		logger.Info("Given url not active anymore")
+		server.Abort(context, server.NotFoundError)
+		return 0, false
+	}
+
+	if r.Schedule != nil && !targeting.InSchedule(*r.Schedule, now) {
+		if r.Schedule.AlternateURL == "" {
+			logger.Info("Given url closed by its schedule")
+			server.Abort(context, server.NotFoundError)
+			return 0, false
+		}
+		r.send(context, r.Schedule.AlternateURL)
+		return 0, true
+	}
+
+	if len(r.Rules) > 0 {
+		if !targeting.NeedsCountry(r.Rules) {
+			geo = nil
+		}
+		visitor := targeting.NewVisitor(context.Request, net.ParseIP(context.GetString("client-ip")), geo)
+		if rule, ok := targeting.Match(r.Rules, visitor); ok {
+			r.send(context, rule.Destination)
+			return 0, true
+		}
+	}
+
+	if len(r.Variants) > 0 {
+		cookie := variantCookiePrefix + shortenURL
+		var sticky uint64
+		if value, err := context.Cookie(cookie); err == nil {
+			sticky, _ = strconv.ParseUint(value, 10, 64)
+		}
+		if variant, ok := targeting.PickVariant(r.Variants, sticky, randomIntn); ok {
+			if variant.ID != sticky {
+				context.SetSameSite(http.SameSiteLaxMode)
+				context.SetCookie(cookie, strconv.FormatUint(variant.ID, 10), variantCookieMaxAge, "/", "", false, true)
+			}
+			r.send(context, variant.Destination)
+			return variant.ID, true
+		}
+	}
+
+	r.send(context, r.URL)
+	return 0, true
+}
+
+// send redirects to destination. Mobile visitors of urls with a deep link get a page opening the app instead,
+// which falls back to destination if the app did not take over in time.
+func (r redirect) send(context *gin.Context, destination string) {
+	if r.AppURI == "" || context.Request.Method != http.MethodGet ||
+		targeting.DeviceOf(context.Request.UserAgent()) == database.DeviceDesktop {
+		context.Redirect(r.Status, destination)
+		return
+	}
+
+	context.HTML(http.StatusOK, deepLinkTemplate, gin.H{
+		// note: app uris are validated on input, html/template would reject their custom schemes otherwise
+		"appURI":    template.URL(r.AppURI),
+		"fallback":  destination,
+		"timeoutMs": deepLinkTimeout.Milliseconds(),
+	})
+}
+
+func randomIntn(n int) int {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
 	if err != nil {
-		if _, ok := err.(database.RecordNotFoundError); ok {
-			log.Printf("Given url %s not found in database", shortenUrl)
-			context.Status(http.StatusNotFound)
+		return 0
+	}
+	return int(i.Int64())
+}
+
+// GetShortenUrlHandler redirects to destination of the code in path. The host of request selects the domain
+// the code is resolved on, domain being the default one. HEAD requests are answered alike but not counted as hits,
+// neither are requests of urls with analytics disabled. Redirect rules of url are matched against the visitor first,
+// geo locating visitors for rules on countries, which are skipped if geo is nil. Activation and schedules of urls are
+// evaluated at the time told by clk.
+func GetShortenUrlHandler(domain string, geo targeting.GeoLocator, clk clock.Clock) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		shortenUrl := context.Param("shorten_url")
+		host := requestHostname(context.Request)
+		logger := logging.FromContext(context).WithField("shorten_url", shortenUrl).WithField("host", host)
+
+		db := context.Value("db").(database.MySQLService)
+		cacheService := context.Value("cache-service").(cache.Service)
+
+		hostDomain := host
+		if host == domain {
+			hostDomain = ""
+		}
+		cacheKey := cache.DomainURL(hostDomain, shortenUrl)
+
+		cached, err := cacheService.GetCachedURL(cacheKey)
+		if err == nil {
+			metrics.RedirectCacheLookups.WithLabelValues(metrics.CacheHit).Inc()
+			r := decodeCachedRedirect(cached)
+			variantID, served := r.respond(context, shortenUrl, geo, clk.Now())
+
+			if served && !r.Uncounted {
+				countHit(context, shortenUrl, variantID, db, logger)
+			}
+			return
+		}
+		if _, ok := err.(*cache.NoFoundErr); !ok {
+			logger.WithError(err).Warn("Error occurred when querying cache for url")
+		}
+		metrics.RedirectCacheLookups.WithLabelValues(metrics.CacheMiss).Inc()
+
+		url, err := db.GetURLWithShortenURL(shortenUrl)
+		if err != nil {
+			if _, ok := err.(database.RecordNotFoundError); ok {
+				logger.Info("Given url not found in database")
+				server.Abort(context, server.NotFoundError)
+				return
+			}
+			logger.WithError(err).Error("Error occurred when querying for url")
+			server.Abort(context, server.InternalError)
 			return
 		}
-		log.Printf("Error occurred when querying for url %s | Reason: %s", shortenUrl, err)
-		context.Status(http.StatusInternalServerError)
+
+		served, err := servedOn(db, *url, host, domain)
+		if err != nil {
+			logger.WithError(err).Error("Error occurred when querying for domain of host")
+			server.Abort(context, server.InternalError)
+			return
+		}
+		if !served {
+			logger.WithField("domain", url.Domain).Info("Given url not served on host")
+			server.Abort(context, server.NotFoundError)
+			return
+		}
+
+		r := newRedirect(*url)
+		variantID, served := r.respond(context, url.ShortenURL, geo, clk.Now())
+
+		// note: only the canonical host of url is cached, so changing url can invalidate it
+		if url.Domain == hostDomain {
+			if err := cacheService.PutCachedURL(cacheKey, encodeCachedRedirect(r), cachedURLExpiration); err != nil {
+				logger.WithError(err).Warn("Unable to cache url")
+			}
+		}
+
+		if served && !r.Uncounted {
+			countHit(context, url.ShortenURL, variantID, db, logger)
+		}
+	}
+}
+
+// countHit counts a visit of shortenURL, and of its variant unless variantID is 0, in the background.
+// HEAD requests come from link previews and checkers rather than visitors, they are left out.
+func countHit(context *gin.Context, shortenURL string, variantID uint64, db database.MySQLService, logger *logrus.Entry) {
+	if context.Request.Method == http.MethodHead {
 		return
 	}
+	go updateURLCount(shortenURL, variantID, db, logger)
+}
 
-	context.Redirect(http.StatusTemporaryRedirect, url.OriginURL)
+// requestHostname returns host of request without port.
+func requestHostname(request *http.Request) string {
+	host := request.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
 
-	go updateURLCount(url, db)
+// servedOn tells whether url is resolved on host. Urls of custom domains are served on their domain only,
+// the other ones on any host but custom domains, so that setups behind proxies keep working.
+func servedOn(db database.MySQLService, url database.URL, host string, defaultDomain string) (bool, error) {
+	if url.Domain != "" {
+		return host == url.Domain, nil
+	}
+	if host == defaultDomain {
+		return true, nil
+	}
+
+	_, err := db.GetDomain(host)
+	if err == nil {
+		return false, nil
+	}
+	if _, ok := err.(database.RecordNotFoundError); ok {
+		return true, nil
+	}
+	return false, err
 }
 
-func updateURLCount(url *database.URL, db database.MySQLService) {
-	url.Count++
-	err := db.UpdateURL(url)
+func updateURLCount(shortenURL string, variantID uint64, db database.MySQLService, logger *logrus.Entry) {
+	err := db.IncreaseURLCount(shortenURL)
 	if err != nil {
-		log.Printf("Failed to update count for url: %v\n", url.ShortenURL)
+		logger.WithError(err).Error("Failed to update count for url")
+	}
+
+	if variantID == 0 {
+		return
+	}
+	// note: a variant replaced since the redirect was cached is not found, its hit is dropped
+	if err := db.IncreaseURLVariantCount(variantID); err != nil {
+		logger.WithError(err).WithField("variant_id", variantID).Warn("Failed to update count for variant")
+	}
+}
+
+// requestMetadata queues shortenURL for fetching metadata of its destination. Urls are left for the periodic
+// refresh rather than blocking the request if the queue is full.
+func requestMetadata(metadataRequest chan<- string, shortenURL string, logger *logrus.Entry) {
+	if metadataRequest == nil {
+		return
+	}
+	select {
+	case metadataRequest <- shortenURL:
+	default:
+		logger.Warn("Metadata request queue is full")
 	}
 }
 
-func CreateShortenUrlHandler(domain string) gin.HandlerFunc {
+func CreateShortenUrlHandler(domain string, metadataRequest chan<- string) gin.HandlerFunc {
 	return func(context *gin.Context) {
 		/**
 		{
-			"url": "<your-url>"
+			"url": "<your-url>",
+			"workspace": "<optional-workspace-id>"
 		}
 		*/
+		logger := logging.FromContext(context)
+
 		body := context.Request.Body
 		r, err := ioutil.ReadAll(body)
 		if err != nil {
-			log.Printf("Unable to read body properly | Reason: %v\n", err)
-			context.AbortWithStatus(http.StatusInternalServerError)
+			logger.WithError(err).Error("Unable to read body properly")
+			server.Abort(context, server.InternalError)
 			return
 		}
 
 		var sReq ShortenReq
 		err = json.Unmarshal(r, &sReq)
 		if err != nil {
-			log.Printf("Unexpected json string: %v | Reason: %v\n", string(r), err)
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.RequestError))
+			logger.WithError(err).Warn("Unexpected json string")
+			server.Abort(context, server.RequestError)
 			return
 		}
 		if len(sReq.URL) == 0 {
-			log.Printf("Empty url")
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.RequestError))
+			logger.Warn("Empty url")
+			server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+				Field:   "url",
+				Message: "must not be empty",
+			}))
 			return
 		}
 
@@ -83,8 +399,11 @@ func CreateShortenUrlHandler(domain string) gin.HandlerFunc {
 		if len(splits) > 1 {
 			protocol := splits[0]
 			if protocol != "ftp" && protocol != "http" && protocol != "https" {
-				log.Printf("Unsupported scheme to get shorthand: %v with splits %v\n", protocol, len(splits))
-				context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.RequestError))
+				logger.WithField("scheme", protocol).Warn("Unsupported scheme to get shorthand")
+				server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+					Field:   "url",
+					Message: "scheme must be one of ftp, http, https",
+				}))
 				return
 			}
 		}
@@ -95,39 +414,98 @@ func CreateShortenUrlHandler(domain string) gin.HandlerFunc {
 
 		u, err := url2.Parse(sReq.URL)
 		if err != nil {
-			log.Printf("Invalid url to get shorthand")
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.RequestError))
+			logger.WithError(err).Warn("Invalid url to get shorthand")
+			server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+				Field:   "url",
+				Message: "must be a valid url",
+			}))
 			return
 		}
 		if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "ftp" {
-			log.Printf("Invalid scheme to get shorthand")
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.RequestError))
+			logger.WithField("scheme", u.Scheme).Warn("Invalid scheme to get shorthand")
+			server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+				Field:   "url",
+				Message: "scheme must be one of ftp, http, https",
+			}))
 			return
 		}
 		if u.Hostname() == domain {
-			log.Printf("Recursive resolves is not allowed")
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.RequestError))
+			logger.WithField("host", u.Hostname()).Warn("Recursive resolves is not allowed")
+			server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+				Field:   "url",
+				Message: "must not point to this service",
+			}))
 			return
 		}
 
 		db := context.Value("db").(database.MySQLService)
 		user := context.Value("user").(*database.User)
-		url, err := db.GetURLIfExistsWithUser(*user, u.String())
+		workspaceID := strings.TrimSpace(sReq.Workspace)
+		if workspaceID == "" {
+			workspaceID = user.UserID
+		}
+		if !workspace.Authorize(context, logger, workspaceID, database.WorkspaceRoleEditor) {
+			return
+		}
+
+		if _, err := db.GetDomain(strings.ToLower(u.Hostname())); err == nil {
+			logger.WithField("host", u.Hostname()).Warn("Recursive resolves is not allowed")
+			server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+				Field:   "url",
+				Message: "must not point to this service",
+			}))
+			return
+		} else if _, ok := err.(database.RecordNotFoundError); !ok {
+			logger.WithError(err).Error("Error occurred when querying for domain of url")
+			server.Abort(context, server.InternalError)
+			return
+		}
+
+		urlDomain, ok := getLinkDomain(context, logger, workspaceID, sReq.Domain)
+		if !ok {
+			return
+		}
+
+		url, err := db.GetURLIfExistsInWorkspace(workspaceID, urlDomain, u.String())
 		if err != nil {
 			if _, ok := err.(database.RecordNotFoundError); ok {
-				shorten, err := getRandomUniqueStr(big.NewInt(999999999), time.Now())
-				if err != nil {
-					log.Printf("Unable to gen random number properly")
-					context.AbortWithStatus(http.StatusInternalServerError)
-					return
-				}
+				var shorten string
+				// note: codes ever issued, even deleted since, are refused by CreateURL so a new one is drawn
+				for attempt := 0; attempt < MaxCreateAttempts; attempt++ {
+					shorten, err = NewShortenURL()
+					if err != nil {
+						logger.WithError(err).Error("Unable to gen random number properly")
+						server.Abort(context, server.InternalError)
+						return
+					}
 
-				err = db.CreateURL(u.String(), shorten, *user)
+					err = db.CreateURL(u.String(), shorten, urlDomain, workspaceID, *user)
+					if _, ok := err.(database.ShortenURLTakenError); !ok {
+						break
+					}
+					logger.WithField("shorten_url", shorten).Info("Shorten url already issued, retrying")
+				}
 				if err != nil {
-					log.Printf("Unable to create entity for given url | Reason: %s", err)
-					context.AbortWithStatus(http.StatusInternalServerError)
+					logger.WithError(err).Error("Unable to create entity for given url")
+					server.Abort(context, server.InternalError)
 					return
 				}
+				requestMetadata(metadataRequest, shorten, logger)
+				audit.Record(context, user.UserID, database.AuditActionURLCreate, database.AuditTargetURL, shorten, nil, gin.H{
+					"origin_url": u.String(),
+					"workspace":  workspaceID,
+					"domain":     urlDomain,
+				})
+				now := time.Now()
+				webhook.Notify(db, logger, database.WebhookEventURLCreated, database.URL{
+					ShortenURL: shorten,
+					OriginURL:  u.String(),
+					Owner:      workspaceID,
+					CreatedBy:  user.UserID,
+					Domain:     urlDomain,
+					CreatedAt:  now,
+					UpdatedAt:  now,
+				})
 
 				context.JSON(http.StatusOK, gin.H{
 					"url": shorten,
@@ -135,8 +513,8 @@ func CreateShortenUrlHandler(domain string) gin.HandlerFunc {
 				return
 			}
 
-			log.Printf("Error occurred when querying for given origin url if non-absent")
-			context.AbortWithStatus(http.StatusInternalServerError)
+			logger.WithError(err).Error("Error occurred when querying for given origin url if non-absent")
+			server.Abort(context, server.InternalError)
 			return
 		}
 
@@ -146,6 +524,50 @@ func CreateShortenUrlHandler(domain string) gin.HandlerFunc {
 	}
 }
 
+// getLinkDomain returns the custom domain requested for a new url of workspace, or empty for the default domain.
+// It aborts unless the domain belongs to workspace and is verified.
+func getLinkDomain(context *gin.Context, logger *logrus.Entry, workspaceID string, requested string) (string, bool) {
+	requested = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(requested)), ".")
+	if requested == "" {
+		return "", true
+	}
+
+	invalid := server.ValidationError.WithDetails(server.FieldError{
+		Field:   "domain",
+		Message: "must be a verified domain of workspace",
+	})
+
+	db := context.Value("db").(database.MySQLService)
+	d, err := db.GetDomain(requested)
+	if err != nil {
+		if _, ok := err.(database.RecordNotFoundError); ok {
+			logger.WithField("domain", requested).Info("Domain not found")
+			server.Abort(context, invalid)
+			return "", false
+		}
+		logger.WithError(err).Error("Error occurred when querying for domain")
+		server.Abort(context, server.InternalError)
+		return "", false
+	}
+	if d.WorkspaceID != workspaceID || d.VerifiedAt.IsZero() {
+		logger.WithField("domain", requested).Info("Domain not usable by workspace")
+		server.Abort(context, invalid)
+		return "", false
+	}
+
+	return d.Hostname, true
+}
+
+// NewShortenURL draws a random code for a new url, never one reserved by other routes. It may have been issued
+// already, which the database refuses.
+func NewShortenURL() (string, error) {
+	shorten, err := getRandomUniqueStr(big.NewInt(999999999), time.Now())
+	for err == nil && IsReservedCode(shorten) {
+		shorten, err = getRandomUniqueStr(big.NewInt(999999999), time.Now())
+	}
+	return shorten, err
+}
+
 // seed1 is from bigInteger in range of 0 to given value, seed2 is from time stamp in the form of seconds
 func getRandomUniqueStr(seed1 *big.Int, seed2 time.Time) (string, error) {
 	// TODO: better to generate unique id with single instance of offline unique id generator behind exposed entry-point
@@ -0,0 +1,28 @@
+package shortener
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"url-shortener/internal/logging"
+	server "url-shortener/internal/route/error"
+)
+
+const (
+	AppleAppSiteAssociationRoute = "/.well-known/apple-app-site-association"
+	AndroidAssetLinksRoute       = "/.well-known/assetlinks.json"
+)
+
+// WellKnownJSONHandler serves content, which apps verify to open links of this service as deep links.
+// Nothing is served if content is not configured.
+func WellKnownJSONHandler(content []byte) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		if len(content) == 0 {
+			logging.FromContext(context).Info("Well-known content not configured")
+			server.Abort(context, server.NotFoundError)
+			return
+		}
+
+		context.Header("Cache-Control", "public, max-age=3600")
+		context.Data(http.StatusOK, "application/json", content)
+	}
+}
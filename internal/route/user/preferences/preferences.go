@@ -0,0 +1,62 @@
+package preferences
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	server "url-shortener/internal/route/error"
+)
+
+type PreferencesResponse struct {
+	DeadLinkAlerts bool `json:"dead_link_alerts"`
+}
+
+// PreferencesRequest updates preferences of user, omitted fields are left unchanged.
+type PreferencesRequest struct {
+	DeadLinkAlerts *bool `json:"dead_link_alerts"`
+}
+
+func newPreferencesResponse(user database.User) PreferencesResponse {
+	return PreferencesResponse{
+		DeadLinkAlerts: !user.DeadLinkAlertsDisabled,
+	}
+}
+
+func GetPreferencesHandler(context *gin.Context) {
+	user := context.Value("user").(*database.User)
+	context.JSON(http.StatusOK, newPreferencesResponse(*user))
+}
+
+func UpdatePreferencesHandler(context *gin.Context) {
+	logger := logging.FromContext(context)
+
+	r, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		logger.WithError(err).Error("Unable to read body properly")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	var req PreferencesRequest
+	if err := json.Unmarshal(r, &req); err != nil {
+		logger.WithError(err).Warn("Unexpected json string")
+		server.Abort(context, server.InvalidJSONStringError)
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	user := context.Value("user").(*database.User)
+	if req.DeadLinkAlerts != nil {
+		if err := db.SetDeadLinkAlertsDisabled(*user, !*req.DeadLinkAlerts); err != nil {
+			logger.WithError(err).Error("Unable to update preferences of user")
+			server.Abort(context, server.InternalError)
+			return
+		}
+		user.DeadLinkAlertsDisabled = !*req.DeadLinkAlerts
+	}
+
+	context.JSON(http.StatusOK, newPreferencesResponse(*user))
+}
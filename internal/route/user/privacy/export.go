@@ -0,0 +1,168 @@
+package privacy
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/route/audit"
+	server "url-shortener/internal/route/error"
+	"url-shortener/internal/util"
+)
+
+type DataExportResponse struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	Size        uint64     `json:"size"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+type DataExportsResponse struct {
+	Exports []DataExportResponse `json:"exports"`
+}
+
+func newDataExportResponse(export database.DataExport) DataExportResponse {
+	response := DataExportResponse{
+		ID:        export.ID,
+		Status:    export.Status,
+		Size:      export.Size,
+		CreatedAt: export.CreatedAt,
+	}
+	if !export.CompletedAt.IsZero() {
+		response.CompletedAt = &export.CompletedAt
+	}
+	if !export.ExpiresAt.IsZero() {
+		response.ExpiresAt = &export.ExpiresAt
+	}
+	return response
+}
+
+// requestExport queues id for packaging. Exports are left for the periodic run rather than blocking the request
+// if the queue is full.
+func requestExport(exportRequest chan<- string, id string, logger *logrus.Entry) {
+	if exportRequest == nil {
+		return
+	}
+	select {
+	case exportRequest <- id:
+	default:
+		logger.Warn("Data export request queue is full")
+	}
+}
+
+// CreateDataExportHandler requests a copy of everything held about user, packaged in the background.
+// Only one export can be pending at a time.
+func CreateDataExportHandler(exportRequest chan<- string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		logger := logging.FromContext(context)
+		db := context.Value("db").(database.MySQLService)
+		user := context.Value("user").(*database.User)
+
+		exports, err := db.GetDataExportsWithUser(user.UserID)
+		if err != nil {
+			logger.WithError(err).Error("Unable to query data exports of user")
+			server.Abort(context, server.InternalError)
+			return
+		}
+		for _, export := range exports {
+			if export.Status == database.DataExportStatusPending {
+				server.Abort(context, server.RequestError.WithMessage("A data export is already being prepared"))
+				return
+			}
+		}
+
+		id, err := util.NewUUID()
+		if err != nil {
+			logger.WithError(err).Error("Unable to generate id of data export")
+			server.Abort(context, server.InternalError)
+			return
+		}
+
+		export := database.DataExport{ID: id, UserID: user.UserID, Status: database.DataExportStatusPending}
+		if err := db.CreateDataExport(export); err != nil {
+			logger.WithError(err).Error("Unable to create data export")
+			server.Abort(context, server.InternalError)
+			return
+		}
+		created, err := db.GetDataExport(id)
+		if err != nil {
+			logger.WithError(err).Error("Unable to query created data export")
+			server.Abort(context, server.InternalError)
+			return
+		}
+
+		requestExport(exportRequest, id, logger.WithField("export_id", id))
+		audit.Record(context, user.UserID, database.AuditActionDataExport, database.AuditTargetUser, user.UserID, nil, nil)
+
+		context.JSON(http.StatusAccepted, newDataExportResponse(*created))
+	}
+}
+
+func GetDataExportsHandler(context *gin.Context) {
+	logger := logging.FromContext(context)
+	db := context.Value("db").(database.MySQLService)
+	user := context.Value("user").(*database.User)
+
+	exports, err := db.GetDataExportsWithUser(user.UserID)
+	if err != nil {
+		logger.WithError(err).Error("Unable to query data exports of user")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	response := DataExportsResponse{Exports: make([]DataExportResponse, len(exports))}
+	for i, export := range exports {
+		response.Exports[i] = newDataExportResponse(export)
+	}
+	context.JSON(http.StatusOK, response)
+}
+
+// DownloadDataExportHandler serves the archive of a ready export to the user who requested it.
+// Exports of other users are reported not found.
+func DownloadDataExportHandler(context *gin.Context) {
+	logger := logging.FromContext(context)
+	db := context.Value("db").(database.MySQLService)
+	user := context.Value("user").(*database.User)
+	id := context.Param("export_id")
+
+	export, err := db.GetDataExport(id)
+	if _, ok := err.(database.RecordNotFoundError); ok || (err == nil && export.UserID != user.UserID) {
+		server.Abort(context, server.NotFoundError)
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Error("Unable to query data export")
+		server.Abort(context, server.InternalError)
+		return
+	}
+	if export.Status != database.DataExportStatusReady || !time.Now().Before(export.ExpiresAt) {
+		server.Abort(context, server.RequestError.WithMessage(fmt.Sprintf("Data export is %v", exportState(*export))))
+		return
+	}
+
+	archive, err := db.GetDataExportArchive(id)
+	if _, ok := err.(database.RecordNotFoundError); ok {
+		server.Abort(context, server.NotFoundError)
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Error("Unable to query archive of data export")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	context.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"data-export-%v.zip\"", export.CreatedAt.UTC().Format("2006-01-02")))
+	context.Data(http.StatusOK, "application/zip", archive)
+}
+
+func exportState(export database.DataExport) string {
+	if export.Status == database.DataExportStatusReady {
+		return "expired"
+	}
+	return export.Status
+}
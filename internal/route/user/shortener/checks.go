@@ -0,0 +1,54 @@
+package shortener
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	server "url-shortener/internal/route/error"
+)
+
+type URLChecksResponse struct {
+	Checks []URLCheckResponse `json:"checks"`
+}
+
+type URLCheckResponse struct {
+	Status    int       `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Broken    bool      `json:"broken"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+const maxURLChecks = 100
+
+// GetURLChecksHandler lists the latest checks of destination of a url in a workspace user is a member of, newest first.
+func GetURLChecksHandler(context *gin.Context) {
+	shortenURL := context.Param("shorten_url")
+	logger := logging.FromContext(context).WithField("shorten_url", shortenURL)
+
+	if _, ok := getAuthorizedURL(context, logger, shortenURL, database.WorkspaceRoleViewer); !ok {
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+
+	checks, err := db.GetURLChecks(shortenURL, maxURLChecks)
+	if err != nil {
+		logger.WithError(err).Error("Unable to query for url checks")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	resChecks := make([]URLCheckResponse, len(checks))
+	for i, check := range checks {
+		resChecks[i] = URLCheckResponse{
+			Status:    check.Status,
+			Error:     check.Error,
+			Broken:    check.Broken,
+			CheckedAt: check.CheckedAt,
+		}
+	}
+
+	context.JSON(http.StatusOK, URLChecksResponse{Checks: resChecks})
+}
@@ -0,0 +1,51 @@
+package shortener
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+	"url-shortener/internal/database"
+)
+
+// cursor is the opaque pagination token handed out to clients. Sort binds it to the ordering it was issued for.
+type cursor struct {
+	Sort       string    `json:"s"`
+	ShortenURL string    `json:"k"`
+	Time       time.Time `json:"t,omitempty"`
+	Hits       int64     `json:"h,omitempty"`
+}
+
+func encodeCursor(sort string, c *database.URLCursor) string {
+	if c == nil {
+		return ""
+	}
+	b, _ := json.Marshal(cursor{
+		Sort:       sort,
+		ShortenURL: c.ShortenURL,
+		Time:       c.Time,
+		Hits:       c.Hits,
+	})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(sort string, token string) (*database.URLCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	if c.Sort != sort || c.ShortenURL == "" {
+		return nil, errors.New("cursor issued for another ordering")
+	}
+
+	return &database.URLCursor{
+		ShortenURL: c.ShortenURL,
+		Time:       c.Time,
+		Hits:       c.Hits,
+	}, nil
+}
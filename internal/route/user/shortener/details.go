@@ -0,0 +1,296 @@
+package shortener
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	url2 "net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"url-shortener/internal/cache"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/route/audit"
+	server "url-shortener/internal/route/error"
+	"url-shortener/internal/route/workspace"
+	"url-shortener/internal/service/webhook"
+)
+
+const (
+	maxTitleLength  = 255
+	maxFolderLength = 100
+	maxNoteLength   = 2000
+	maxTags         = 20
+	maxTagLength    = 50
+	maxAppURILength = 2048
+)
+
+var appSchemePattern = regexp.MustCompile("^[a-z][a-z0-9+.-]*$")
+
+// unsafeAppSchemes are never opened as apps, as they either are web pages or run in the context of the page.
+var unsafeAppSchemes = map[string]bool{
+	"http":       true,
+	"https":      true,
+	"javascript": true,
+	"vbscript":   true,
+	"data":       true,
+	"file":       true,
+	"about":      true,
+	"blob":       true,
+}
+
+// URLDetailsRequest edits attributes of url, omitted fields are left unchanged.
+type URLDetailsRequest struct {
+	Title          *string   `json:"title"`
+	Folder         *string   `json:"folder"`
+	Note           *string   `json:"note"`
+	Tags           *[]string `json:"tags"`
+	RedirectStatus *int      `json:"redirect_status"`
+	Analytics      *bool     `json:"analytics"`
+	AppURI         *string   `json:"app_uri"`   // empty removes the deep link
+	StartsAt       *string   `json:"starts_at"` // RFC 3339, empty removes the start
+	EndsAt         *string   `json:"ends_at"`   // RFC 3339, empty removes the end
+
+	startsAt *time.Time
+	endsAt   *time.Time
+}
+
+type TagsResponse struct {
+	Tags []TagResponse `json:"tags"`
+}
+
+type TagResponse struct {
+	Tag   string `json:"tag"`
+	Links uint64 `json:"links"`
+	Hits  int64  `json:"hits"`
+}
+
+// normalizeTag makes tags comparable regardless of surrounding spaces and case.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// validate normalizes the request in place and returns every field violating the limits.
+func (r *URLDetailsRequest) validate() []server.FieldError {
+	var errs []server.FieldError
+	checkLength := func(field string, value *string, max int) {
+		if value == nil {
+			return
+		}
+		*value = strings.TrimSpace(*value)
+		if utf8.RuneCountInString(*value) > max {
+			errs = append(errs, server.FieldError{Field: field, Message: fmt.Sprintf("must be at most %v characters", max)})
+		}
+	}
+	checkLength("title", r.Title, maxTitleLength)
+	checkLength("folder", r.Folder, maxFolderLength)
+	checkLength("note", r.Note, maxNoteLength)
+
+	if r.RedirectStatus != nil && !database.IsRedirectStatus(*r.RedirectStatus) {
+		errs = append(errs, server.FieldError{Field: "redirect_status", Message: "must be one of 301, 302, 307, 308"})
+	}
+
+	if r.AppURI != nil {
+		*r.AppURI = strings.TrimSpace(*r.AppURI)
+		if *r.AppURI != "" && !isAppURI(*r.AppURI) {
+			errs = append(errs, server.FieldError{Field: "app_uri", Message: fmt.Sprintf("must be an app uri with a custom scheme of at most %v characters", maxAppURILength)})
+		}
+	}
+
+	parseTime := func(field string, value *string) *time.Time {
+		if value == nil {
+			return nil
+		}
+		var t time.Time
+		if s := strings.TrimSpace(*value); s != "" {
+			var err error
+			if t, err = time.Parse(time.RFC3339, s); err != nil {
+				errs = append(errs, server.FieldError{Field: field, Message: "must be an RFC 3339 date-time"})
+			}
+		}
+		return &t
+	}
+	r.startsAt = parseTime("starts_at", r.StartsAt)
+	r.endsAt = parseTime("ends_at", r.EndsAt)
+
+	if r.Tags == nil {
+		return errs
+	}
+	seen := map[string]bool{}
+	tags := []string{}
+	for i, tag := range *r.Tags {
+		tag = normalizeTag(tag)
+		if tag == "" {
+			errs = append(errs, server.FieldError{Field: fmt.Sprintf("tags[%v]", i), Message: "must not be empty"})
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			errs = append(errs, server.FieldError{Field: fmt.Sprintf("tags[%v]", i), Message: fmt.Sprintf("must be at most %v characters", maxTagLength)})
+			continue
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > maxTags {
+		errs = append(errs, server.FieldError{Field: "tags", Message: fmt.Sprintf("must have at most %v items", maxTags)})
+	}
+	r.Tags = &tags
+
+	return errs
+}
+
+// isAppURI tells whether raw is an absolute uri with a scheme fit for opening an app, e.g. myapp://item/42.
+func isAppURI(raw string) bool {
+	if len(raw) > maxAppURILength {
+		return false
+	}
+	u, err := url2.Parse(raw)
+	if err != nil {
+		return false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	return appSchemePattern.MatchString(scheme) && !unsafeAppSchemes[scheme]
+}
+
+// getAuthorizedURL queries url, aborting unless it exists and user holds at least required role in its workspace.
+func getAuthorizedURL(context *gin.Context, logger *logrus.Entry, shortenURL string, required string) (*database.URL, bool) {
+	db := context.Value("db").(database.MySQLService)
+	url, err := db.GetURLWithShortenURL(shortenURL)
+	if err != nil {
+		if _, ok := err.(database.RecordNotFoundError); ok {
+			logger.Info("Given url not found in database")
+			server.Abort(context, server.NotFoundError)
+			return nil, false
+		}
+		logger.WithError(err).Error("Error occurred when querying for url")
+		server.Abort(context, server.InternalError)
+		return nil, false
+	}
+	if !workspace.Authorize(context, logger, url.Owner, required) {
+		return nil, false
+	}
+
+	return url, true
+}
+
+// UpdateShortenUrlHandler edits title, folder, note, tags, redirect status, analytics, app uri and activation of a url in a workspace user is an editor of.
+func UpdateShortenUrlHandler(context *gin.Context) {
+	shortenURL := context.Param("shorten_url")
+	logger := logging.FromContext(context).WithField("shorten_url", shortenURL)
+
+	r, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		logger.WithError(err).Error("Unable to read body properly")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	var req URLDetailsRequest
+	if err := json.Unmarshal(r, &req); err != nil {
+		logger.WithError(err).Warn("Unexpected json string")
+		server.Abort(context, server.InvalidJSONStringError)
+		return
+	}
+	if errs := req.validate(); len(errs) > 0 {
+		logger.Info("Invalid url details")
+		server.Abort(context, server.ValidationError.WithDetails(errs...))
+		return
+	}
+
+	stored, ok := getAuthorizedURL(context, logger, shortenURL, database.WorkspaceRoleEditor)
+	if !ok {
+		return
+	}
+
+	startsAt, endsAt := stored.StartsAt, stored.EndsAt
+	if req.startsAt != nil {
+		startsAt = *req.startsAt
+	}
+	if req.endsAt != nil {
+		endsAt = *req.endsAt
+	}
+	if !startsAt.IsZero() && !endsAt.IsZero() && !endsAt.After(startsAt) {
+		logger.Info("Url would end before it starts")
+		server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+			Field:   "ends_at",
+			Message: "must be after starts_at",
+		}))
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+
+	var analyticsDisabled *bool
+	if req.Analytics != nil {
+		disabled := !*req.Analytics
+		analyticsDisabled = &disabled
+	}
+	err = db.UpdateURLDetails(shortenURL, database.URLDetails{
+		Title:             req.Title,
+		Folder:            req.Folder,
+		Note:              req.Note,
+		Tags:              req.Tags,
+		RedirectStatus:    req.RedirectStatus,
+		AnalyticsDisabled: analyticsDisabled,
+		AppURI:            req.AppURI,
+		StartsAt:          req.startsAt,
+		EndsAt:            req.endsAt,
+	})
+	if err != nil {
+		logger.WithError(err).Error("Unable to update url details")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	if req.RedirectStatus != nil || req.Analytics != nil || req.AppURI != nil || req.startsAt != nil || req.endsAt != nil {
+		cacheService := context.Value("cache-service").(cache.Service)
+		if err := cacheService.DelCachedURL(cache.DomainURL(stored.Domain, shortenURL)); err != nil {
+			logger.WithError(err).Warn("Unable to evict cached entity")
+		}
+	}
+
+	url, err := db.GetURLWithShortenURL(shortenURL)
+	if err != nil {
+		logger.WithError(err).Error("Error occurred when querying for updated url")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	user := context.Value("user").(*database.User)
+	audit.Record(context, user.UserID, database.AuditActionURLUpdate, database.AuditTargetURL, shortenURL,
+		newURLResponse(*stored), newURLResponse(*url))
+	webhook.Notify(db, logger, database.WebhookEventURLUpdated, *url)
+
+	context.JSON(http.StatusOK, newURLResponse(*url))
+}
+
+// GetTagsHandler lists tags used in workspace with the number of urls and hits of each.
+func GetTagsHandler(context *gin.Context) {
+	workspaceID := workspace.FromQuery(context)
+	logger := logging.FromContext(context).WithField("workspace_id", workspaceID)
+	if !workspace.Authorize(context, logger, workspaceID, database.WorkspaceRoleViewer) {
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	stats, err := db.GetTagStatsInWorkspace(workspaceID)
+	if err != nil {
+		logger.WithError(err).Error("Unable to query for user's tags")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	tags := make([]TagResponse, len(stats))
+	for i, stat := range stats {
+		tags[i] = TagResponse{Tag: stat.Tag, Links: stat.Links, Hits: stat.Hits}
+	}
+
+	context.JSON(http.StatusOK, TagsResponse{Tags: tags})
+}
@@ -0,0 +1,200 @@
+package shortener
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	server "url-shortener/internal/route/error"
+	"url-shortener/internal/route/workspace"
+)
+
+const (
+	formatCSV   = "csv"
+	formatJSONL = "jsonl"
+
+	exportBatchSize = 500
+)
+
+// exportColumns are the header of exported csv files, also understood by imports.
+var exportColumns = []string{"shorten_url", "origin_url", "domain", "title", "folder", "note", "tags", "hits", "created_at", "updated_at"}
+
+// ExportedURL is a line of exported json lines files, and the fields of imported ones.
+type ExportedURL struct {
+	ShortenURL string    `json:"shorten_url"`
+	OriginURL  string    `json:"origin_url"`
+	Domain     string    `json:"domain"` // empty for the default domain
+	Title      string    `json:"title"`
+	Folder     string    `json:"folder"`
+	Note       string    `json:"note"`
+	Tags       []string  `json:"tags"`
+	Hits       int64     `json:"hits"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func newExportedURL(url database.URL) ExportedURL {
+	return ExportedURL{
+		ShortenURL: url.ShortenURL,
+		OriginURL:  url.OriginURL,
+		Domain:     url.Domain,
+		Title:      url.Title,
+		Folder:     url.Folder,
+		Note:       url.Note,
+		Tags:       url.Tags,
+		Hits:       url.Count,
+		CreatedAt:  url.CreatedAt,
+		UpdatedAt:  url.UpdatedAt,
+	}
+}
+
+// formulaPrefixes start cells spreadsheets evaluate as formulas, along with the quote escaping them.
+const formulaPrefixes = "=+-@'"
+
+// escapeFormula prefixes value with a quote if spreadsheets would evaluate it, unescapeFormula drops it on import.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func unescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// record returns u as a row of exportColumns. Tags are joined with commas, cells users write are escaped
+// from being evaluated as formulas.
+func (u ExportedURL) record() []string {
+	return []string{
+		u.ShortenURL,
+		escapeFormula(u.OriginURL),
+		u.Domain,
+		escapeFormula(u.Title),
+		escapeFormula(u.Folder),
+		escapeFormula(u.Note),
+		escapeFormula(strings.Join(u.Tags, ",")),
+		strconv.FormatInt(u.Hits, 10),
+		u.CreatedAt.UTC().Format(time.RFC3339),
+		u.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// urlEncoder writes exported urls to a response in one of the export formats.
+type urlEncoder interface {
+	Encode(url ExportedURL) error
+	Flush() error
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVEncoder(w io.Writer) (*csvEncoder, error) {
+	e := &csvEncoder{w: csv.NewWriter(w)}
+	return e, e.w.Write(exportColumns)
+}
+
+func (e *csvEncoder) Encode(url ExportedURL) error {
+	return e.w.Write(url.record())
+}
+
+func (e *csvEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonlEncoder struct {
+	e *json.Encoder
+}
+
+func (e *jsonlEncoder) Encode(url ExportedURL) error {
+	return e.e.Encode(url)
+}
+
+func (e *jsonlEncoder) Flush() error {
+	return nil
+}
+
+// ExportURLsHandler streams every url of a workspace user is a member of, with hits, tags and timestamps, as csv
+// or json lines. Urls are read and written a batch at a time, so that large workspaces are not held in memory.
+func ExportURLsHandler(context *gin.Context) {
+	logger := logging.FromContext(context)
+
+	format := context.DefaultQuery("format", formatCSV)
+	if format != formatCSV && format != formatJSONL {
+		logger.WithField("format", format).Info("Unsupported export format")
+		server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+			Field:   "format",
+			Message: "must be one of csv, jsonl",
+		}))
+		return
+	}
+
+	workspaceID := workspace.FromQuery(context)
+	if !workspace.Authorize(context, logger, workspaceID, database.WorkspaceRoleViewer) {
+		return
+	}
+
+	// note: the first batch is read before responding, so that failing right away is still answered with an error
+	db := context.Value("db").(database.MySQLService)
+	urls, err := db.GetURLsInWorkspaceAfter(workspaceID, "", exportBatchSize)
+	if err != nil {
+		logger.WithError(err).Error("Unable to query for urls of workspace")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == formatJSONL {
+		contentType = "application/x-ndjson"
+	}
+	context.Header("Content-Type", contentType)
+	context.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="links-%v.%v"`, time.Now().UTC().Format("20060102"), format))
+	context.Status(http.StatusOK)
+
+	var encoder urlEncoder = &jsonlEncoder{e: json.NewEncoder(context.Writer)}
+	if format == formatCSV {
+		if encoder, err = newCSVEncoder(context.Writer); err != nil {
+			logger.WithError(err).Warn("Unable to write export")
+			return
+		}
+	}
+
+	exported := 0
+	for {
+		for _, url := range urls {
+			if err := encoder.Encode(newExportedURL(url)); err != nil {
+				logger.WithError(err).Warn("Unable to write export")
+				return
+			}
+		}
+		if err := encoder.Flush(); err != nil {
+			logger.WithError(err).Warn("Unable to write export")
+			return
+		}
+		context.Writer.Flush()
+		exported += len(urls)
+
+		if len(urls) < exportBatchSize {
+			break
+		}
+		// note: the status is sent already, a failure from now on can only cut the export short
+		if urls, err = db.GetURLsInWorkspaceAfter(workspaceID, urls[len(urls)-1].ShortenURL, exportBatchSize); err != nil {
+			logger.WithError(err).Error("Unable to query for urls of workspace, export is incomplete")
+			return
+		}
+	}
+
+	logger.WithField("exported", exported).Info("Urls exported")
+}
@@ -0,0 +1,475 @@
+package shortener
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	url2 "net/url"
+	"strconv"
+	"strings"
+	"time"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/route/audit"
+	server "url-shortener/internal/route/error"
+	"url-shortener/internal/route/shortener"
+	"url-shortener/internal/route/workspace"
+	"url-shortener/internal/service/webhook"
+)
+
+const (
+	maxImportSize         = 10 << 20
+	maxImportRows         = 10000
+	maxImportedCodeLength = 64
+	maxImportedLineLength = 1 << 20
+)
+
+// importColumns maps csv headers, lowercased with spaces as underscores, to the field they hold. Besides the
+// columns of exports, the ones of Bitly exports are understood.
+var importColumns = map[string]string{
+	"shorten_url":  "shorten_url",
+	"short_url":    "shorten_url",
+	"link":         "shorten_url",
+	"bitlink":      "shorten_url",
+	"origin_url":   "origin_url",
+	"long_url":     "origin_url",
+	"url":          "origin_url",
+	"destination":  "origin_url",
+	"domain":       "domain",
+	"title":        "title",
+	"folder":       "folder",
+	"note":         "note",
+	"tags":         "tags",
+	"hits":         "hits",
+	"clicks":       "hits",
+	"total_clicks": "hits",
+	"created_at":   "created_at",
+	"created":      "created_at",
+	"date_created": "created_at",
+}
+
+// importTimeLayouts are tried in order on creation times of imported csv files.
+var importTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05 -0700 MST", "2006-01-02 15:04:05", "2006-01-02"}
+
+type ImportResponse struct {
+	Imported  uint64           `json:"imported"`
+	Skipped   uint64           `json:"skipped"`   // imported before, under the same code in the same workspace
+	Conflicts []ImportConflict `json:"conflicts"` // imported under another code
+	Failures  []ImportFailure  `json:"failures"`  // not imported
+}
+
+// ImportConflict tells the code a url was imported under when the requested one could not be kept.
+type ImportConflict struct {
+	Row        int    `json:"row"`
+	Requested  string `json:"requested"`
+	ShortenURL string `json:"shorten_url"`
+	Reason     string `json:"reason"`
+}
+
+type ImportFailure struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// importRow is a url read from an imported file, Row counting urls from 1 regardless of the format.
+type importRow struct {
+	Row int
+	ExportedURL
+}
+
+// rowReader reads the urls of an imported file one at a time, returning io.EOF after the last one.
+// Errors about a single url are returned as rowError, reading goes on after them.
+type rowReader func() (importRow, error)
+
+type rowError struct {
+	row     int
+	message string
+}
+
+func (e rowError) Error() string {
+	return e.message
+}
+
+// newCSVRowReader reads csv files with a header naming columns of importColumns, others are ignored.
+// Cells escaped from being evaluated as formulas by exports are unescaped.
+func newCSVRowReader(r io.Reader) (rowReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("must have a header")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("must be a valid csv file: %v", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))), " ", "_")
+		if field, ok := importColumns[name]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["origin_url"]; !ok {
+		return nil, fmt.Errorf("must have an origin_url or long_url column")
+	}
+
+	row := 0
+	return func() (importRow, error) {
+		record, err := reader.Read()
+		if err != nil {
+			if err == io.EOF {
+				return importRow{}, err
+			}
+			return importRow{}, fmt.Errorf("must be a valid csv file: %v", err)
+		}
+		row++
+
+		get := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		u := importRow{Row: row, ExportedURL: ExportedURL{
+			ShortenURL: get("shorten_url"),
+			OriginURL:  unescapeFormula(get("origin_url")),
+			Domain:     get("domain"),
+			Title:      unescapeFormula(get("title")),
+			Folder:     unescapeFormula(get("folder")),
+			Note:       unescapeFormula(get("note")),
+		}}
+		if tags := unescapeFormula(get("tags")); tags != "" {
+			u.Tags = strings.Split(tags, ",")
+		}
+		if hits := get("hits"); hits != "" {
+			if u.Hits, err = strconv.ParseInt(hits, 10, 64); err != nil {
+				return u, rowError{row: row, message: "hits must be an integer"}
+			}
+		}
+		if createdAt := get("created_at"); createdAt != "" {
+			if u.CreatedAt, err = parseImportedTime(createdAt); err != nil {
+				return u, rowError{row: row, message: "created_at must be a date-time"}
+			}
+		}
+		return u, nil
+	}, nil
+}
+
+func parseImportedTime(value string) (time.Time, error) {
+	var err error
+	for _, layout := range importTimeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// newJSONLRowReader reads files with an ExportedURL on each line, blank lines are ignored.
+func newJSONLRowReader(r io.Reader) rowReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportedLineLength)
+
+	row := 0
+	return func() (importRow, error) {
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			row++
+
+			u := importRow{Row: row}
+			if err := json.Unmarshal([]byte(line), &u.ExportedURL); err != nil {
+				return u, rowError{row: row, message: "must be a valid json object"}
+			}
+			return u, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return importRow{}, fmt.Errorf("must be a valid json lines file: %v", err)
+		}
+		return importRow{}, io.EOF
+	}
+}
+
+// importer recreates urls read from a file in a workspace, remembering lookups shared by many of them.
+type importer struct {
+	db            database.MySQLService
+	logger        *logrus.Entry
+	user          database.User
+	workspaceID   string
+	defaultDomain string
+
+	hosts   map[string]bool // whether urls to host may be shortened
+	domains map[string]bool // whether domain is a verified domain of workspace
+	res     ImportResponse
+}
+
+// fail records that row was not imported because of message.
+func (i *importer) fail(row int, message string) {
+	i.res.Failures = append(i.res.Failures, ImportFailure{Row: row, Message: message})
+}
+
+// allowedHost tells whether urls to host may be shortened, which excludes this service and its custom domains.
+func (i *importer) allowedHost(host string) (bool, error) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if allowed, ok := i.hosts[host]; ok {
+		return allowed, nil
+	}
+
+	allowed := host != i.defaultDomain
+	if allowed {
+		_, err := i.db.GetDomain(host)
+		if _, ok := err.(database.RecordNotFoundError); !ok {
+			if err != nil {
+				return false, err
+			}
+			allowed = false
+		}
+	}
+	i.hosts[host] = allowed
+	return allowed, nil
+}
+
+// verifiedDomain tells whether domain is a verified custom domain of the workspace.
+func (i *importer) verifiedDomain(domain string) (bool, error) {
+	if verified, ok := i.domains[domain]; ok {
+		return verified, nil
+	}
+
+	d, err := i.db.GetDomain(domain)
+	if _, ok := err.(database.RecordNotFoundError); ok {
+		i.domains[domain] = false
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	i.domains[domain] = d.WorkspaceID == i.workspaceID && !d.VerifiedAt.IsZero()
+	return i.domains[domain], nil
+}
+
+// importURL validates u and recreates it, under its own code if it can be kept, notifying webhooks of the workspace.
+// An error is returned only if importing can not go on.
+func (i *importer) importURL(u importRow) error {
+	origin, err := url2.Parse(strings.TrimSpace(u.OriginURL))
+	if err != nil || (origin.Scheme != "http" && origin.Scheme != "https" && origin.Scheme != "ftp") || origin.Host == "" {
+		i.fail(u.Row, "origin_url must be a valid ftp, http or https url")
+		return nil
+	}
+	allowed, err := i.allowedHost(origin.Hostname())
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		i.fail(u.Row, "origin_url must not point to this service")
+		return nil
+	}
+
+	details := URLDetailsRequest{Title: &u.Title, Folder: &u.Folder, Note: &u.Note}
+	if len(u.Tags) > 0 {
+		details.Tags = &u.Tags
+	}
+	if errs := details.validate(); len(errs) > 0 {
+		messages := make([]string, len(errs))
+		for j, e := range errs {
+			messages[j] = e.Field + " " + e.Message
+		}
+		i.fail(u.Row, strings.Join(messages, ", "))
+		return nil
+	}
+	if u.Hits < 0 {
+		i.fail(u.Row, "hits must not be negative")
+		return nil
+	}
+
+	domain := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(u.Domain)), ".")
+	if domain != "" {
+		verified, err := i.verifiedDomain(domain)
+		if err != nil {
+			return err
+		}
+		if !verified {
+			i.fail(u.Row, "domain must be a verified domain of workspace")
+			return nil
+		}
+	}
+
+	url := database.URL{
+		OriginURL: origin.String(),
+		Owner:     i.workspaceID,
+		Domain:    domain,
+		Title:     *details.Title,
+		Folder:    *details.Folder,
+		Note:      *details.Note,
+		Count:     u.Hits,
+		CreatedAt: u.CreatedAt,
+	}
+	if details.Tags != nil {
+		url.Tags = *details.Tags
+	}
+
+	// note: links of other services are exported with their host, only the code of them is kept
+	requested := strings.TrimSpace(u.ShortenURL)
+	if slash := strings.LastIndex(strings.TrimSuffix(requested, "/"), "/"); slash >= 0 {
+		requested = strings.TrimSuffix(requested[slash+1:], "/")
+	}
+
+	reason := ""
+	if requested != "" && (len(requested) > maxImportedCodeLength || !shortener.IsServableCode(requested)) {
+		reason = "not a valid code"
+	}
+	for attempt := 0; attempt < shortener.MaxCreateAttempts; attempt++ {
+		if requested != "" && reason == "" {
+			url.ShortenURL = requested
+		} else if url.ShortenURL, err = shortener.NewShortenURL(); err != nil {
+			return err
+		}
+
+		err = i.db.ImportURL(url, i.user)
+		if _, ok := err.(database.ShortenURLTakenError); !ok {
+			break
+		}
+		if url.ShortenURL != requested {
+			continue
+		}
+
+		existing, err := i.db.GetURLWithShortenURL(requested)
+		if err == nil && existing.Owner == i.workspaceID && existing.Domain == domain && existing.OriginURL == url.OriginURL {
+			i.res.Skipped++
+			return nil
+		}
+		reason = "already taken"
+	}
+	if err != nil {
+		if _, ok := err.(database.ShortenURLTakenError); ok {
+			i.fail(u.Row, "no code is available")
+			return nil
+		}
+		return err
+	}
+
+	i.res.Imported++
+	now := time.Now()
+	url.CreatedBy = i.user.UserID
+	url.UpdatedAt = now
+	if url.CreatedAt.IsZero() {
+		url.CreatedAt = now
+	}
+	webhook.Notify(i.db, i.logger, database.WebhookEventURLCreated, url)
+
+	if url.ShortenURL != requested && requested != "" {
+		i.res.Conflicts = append(i.res.Conflicts, ImportConflict{
+			Row:        u.Row,
+			Requested:  requested,
+			ShortenURL: url.ShortenURL,
+			Reason:     reason,
+		})
+	}
+	return nil
+}
+
+// ImportURLsHandler recreates urls of a file in a workspace user is an editor of, reading it as it is uploaded.
+// Files are csv, including Bitly exports, or json lines as exported by ExportURLsHandler. Codes are kept unless
+// invalid or issued already, urls imported before under the same code are skipped. Urls are imported one by one,
+// so the ones before a malformed part of a file are kept.
+func ImportURLsHandler(defaultDomain string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		logger := logging.FromContext(context)
+
+		format := context.DefaultQuery("format", formatCSV)
+		if format != formatCSV && format != formatJSONL {
+			logger.WithField("format", format).Info("Unsupported import format")
+			server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+				Field:   "format",
+				Message: "must be one of csv, jsonl",
+			}))
+			return
+		}
+
+		workspaceID := workspace.FromQuery(context)
+		if !workspace.Authorize(context, logger, workspaceID, database.WorkspaceRoleEditor) {
+			return
+		}
+
+		body := http.MaxBytesReader(context.Writer, context.Request.Body, maxImportSize)
+		next := newJSONLRowReader(body)
+		if format == formatCSV {
+			var err error
+			if next, err = newCSVRowReader(body); err != nil {
+				logger.WithError(err).Info("Invalid csv file")
+				server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+					Field:   "body",
+					Message: err.Error(),
+				}))
+				return
+			}
+		}
+
+		user := context.Value("user").(*database.User)
+		i := importer{
+			db:            context.Value("db").(database.MySQLService),
+			logger:        logger,
+			user:          *user,
+			workspaceID:   workspaceID,
+			defaultDomain: defaultDomain,
+			hosts:         map[string]bool{},
+			domains:       map[string]bool{},
+			res: ImportResponse{
+				Conflicts: []ImportConflict{},
+				Failures:  []ImportFailure{},
+			},
+		}
+
+		for rows := 0; ; rows++ {
+			u, err := next()
+			if err == io.EOF {
+				break
+			}
+			if e, ok := err.(rowError); ok {
+				i.fail(e.row, e.message)
+				continue
+			}
+			if err != nil {
+				logger.WithError(err).Info("Import stopped at malformed file")
+				i.fail(rows+1, err.Error())
+				break
+			}
+			if rows == maxImportRows {
+				i.fail(u.Row, fmt.Sprintf("imports are limited to %v urls", maxImportRows))
+				break
+			}
+
+			if err := i.importURL(u); err != nil {
+				logger.WithError(err).WithField("row", u.Row).Error("Unable to import url")
+				if i.res.Imported == 0 {
+					server.Abort(context, server.InternalError)
+					return
+				}
+				i.fail(u.Row, "internal error, import stopped")
+				break
+			}
+		}
+
+		audit.Record(context, user.UserID, database.AuditActionURLsImport, database.AuditTargetWorkspace, workspaceID, nil, gin.H{
+			"format":    format,
+			"imported":  i.res.Imported,
+			"skipped":   i.res.Skipped,
+			"conflicts": len(i.res.Conflicts),
+			"failures":  len(i.res.Failures),
+		})
+		logger.WithField("imported", i.res.Imported).WithField("failures", len(i.res.Failures)).Info("Urls imported")
+
+		context.JSON(http.StatusOK, i.res)
+	}
+}
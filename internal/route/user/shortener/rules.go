@@ -0,0 +1,179 @@
+package shortener
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	url2 "net/url"
+	"regexp"
+	"strings"
+	"url-shortener/internal/cache"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/route/audit"
+	server "url-shortener/internal/route/error"
+	"url-shortener/internal/service/webhook"
+)
+
+const maxRedirectRules = 20
+
+var (
+	countryPattern  = regexp.MustCompile("^[A-Z]{2}$")
+	languagePattern = regexp.MustCompile("^[a-z]{2,3}$")
+)
+
+// RedirectRulesRequest replaces every redirect rule of a url, an empty list removes them.
+type RedirectRulesRequest struct {
+	Rules []RedirectRuleRequest `json:"rules"`
+}
+
+type RedirectRuleRequest struct {
+	Device      string `json:"device,omitempty"`
+	Country     string `json:"country,omitempty"`
+	Language    string `json:"language,omitempty"`
+	Destination string `json:"destination"`
+}
+
+type RedirectRulesResponse struct {
+	Rules []RedirectRuleRequest `json:"rules"`
+}
+
+// validate normalizes the request in place and returns every rule field violating the limits.
+func (r *RedirectRulesRequest) validate() []server.FieldError {
+	var errs []server.FieldError
+	if len(r.Rules) > maxRedirectRules {
+		errs = append(errs, server.FieldError{Field: "rules", Message: fmt.Sprintf("must have at most %v items", maxRedirectRules)})
+	}
+
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		field := func(name string) string {
+			return fmt.Sprintf("rules[%v].%v", i, name)
+		}
+
+		rule.Device = strings.ToLower(strings.TrimSpace(rule.Device))
+		rule.Country = strings.ToUpper(strings.TrimSpace(rule.Country))
+		rule.Language = strings.ToLower(strings.TrimSpace(rule.Language))
+		rule.Destination = strings.TrimSpace(rule.Destination)
+
+		if rule.Device == "" && rule.Country == "" && rule.Language == "" {
+			errs = append(errs, server.FieldError{Field: fmt.Sprintf("rules[%v]", i), Message: "must have at least one of device, country, language"})
+		}
+		switch rule.Device {
+		case "", database.DeviceIOS, database.DeviceAndroid, database.DeviceDesktop:
+		default:
+			errs = append(errs, server.FieldError{Field: field("device"), Message: "must be one of ios, android, desktop"})
+		}
+		if rule.Country != "" && !countryPattern.MatchString(rule.Country) {
+			errs = append(errs, server.FieldError{Field: field("country"), Message: "must be an ISO 3166-1 alpha-2 code"})
+		}
+		if rule.Language != "" && !languagePattern.MatchString(rule.Language) {
+			errs = append(errs, server.FieldError{Field: field("language"), Message: "must be a primary language subtag"})
+		}
+		if !isWebURL(rule.Destination) {
+			errs = append(errs, server.FieldError{Field: field("destination"), Message: "must be a valid http or https url"})
+		}
+	}
+
+	return errs
+}
+
+// isWebURL tells whether raw is an absolute http or https url.
+func isWebURL(raw string) bool {
+	u, err := url2.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func newRedirectRulesResponse(rules []database.RedirectRule) RedirectRulesResponse {
+	res := RedirectRulesResponse{Rules: make([]RedirectRuleRequest, len(rules))}
+	for i, rule := range rules {
+		res.Rules[i] = RedirectRuleRequest{
+			Device:      rule.Device,
+			Country:     rule.Country,
+			Language:    rule.Language,
+			Destination: rule.Destination,
+		}
+	}
+	return res
+}
+
+// GetRedirectRulesHandler lists redirect rules of a url in a workspace user is a member of, in the order they are evaluated.
+func GetRedirectRulesHandler(context *gin.Context) {
+	shortenURL := context.Param("shorten_url")
+	logger := logging.FromContext(context).WithField("shorten_url", shortenURL)
+
+	url, ok := getAuthorizedURL(context, logger, shortenURL, database.WorkspaceRoleViewer)
+	if !ok {
+		return
+	}
+
+	context.JSON(http.StatusOK, newRedirectRulesResponse(url.Rules))
+}
+
+// UpdateRedirectRulesHandler replaces redirect rules of a url in a workspace user is an editor of.
+func UpdateRedirectRulesHandler(context *gin.Context) {
+	shortenURL := context.Param("shorten_url")
+	logger := logging.FromContext(context).WithField("shorten_url", shortenURL)
+
+	r, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		logger.WithError(err).Error("Unable to read body properly")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	var req RedirectRulesRequest
+	if err := json.Unmarshal(r, &req); err != nil {
+		logger.WithError(err).Warn("Unexpected json string")
+		server.Abort(context, server.InvalidJSONStringError)
+		return
+	}
+	if errs := req.validate(); len(errs) > 0 {
+		logger.Info("Invalid redirect rules")
+		server.Abort(context, server.ValidationError.WithDetails(errs...))
+		return
+	}
+
+	stored, ok := getAuthorizedURL(context, logger, shortenURL, database.WorkspaceRoleEditor)
+	if !ok {
+		return
+	}
+
+	rules := make([]database.RedirectRule, len(req.Rules))
+	for i, rule := range req.Rules {
+		rules[i] = database.RedirectRule{
+			Device:      rule.Device,
+			Country:     rule.Country,
+			Language:    rule.Language,
+			Destination: rule.Destination,
+		}
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	if err := db.SetURLRules(shortenURL, rules); err != nil {
+		logger.WithError(err).Error("Unable to store redirect rules")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	cacheService := context.Value("cache-service").(cache.Service)
+	if err := cacheService.DelCachedURL(cache.DomainURL(stored.Domain, shortenURL)); err != nil {
+		logger.WithError(err).Warn("Unable to evict cached entity")
+	}
+
+	url, err := db.GetURLWithShortenURL(shortenURL)
+	if err != nil {
+		logger.WithError(err).Error("Error occurred when querying for updated url")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	user := context.Value("user").(*database.User)
+	audit.Record(context, user.UserID, database.AuditActionURLRulesUpdate, database.AuditTargetURL, shortenURL,
+		newRedirectRulesResponse(stored.Rules), newRedirectRulesResponse(rules))
+	webhook.Notify(db, logger, database.WebhookEventURLUpdated, *url)
+
+	context.JSON(http.StatusOK, newRedirectRulesResponse(rules))
+}
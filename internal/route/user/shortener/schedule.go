@@ -0,0 +1,217 @@
+package shortener
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+	"url-shortener/internal/cache"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/route/audit"
+	server "url-shortener/internal/route/error"
+	"url-shortener/internal/service/webhook"
+)
+
+const maxScheduleWindows = 20
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// ScheduleRequest replaces the schedule of a url, no windows make it active around the clock again.
+type ScheduleRequest struct {
+	Timezone     string                  `json:"timezone"` // defaults to UTC
+	Windows      []ScheduleWindowRequest `json:"windows"`
+	AlternateURL string                  `json:"alternate_url"` // empty answers not found outside of the windows
+}
+
+type ScheduleWindowRequest struct {
+	Days  []string `json:"days"`  // sun, mon, tue, wed, thu, fri, sat
+	Start string   `json:"start"` // HH:MM
+	End   string   `json:"end"`   // HH:MM up to 24:00, exclusive
+}
+
+// parseClock returns minutes since midnight of HH:MM.
+func parseClock(value string) (int, bool) {
+	var hour, minute int
+	if n, err := fmt.Sscanf(value, "%d:%d", &hour, &minute); err != nil || n != 2 || len(value) != 5 {
+		return 0, false
+	}
+	if hour < 0 || minute < 0 || minute > 59 || hour*60+minute > 24*60 {
+		return 0, false
+	}
+	return hour*60 + minute, true
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// toSchedule validates the request, returning every field violating the limits or the schedule it describes.
+func (r *ScheduleRequest) toSchedule() (*database.Schedule, []server.FieldError) {
+	var errs []server.FieldError
+
+	r.Timezone = strings.TrimSpace(r.Timezone)
+	if r.Timezone == "" {
+		r.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(r.Timezone); err != nil {
+		errs = append(errs, server.FieldError{Field: "timezone", Message: "must be an IANA time zone"})
+	}
+
+	r.AlternateURL = strings.TrimSpace(r.AlternateURL)
+	if r.AlternateURL != "" && !isWebURL(r.AlternateURL) {
+		errs = append(errs, server.FieldError{Field: "alternate_url", Message: "must be a valid http or https url"})
+	}
+
+	if len(r.Windows) > maxScheduleWindows {
+		errs = append(errs, server.FieldError{Field: "windows", Message: fmt.Sprintf("must have at most %v items", maxScheduleWindows)})
+	}
+
+	schedule := &database.Schedule{
+		Timezone:     r.Timezone,
+		Windows:      make([]database.ScheduleWindow, len(r.Windows)),
+		AlternateURL: r.AlternateURL,
+	}
+	for i, w := range r.Windows {
+		field := func(name string) string {
+			return fmt.Sprintf("windows[%v].%v", i, name)
+		}
+
+		window := database.ScheduleWindow{Days: []time.Weekday{}}
+		for _, name := range w.Days {
+			day := -1
+			for d, weekday := range weekdayNames {
+				if strings.ToLower(strings.TrimSpace(name)) == weekday {
+					day = d
+				}
+			}
+			if day < 0 {
+				errs = append(errs, server.FieldError{Field: field("days"), Message: "must be some of " + strings.Join(weekdayNames, ", ")})
+				break
+			}
+			window.Days = append(window.Days, time.Weekday(day))
+		}
+		if len(w.Days) == 0 {
+			errs = append(errs, server.FieldError{Field: field("days"), Message: "must not be empty"})
+		}
+
+		var okStart, okEnd bool
+		window.Start, okStart = parseClock(w.Start)
+		window.End, okEnd = parseClock(w.End)
+		if !okStart {
+			errs = append(errs, server.FieldError{Field: field("start"), Message: "must be a time of day as HH:MM"})
+		}
+		if !okEnd {
+			errs = append(errs, server.FieldError{Field: field("end"), Message: "must be a time of day as HH:MM, up to 24:00"})
+		}
+		if okStart && okEnd && window.End <= window.Start {
+			errs = append(errs, server.FieldError{Field: field("end"), Message: "must be after start, split windows spanning midnight"})
+		}
+		schedule.Windows[i] = window
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(schedule.Windows) == 0 {
+		return nil, nil
+	}
+	return schedule, nil
+}
+
+func newScheduleResponse(schedule *database.Schedule) ScheduleRequest {
+	if schedule == nil {
+		return ScheduleRequest{Timezone: "UTC", Windows: []ScheduleWindowRequest{}}
+	}
+
+	res := ScheduleRequest{
+		Timezone:     schedule.Timezone,
+		Windows:      make([]ScheduleWindowRequest, len(schedule.Windows)),
+		AlternateURL: schedule.AlternateURL,
+	}
+	for i, window := range schedule.Windows {
+		days := make([]string, len(window.Days))
+		for j, day := range window.Days {
+			days[j] = weekdayNames[day]
+		}
+		res.Windows[i] = ScheduleWindowRequest{
+			Days:  days,
+			Start: formatClock(window.Start),
+			End:   formatClock(window.End),
+		}
+	}
+	return res
+}
+
+// GetURLScheduleHandler tells when a url in a workspace user is a member of is active, no windows meaning always.
+func GetURLScheduleHandler(context *gin.Context) {
+	shortenURL := context.Param("shorten_url")
+	logger := logging.FromContext(context).WithField("shorten_url", shortenURL)
+
+	url, ok := getAuthorizedURL(context, logger, shortenURL, database.WorkspaceRoleViewer)
+	if !ok {
+		return
+	}
+
+	context.JSON(http.StatusOK, newScheduleResponse(url.Schedule))
+}
+
+// UpdateURLScheduleHandler replaces the schedule of a url in a workspace user is an editor of.
+func UpdateURLScheduleHandler(context *gin.Context) {
+	shortenURL := context.Param("shorten_url")
+	logger := logging.FromContext(context).WithField("shorten_url", shortenURL)
+
+	r, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		logger.WithError(err).Error("Unable to read body properly")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	var req ScheduleRequest
+	if err := json.Unmarshal(r, &req); err != nil {
+		logger.WithError(err).Warn("Unexpected json string")
+		server.Abort(context, server.InvalidJSONStringError)
+		return
+	}
+	schedule, errs := req.toSchedule()
+	if len(errs) > 0 {
+		logger.Info("Invalid url schedule")
+		server.Abort(context, server.ValidationError.WithDetails(errs...))
+		return
+	}
+
+	stored, ok := getAuthorizedURL(context, logger, shortenURL, database.WorkspaceRoleEditor)
+	if !ok {
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	if err := db.SetURLSchedule(shortenURL, schedule); err != nil {
+		logger.WithError(err).Error("Unable to store url schedule")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	cacheService := context.Value("cache-service").(cache.Service)
+	if err := cacheService.DelCachedURL(cache.DomainURL(stored.Domain, shortenURL)); err != nil {
+		logger.WithError(err).Warn("Unable to evict cached entity")
+	}
+
+	url, err := db.GetURLWithShortenURL(shortenURL)
+	if err != nil {
+		logger.WithError(err).Error("Error occurred when querying for updated url")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	user := context.Value("user").(*database.User)
+	audit.Record(context, user.UserID, database.AuditActionURLSchedule, database.AuditTargetURL, shortenURL,
+		newScheduleResponse(stored.Schedule), newScheduleResponse(schedule))
+	webhook.Notify(db, logger, database.WebhookEventURLUpdated, *url)
+
+	context.JSON(http.StatusOK, newScheduleResponse(schedule))
+}
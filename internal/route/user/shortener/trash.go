@@ -0,0 +1,125 @@
+package shortener
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+	"url-shortener/internal/cache"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/route/audit"
+	server "url-shortener/internal/route/error"
+	"url-shortener/internal/route/workspace"
+	"url-shortener/internal/service/webhook"
+)
+
+type TrashResponse struct {
+	URLs []TrashedURLResponse `json:"urls"`
+}
+
+// TrashedURLResponse is a deleted url, restorable until PurgeAt.
+type TrashedURLResponse struct {
+	URLResponse
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// GetTrashHandler lists deleted urls of a workspace user is a member of, the most recently deleted first.
+func GetTrashHandler(retention time.Duration) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		logger := logging.FromContext(context)
+
+		workspaceID := workspace.FromQuery(context)
+		if !workspace.Authorize(context, logger, workspaceID, database.WorkspaceRoleViewer) {
+			return
+		}
+
+		db := context.Value("db").(database.MySQLService)
+		urls, err := db.GetDeletedURLsInWorkspace(workspaceID)
+		if err != nil {
+			logger.WithError(err).Error("Unable to query for deleted urls")
+			server.Abort(context, server.InternalError)
+			return
+		}
+
+		resUrls := make([]TrashedURLResponse, len(urls))
+		for i, url := range urls {
+			resUrls[i] = TrashedURLResponse{
+				URLResponse: newURLResponse(url),
+				DeletedAt:   url.DeletedAt,
+				PurgeAt:     url.DeletedAt.Add(retention),
+			}
+		}
+
+		context.JSON(http.StatusOK, TrashResponse{URLs: resUrls})
+	}
+}
+
+// RestoreShortenUrlHandler takes a deleted url out of the trash of a workspace user is an editor of,
+// unless it was deleted longer than retention ago or its custom domain is no longer usable by the workspace.
+func RestoreShortenUrlHandler(retention time.Duration) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		shortenURL := context.Param("shorten_url")
+		logger := logging.FromContext(context).WithField("shorten_url", shortenURL)
+
+		db := context.Value("db").(database.MySQLService)
+		url, err := db.GetDeletedURL(shortenURL)
+		if err != nil {
+			if _, ok := err.(database.RecordNotFoundError); ok {
+				logger.Info("Given url not found in trash")
+				server.Abort(context, server.NotFoundError)
+				return
+			}
+			logger.WithError(err).Error("Error occurred when querying for deleted url")
+			server.Abort(context, server.InternalError)
+			return
+		}
+		if !workspace.Authorize(context, logger, url.Owner, database.WorkspaceRoleEditor) {
+			return
+		}
+		if time.Since(url.DeletedAt) > retention {
+			logger.WithField("deleted_at", url.DeletedAt).Info("Retention period of deleted url is over")
+			server.Abort(context, server.NotFoundError)
+			return
+		}
+
+		if url.Domain != "" {
+			d, err := db.GetDomain(url.Domain)
+			if err != nil {
+				if _, ok := err.(database.RecordNotFoundError); !ok {
+					logger.WithError(err).Error("Error occurred when querying for domain")
+					server.Abort(context, server.InternalError)
+					return
+				}
+			}
+			if d == nil || d.WorkspaceID != url.Owner || d.VerifiedAt.IsZero() {
+				logger.WithField("domain", url.Domain).Info("Domain of deleted url not usable by workspace")
+				server.Abort(context, server.RequestError.WithMessage("Domain of url is no longer a verified domain of workspace"))
+				return
+			}
+		}
+
+		if err := db.RestoreURL(shortenURL); err != nil {
+			if _, ok := err.(database.RecordNotFoundError); ok {
+				logger.Info("Given url not found in trash")
+				server.Abort(context, server.NotFoundError)
+				return
+			}
+			logger.WithError(err).Error("Unable to restore entity in database")
+			server.Abort(context, server.InternalError)
+			return
+		}
+
+		cacheService := context.Value("cache-service").(cache.Service)
+		if err := cacheService.DelCachedURL(cache.DomainURL(url.Domain, shortenURL)); err != nil {
+			logger.WithError(err).Warn("Unable to evict cached entity")
+		}
+
+		url.DeletedAt = time.Time{}
+		user := context.Value("user").(*database.User)
+		audit.Record(context, user.UserID, database.AuditActionURLRestore, database.AuditTargetURL, shortenURL, nil, newURLResponse(*url))
+		webhook.Notify(db, logger, database.WebhookEventURLUpdated, *url)
+
+		context.JSON(http.StatusOK, newURLResponse(*url))
+	}
+}
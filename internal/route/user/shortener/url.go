@@ -2,11 +2,17 @@ package shortener
 
 import (
 	"github.com/gin-gonic/gin"
-	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+	"url-shortener/internal/cache"
 	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/route/audit"
 	server "url-shortener/internal/route/error"
+	"url-shortener/internal/route/workspace"
+	"url-shortener/internal/service/webhook"
 )
 
 type URLsResponse struct {
@@ -14,52 +20,149 @@ type URLsResponse struct {
 	URLs  []URLResponse `json:"urls"`
 }
 
+type URLsPageResponse struct {
+	Total      uint64        `json:"total"`
+	URLs       []URLResponse `json:"urls"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
 type URLResponse struct {
-	OriginURL  string `json:"origin_url"`
-	ShortenURL string `json:"shorten_url"`
-	Hits       int64  `json:"hits"`
+	OriginURL      string               `json:"origin_url"`
+	Workspace      string               `json:"workspace"`
+	CreatedBy      string               `json:"created_by"`
+	Domain         string               `json:"domain"` // empty for the default domain
+	ShortenURL     string               `json:"shorten_url"`
+	RedirectStatus int                  `json:"redirect_status"`
+	Analytics      bool                 `json:"analytics"`
+	AppURI         string               `json:"app_uri"`
+	StartsAt       *time.Time           `json:"starts_at,omitempty"`
+	EndsAt         *time.Time           `json:"ends_at,omitempty"`
+	Hits           int64                `json:"hits"`
+	Title          string               `json:"title"`
+	Folder         string               `json:"folder"`
+	Note           string               `json:"note"`
+	Tags           []string             `json:"tags"`
+	Metadata       *URLMetadataResponse `json:"metadata,omitempty"`
+	BrokenSince    *time.Time           `json:"broken_since,omitempty"`
+	SuspendedAt    *time.Time           `json:"suspended_at,omitempty"` // set while reports are reviewed
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+}
+
+// URLMetadataResponse describes the destination page, as of FetchedAt.
+type URLMetadataResponse struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Image       string    `json:"image"`
+	Favicon     string    `json:"favicon"`
+	Status      int       `json:"status"`
+	Broken      bool      `json:"broken"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+func newURLResponse(url database.URL) URLResponse {
+	tags := url.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	var metadata *URLMetadataResponse
+	if m := url.Metadata; !m.FetchedAt.IsZero() {
+		metadata = &URLMetadataResponse{
+			Title:       m.Title,
+			Description: m.Description,
+			Image:       m.Image,
+			Favicon:     m.Favicon,
+			Status:      m.Status,
+			Broken:      m.Status == http.StatusNotFound || m.Status == http.StatusGone,
+			FetchedAt:   m.FetchedAt,
+		}
+	}
+	var brokenSince, suspendedAt, startsAt, endsAt *time.Time
+	if !url.BrokenSince.IsZero() {
+		brokenSince = &url.BrokenSince
+	}
+	if !url.SuspendedAt.IsZero() {
+		suspendedAt = &url.SuspendedAt
+	}
+	if !url.StartsAt.IsZero() {
+		startsAt = &url.StartsAt
+	}
+	if !url.EndsAt.IsZero() {
+		endsAt = &url.EndsAt
+	}
+	return URLResponse{
+		OriginURL:      url.OriginURL,
+		Workspace:      url.Owner,
+		CreatedBy:      url.CreatedBy,
+		Domain:         url.Domain,
+		ShortenURL:     url.ShortenURL,
+		RedirectStatus: database.RedirectStatusOf(url),
+		Analytics:      !url.AnalyticsDisabled,
+		AppURI:         url.AppURI,
+		StartsAt:       startsAt,
+		EndsAt:         endsAt,
+		Hits:           url.Count,
+		Title:          url.Title,
+		Folder:         url.Folder,
+		Note:           url.Note,
+		Tags:           tags,
+		Metadata:       metadata,
+		BrokenSince:    brokenSince,
+		SuspendedAt:    suspendedAt,
+		CreatedAt:      url.CreatedAt,
+		UpdatedAt:      url.UpdatedAt,
+	}
 }
 
 func GetShortenUrlsHandler(context *gin.Context) {
+	logger := logging.FromContext(context)
+
 	paramOffset := context.DefaultQuery("offset", "0")
 	paramLimit := context.DefaultQuery("limit", "100")
 
 	offset, err := strconv.ParseUint(paramOffset, 10, 64)
 	if err != nil {
-		log.Printf("Unable to decode query parameter offset: %v | Reason: %v\n", paramOffset, err)
-		context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.RequestError))
+		logger.WithError(err).WithField("offset", paramOffset).Warn("Unable to decode query parameter offset")
+		server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+			Field:   "offset",
+			Message: "must be a non-negative integer",
+		}))
 		return
 	}
 	limit, err := strconv.ParseUint(paramLimit, 10, 64)
 	if err != nil {
-		log.Printf("Unable to decode query parameter limit: %v | Reason: %v\n", paramLimit, err)
-		context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.RequestError))
+		logger.WithError(err).WithField("limit", paramLimit).Warn("Unable to decode query parameter limit")
+		server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+			Field:   "limit",
+			Message: "must be a non-negative integer",
+		}))
+		return
 	}
 	if limit < 1 || limit > 100 {
 		limit = 100
 	}
 
+	workspaceID := workspace.FromQuery(context)
+	if !workspace.Authorize(context, logger, workspaceID, database.WorkspaceRoleViewer) {
+		return
+	}
+
 	db := context.Value("db").(database.MySQLService)
-	user := context.Value("user").(*database.User)
-	total, urls, err := db.GetURLsWithUser(*user, offset, limit)
+	total, urls, err := db.GetURLsInWorkspace(workspaceID, urlFilterFromQuery(context), offset, limit)
 	if err != nil {
-		log.Printf("Unable to query for user's urls | Reason: %v\n", err)
-		context.AbortWithStatus(http.StatusInternalServerError)
+		logger.WithError(err).Error("Unable to query for user's urls")
+		server.Abort(context, server.InternalError)
 		return
 	}
 	if len(urls) == 0 {
-		log.Printf("No record found in database")
-		context.AbortWithStatus(http.StatusNotFound)
+		logger.Info("No record found in database")
+		server.Abort(context, server.NotFoundError)
 		return
 	}
 
 	resUrls := make([]URLResponse, len(urls))
 	for i, url := range urls {
-		resUrls[i] = URLResponse{
-			OriginURL:  url.OriginURL,
-			ShortenURL: url.ShortenURL,
-			Hits:       url.Count,
-		}
+		resUrls[i] = newURLResponse(url)
 	}
 
 	context.JSON(http.StatusOK, URLsResponse{
@@ -68,16 +171,112 @@ func GetShortenUrlsHandler(context *gin.Context) {
 	})
 }
 
+// GetShortenUrlsPageHandler lists user's urls page by page with opaque cursors, optionally sorted and searched.
+// Unlike GetShortenUrlsHandler, an empty page is not an error.
+func GetShortenUrlsPageHandler(context *gin.Context) {
+	logger := logging.FromContext(context)
+
+	sort := context.DefaultQuery("sort", database.URLSortCreated)
+	if sort != database.URLSortCreated && sort != database.URLSortUpdated && sort != database.URLSortHits {
+		logger.WithField("sort", sort).Info("Unsupported sort option")
+		server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+			Field:   "sort",
+			Message: "must be one of created, updated, hits",
+		}))
+		return
+	}
+
+	paramLimit := context.DefaultQuery("limit", "100")
+	limit, err := strconv.ParseUint(paramLimit, 10, 64)
+	if err != nil {
+		logger.WithError(err).WithField("limit", paramLimit).Info("Unable to decode query parameter limit")
+		server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+			Field:   "limit",
+			Message: "must be a non-negative integer",
+		}))
+		return
+	}
+	if limit < 1 || limit > 100 {
+		limit = 100
+	}
+
+	var after *database.URLCursor
+	if token := context.Query("cursor"); token != "" {
+		after, err = decodeCursor(sort, token)
+		if err != nil {
+			logger.WithError(err).Info("Unable to decode query parameter cursor")
+			server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+				Field:   "cursor",
+				Message: "must be a cursor returned by previous page with the same sort",
+			}))
+			return
+		}
+	}
+
+	workspaceID := workspace.FromQuery(context)
+	if !workspace.Authorize(context, logger, workspaceID, database.WorkspaceRoleViewer) {
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	total, urls, next, err := db.ListURLsInWorkspace(workspaceID, database.URLListQuery{
+		URLFilter: urlFilterFromQuery(context),
+		Sort:      sort,
+		Search:    strings.TrimSpace(context.Query("q")),
+		After:     after,
+		Limit:     limit,
+	})
+	if err != nil {
+		logger.WithError(err).Error("Unable to query for user's urls")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	resUrls := make([]URLResponse, len(urls))
+	for i, url := range urls {
+		resUrls[i] = newURLResponse(url)
+	}
+
+	context.JSON(http.StatusOK, URLsPageResponse{
+		Total:      total,
+		URLs:       resUrls,
+		NextCursor: encodeCursor(sort, next),
+	})
+}
+
+func urlFilterFromQuery(context *gin.Context) database.URLFilter {
+	return database.URLFilter{
+		Tag:    normalizeTag(context.Query("tag")),
+		Folder: strings.TrimSpace(context.Query("folder")),
+	}
+}
+
+// RemoveShortenUrlHandler moves a url in a workspace user is an editor of to the trash.
 func RemoveShortenUrlHandler(context *gin.Context) {
 	url := context.Param("shorten_url")
+	logger := logging.FromContext(context).WithField("shorten_url", url)
+	stored, ok := getAuthorizedURL(context, logger, url, database.WorkspaceRoleEditor)
+	if !ok {
+		return
+	}
 
 	db := context.Value("db").(database.MySQLService)
 	err := db.DeleteURL(url)
 	if err != nil {
-		log.Printf("Unable to delete entity %v in database | Reason: %v\n", url, err)
-		context.AbortWithStatus(http.StatusInternalServerError)
+		logger.WithError(err).Error("Unable to delete entity in database")
+		server.Abort(context, server.InternalError)
 		return
 	}
 
+	cacheService := context.Value("cache-service").(cache.Service)
+	if err := cacheService.DelCachedURL(cache.DomainURL(stored.Domain, url)); err != nil {
+		logger.WithError(err).Warn("Unable to evict cached entity")
+	}
+
+	user := context.Value("user").(*database.User)
+	audit.Record(context, user.UserID, database.AuditActionURLDelete, database.AuditTargetURL, url, newURLResponse(*stored), nil)
+	stored.DeletedAt = time.Now()
+	webhook.Notify(db, logger, database.WebhookEventURLDeleted, *stored)
+
 	context.Status(http.StatusOK)
 }
@@ -0,0 +1,156 @@
+package shortener
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"url-shortener/internal/cache"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/route/audit"
+	server "url-shortener/internal/route/error"
+	"url-shortener/internal/service/webhook"
+)
+
+const (
+	minURLVariants   = 2
+	maxURLVariants   = 10
+	maxVariantWeight = 1000
+)
+
+// URLVariantsRequest replaces every variant of a url, an empty list sends all visitors to the origin url again.
+// Hits of replaced variants are reset.
+type URLVariantsRequest struct {
+	Variants []URLVariantRequest `json:"variants"`
+}
+
+type URLVariantRequest struct {
+	Destination string `json:"destination"`
+	Weight      int    `json:"weight"`
+}
+
+type URLVariantsResponse struct {
+	Variants []URLVariantResponse `json:"variants"`
+}
+
+type URLVariantResponse struct {
+	ID          uint64 `json:"id"`
+	Destination string `json:"destination"`
+	Weight      int    `json:"weight"`
+	Hits        int64  `json:"hits"`
+}
+
+// validate normalizes the request in place and returns every variant field violating the limits.
+func (r *URLVariantsRequest) validate() []server.FieldError {
+	var errs []server.FieldError
+	if len(r.Variants) > 0 && (len(r.Variants) < minURLVariants || len(r.Variants) > maxURLVariants) {
+		errs = append(errs, server.FieldError{Field: "variants", Message: fmt.Sprintf("must have none or between %v and %v items", minURLVariants, maxURLVariants)})
+	}
+
+	for i := range r.Variants {
+		variant := &r.Variants[i]
+		variant.Destination = strings.TrimSpace(variant.Destination)
+
+		if !isWebURL(variant.Destination) {
+			errs = append(errs, server.FieldError{Field: fmt.Sprintf("variants[%v].destination", i), Message: "must be a valid http or https url"})
+		}
+		if variant.Weight < 1 || variant.Weight > maxVariantWeight {
+			errs = append(errs, server.FieldError{Field: fmt.Sprintf("variants[%v].weight", i), Message: fmt.Sprintf("must be between 1 and %v", maxVariantWeight)})
+		}
+	}
+
+	return errs
+}
+
+func newURLVariantsResponse(variants []database.URLVariant) URLVariantsResponse {
+	res := URLVariantsResponse{Variants: make([]URLVariantResponse, len(variants))}
+	for i, variant := range variants {
+		res.Variants[i] = URLVariantResponse{
+			ID:          variant.ID,
+			Destination: variant.Destination,
+			Weight:      variant.Weight,
+			Hits:        variant.Hits,
+		}
+	}
+	return res
+}
+
+// GetURLVariantsHandler lists variants of a url in a workspace user is a member of, with the hits of each.
+func GetURLVariantsHandler(context *gin.Context) {
+	shortenURL := context.Param("shorten_url")
+	logger := logging.FromContext(context).WithField("shorten_url", shortenURL)
+
+	url, ok := getAuthorizedURL(context, logger, shortenURL, database.WorkspaceRoleViewer)
+	if !ok {
+		return
+	}
+
+	context.JSON(http.StatusOK, newURLVariantsResponse(url.Variants))
+}
+
+// UpdateURLVariantsHandler replaces variants of a url in a workspace user is an editor of.
+func UpdateURLVariantsHandler(context *gin.Context) {
+	shortenURL := context.Param("shorten_url")
+	logger := logging.FromContext(context).WithField("shorten_url", shortenURL)
+
+	r, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		logger.WithError(err).Error("Unable to read body properly")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	var req URLVariantsRequest
+	if err := json.Unmarshal(r, &req); err != nil {
+		logger.WithError(err).Warn("Unexpected json string")
+		server.Abort(context, server.InvalidJSONStringError)
+		return
+	}
+	if errs := req.validate(); len(errs) > 0 {
+		logger.Info("Invalid url variants")
+		server.Abort(context, server.ValidationError.WithDetails(errs...))
+		return
+	}
+
+	stored, ok := getAuthorizedURL(context, logger, shortenURL, database.WorkspaceRoleEditor)
+	if !ok {
+		return
+	}
+
+	variants := make([]database.URLVariant, len(req.Variants))
+	for i, variant := range req.Variants {
+		variants[i] = database.URLVariant{
+			Destination: variant.Destination,
+			Weight:      variant.Weight,
+		}
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	if err := db.SetURLVariants(shortenURL, variants); err != nil {
+		logger.WithError(err).Error("Unable to store url variants")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	cacheService := context.Value("cache-service").(cache.Service)
+	if err := cacheService.DelCachedURL(cache.DomainURL(stored.Domain, shortenURL)); err != nil {
+		logger.WithError(err).Warn("Unable to evict cached entity")
+	}
+
+	url, err := db.GetURLWithShortenURL(shortenURL)
+	if err != nil {
+		logger.WithError(err).Error("Error occurred when querying for updated url")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	user := context.Value("user").(*database.User)
+	audit.Record(context, user.UserID, database.AuditActionURLVariants, database.AuditTargetURL, shortenURL,
+		newURLVariantsResponse(stored.Variants), newURLVariantsResponse(url.Variants))
+	webhook.Notify(db, logger, database.WebhookEventURLUpdated, *url)
+
+	context.JSON(http.StatusOK, newURLVariantsResponse(url.Variants))
+}
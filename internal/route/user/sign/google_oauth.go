@@ -4,16 +4,17 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
-	"fmt"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 	"io/ioutil"
-	"log"
 	"math/rand"
 	"net/http"
 	"strings"
 	"time"
 	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/route/audit"
 	server "url-shortener/internal/route/error"
 	"url-shortener/internal/util"
 )
@@ -28,37 +29,39 @@ func GoogleSignHandler(useHttps bool) gin.HandlerFunc {
 
 func GoogleSignCallbackHandler(jwtKey []byte, baseUrl string) gin.HandlerFunc {
 	return func(context *gin.Context) {
+		logger := logging.FromContext(context)
+
 		oauthState, err := context.Cookie("oauthstate")
 		if err != nil {
-			log.Printf("Failed to fetch oauthState: %v\n", err)
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.RequestError))
+			logger.WithError(err).Warn("Failed to fetch oauthState")
+			server.Abort(context, server.RequestError)
 			return
 		}
 		if oauthState != context.Query("state") {
-			log.Printf("oauthState verification failed: oauthState=%v queryStr=%v\n", oauthState, context.Query("state"))
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.RequestError))
+			logger.Warn("oauthState verification failed")
+			server.Abort(context, server.RequestError)
 			return
 		}
 
-		userOauthInfo, err := extractUserInfoFromGoogleToken(context.Query("code"))
+		userOauthInfo, err := extractUserInfoFromGoogleToken(context.Query("code"), logger)
 		if err != nil {
-			log.Printf("Unable to extract user info with code: %v | Reason: %v\n", context.Query("code"), err)
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.RequestError))
+			logger.WithError(err).Warn("Unable to extract user info with code")
+			server.Abort(context, server.RequestError)
 			return
 		}
 
 		db := context.Value("db").(database.MySQLService)
 		uuid, err := util.NewUUID()
 		if err != nil {
-			log.Printf("Error occurred when generating uuid | Reason: %v\n", err)
-			context.AbortWithStatus(http.StatusInternalServerError)
+			logger.WithError(err).Error("Error occurred when generating uuid")
+			server.Abort(context, server.InternalError)
 			return
 		}
 
-		_, err = db.GetUserWithEmail(strings.ToLower(userOauthInfo.Email))
+		userInfo, err := db.GetUserWithEmail(strings.ToLower(userOauthInfo.Email))
 		if err != nil {
 			if _, ok := err.(database.RecordNotFoundError); ok {
-				log.Printf("User not registered\n")
+				logger.Info("User not registered")
 				err = db.CreateGoogleUser(database.User{
 					UserID: uuid,
 					Email:  strings.ToLower(userOauthInfo.Email),
@@ -68,17 +71,28 @@ func GoogleSignCallbackHandler(jwtKey []byte, baseUrl string) gin.HandlerFunc {
 					GoogleUUID: userOauthInfo.Sub,
 				})
 				if err != nil {
-					log.Printf("Error occurred when creating user in database | Reason: %v\n", err)
-					context.AbortWithStatus(http.StatusInternalServerError)
+					logger.WithError(err).Error("Error occurred when creating user in database")
+					server.Abort(context, server.InternalError)
 					return
 				}
+				userInfo = &database.User{UserID: uuid}
+				audit.Record(context, uuid, database.AuditActionSignUp, database.AuditTargetUser, uuid, nil, gin.H{
+					"email": strings.ToLower(userOauthInfo.Email),
+					"type":  database.UserTypeGoogle,
+				})
 			} else {
-				fmt.Printf("Unable to check whether user is registered | Reason: %v\n", err)
-				context.AbortWithStatus(http.StatusInternalServerError)
+				logger.WithError(err).Error("Unable to check whether user is registered")
+				server.Abort(context, server.InternalError)
 				return
 			}
 		}
-		log.Printf("User has registered\n")
+		logger.Info("User has registered")
+
+		if userInfo.Disabled {
+			logger.WithField("user_id", userInfo.UserID).Info("This user is disabled")
+			server.Abort(context, server.AccountDisabledError)
+			return
+		}
 
 		unsignedToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 			"type":   database.UserTypeGoogle,
@@ -86,6 +100,7 @@ func GoogleSignCallbackHandler(jwtKey []byte, baseUrl string) gin.HandlerFunc {
 			"issued": time.Now().Unix(),
 		})
 		issuedToken, err := unsignedToken.SignedString(jwtKey)
+		audit.Record(context, userInfo.UserID, database.AuditActionSignIn, database.AuditTargetUser, userInfo.UserID, nil, nil)
 
 		context.HTML(http.StatusOK, "google_oauth_callback.tmpl", gin.H{
 			"token":   issuedToken,
@@ -117,10 +132,10 @@ type googleOauthUserInfo struct {
 	Locale        string `json:"locale"`
 }
 
-func extractUserInfoFromGoogleToken(code string) (*googleOauthUserInfo, error) {
+func extractUserInfoFromGoogleToken(code string, logger *logrus.Entry) (*googleOauthUserInfo, error) {
 	token, err := oauthConf.Exchange(context.Background(), code)
 	if err != nil {
-		log.Println("Unable to get token from authorization code")
+		logger.WithError(err).Warn("Unable to get token from authorization code")
 		return nil, err
 	}
 
@@ -128,20 +143,20 @@ func extractUserInfoFromGoogleToken(code string) (*googleOauthUserInfo, error) {
 
 	response, err := client.Get("https://www.googleapis.com/oauth2/v3/userinfo")
 	if err != nil {
-		log.Println("Unable to fetch user info from google apis")
+		logger.WithError(err).Warn("Unable to fetch user info from google apis")
 		return nil, err
 	}
 	defer response.Body.Close()
 
 	content, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		log.Printf("Unable to read response body\n")
+		logger.WithError(err).Warn("Unable to read response body")
 		return nil, err
 	}
 
 	var userInfo googleOauthUserInfo
 	if err = json.Unmarshal(content, &userInfo); err != nil {
-		log.Printf("Unable to parse response body as json obj\n")
+		logger.WithError(err).Warn("Unable to parse response body as json obj")
 		return nil, err
 	}
 
@@ -5,11 +5,12 @@ import (
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"strings"
 	"time"
 	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/route/audit"
 	server "url-shortener/internal/route/error"
 	"url-shortener/internal/util"
 )
@@ -21,24 +22,29 @@ type Auth struct {
 
 func UserSignInHandler(jwtKey []byte) gin.HandlerFunc {
 	return func(context *gin.Context) {
+		logger := logging.FromContext(context)
+
 		body := context.Request.Body
 		r, err := ioutil.ReadAll(body)
 		if err != nil {
-			log.Printf("Unable to read body properly | Reason: %v\n", err)
-			context.AbortWithStatus(http.StatusInternalServerError)
+			logger.WithError(err).Error("Unable to read body properly")
+			server.Abort(context, server.InternalError)
 			return
 		}
 
 		var auth Auth
 		err = json.Unmarshal(r, &auth)
 		if err != nil {
-			log.Printf("Unexpected json string: %v | Reason: %v\n", string(r), err)
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.InvalidJSONStringError))
+			logger.WithError(err).Warn("Unexpected json string")
+			server.Abort(context, server.InvalidJSONStringError)
 			return
 		}
 		if !util.CheckEmailIfValid(auth.Email) {
-			log.Printf("Email is not valid: %v\n", auth.Email)
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.EmailValidationError))
+			logger.Info("Email is not valid")
+			server.Abort(context, server.EmailValidationError.WithDetails(server.FieldError{
+				Field:   "email",
+				Message: "must be a valid email address",
+			}))
 			return
 		}
 		// TODO: password validation and sanitation
@@ -47,25 +53,31 @@ func UserSignInHandler(jwtKey []byte) gin.HandlerFunc {
 		userInfo, err := db.GetUserWithEmail(strings.ToLower(auth.Email))
 		if err != nil {
 			if _, ok := err.(database.RecordNotFoundError); ok {
-				log.Printf("This user not found in database")
-				context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.AuthenticationError))
+				logger.Info("This user not found in database")
+				server.Abort(context, server.CredentialsError)
 				return
 			}
 
-			log.Printf("Unable to query for user info in database | Reason: %v\n", err)
-			context.AbortWithStatus(http.StatusInternalServerError)
+			logger.WithError(err).Error("Unable to query for user info in database")
+			server.Abort(context, server.InternalError)
 			return
 		}
 
 		if userInfo.Type != "local" {
-			log.Printf("This user doesn't belong to this login type: %v\n", userInfo.Type)
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.AuthenticationError))
+			logger.WithField("user_type", userInfo.Type).Info("This user doesn't belong to this login type")
+			server.Abort(context, server.CredentialsError)
 			return
 		}
 
 		if !util.CheckPasswordHash(auth.Password, userInfo.Password) {
-			log.Printf("Password hash mismatch")
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.AuthenticationError))
+			logger.Info("Password hash mismatch")
+			server.Abort(context, server.CredentialsError)
+			return
+		}
+
+		if userInfo.Disabled {
+			logger.WithField("user_id", userInfo.UserID).Info("This user is disabled")
+			server.Abort(context, server.AccountDisabledError)
 			return
 		}
 
@@ -75,6 +87,7 @@ func UserSignInHandler(jwtKey []byte) gin.HandlerFunc {
 			"issued": time.Now().Unix(),
 		})
 		issuedToken, err := unsignedToken.SignedString(jwtKey)
+		audit.Record(context, userInfo.UserID, database.AuditActionSignIn, database.AuditTargetUser, userInfo.UserID, nil, nil)
 
 		context.JSON(http.StatusOK, gin.H{
 			"issueToken": issuedToken,
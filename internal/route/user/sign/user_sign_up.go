@@ -7,13 +7,14 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis"
 	"io/ioutil"
-	"log"
 	"math/big"
 	"net/http"
 	"strings"
 	"time"
 	"url-shortener/internal/cache"
 	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	"url-shortener/internal/route/audit"
 	server "url-shortener/internal/route/error"
 	"url-shortener/internal/service/mail"
 	"url-shortener/internal/util"
@@ -26,32 +27,39 @@ type signUpCompletion struct {
 
 func UserSignUpCompletionHandler(emailVerificationIgnored bool) gin.HandlerFunc {
 	return func(context *gin.Context) {
+		logger := logging.FromContext(context)
+
 		body := context.Request.Body
 		r, err := ioutil.ReadAll(body)
 		if err != nil {
-			log.Printf("Unable to read body properly | Reason: %v\n", err)
-			context.AbortWithStatus(http.StatusInternalServerError)
+			logger.WithError(err).Error("Unable to read body properly")
+			server.Abort(context, server.InternalError)
 			return
 		}
 
 		var verification signUpCompletion
 		err = json.Unmarshal(r, &verification)
 		if err != nil {
-			log.Printf("Unexpected json string: %v | Reason: %v\n", string(r), err)
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.InvalidJSONStringError))
+			logger.WithError(err).Warn("Unexpected json string")
+			server.Abort(context, server.InvalidJSONStringError)
 			return
 		}
 
 		if !util.CheckEmailIfValid(verification.Email) {
-			log.Printf("Email is not valid\n")
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.EmailValidationError))
+			logger.Info("Email is not valid")
+			server.Abort(context, server.EmailValidationError.WithDetails(server.FieldError{
+				Field:   "email",
+				Message: "must be a valid email address",
+			}))
 			return
 		}
 
-		fmt.Printf("code %v", verification.Code)
 		if !util.IsOnlySixDigits(verification.Code) {
-			log.Printf("Code is not valid\n")
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.CodeValidationError))
+			logger.Info("Code is not valid")
+			server.Abort(context, server.CodeValidationError.WithDetails(server.FieldError{
+				Field:   "code",
+				Message: "must consist of six digits",
+			}))
 			return
 		}
 
@@ -59,39 +67,39 @@ func UserSignUpCompletionHandler(emailVerificationIgnored bool) gin.HandlerFunc
 		ck := cacheKey{Email: strings.ToLower(verification.Email)}
 		code, err := c.Get(ck.CodeKey())
 		if err == redis.Nil {
-			log.Printf("No relevant registration info: code found in cache\n")
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.CodeValidationError))
+			logger.Info("No relevant registration info: code found in cache")
+			server.Abort(context, server.CodeValidationError)
 			return
 		}
 		if err != nil {
-			log.Printf("Error occurred when getting code in cache | Reason: %v\n", err)
-			context.AbortWithStatus(http.StatusInternalServerError)
+			logger.WithError(err).Error("Error occurred when getting code in cache")
+			server.Abort(context, server.InternalError)
 			return
 		}
 
 		if !emailVerificationIgnored && verification.Code != code {
-			log.Printf("Code mismatch\n")
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.CodeValidationError))
+			logger.Info("Code mismatch")
+			server.Abort(context, server.CodeValidationError)
 			return
 		}
 
 		hashedPassword, err := c.Get(ck.PasswordKey())
 		if err == redis.Nil {
-			log.Printf("No relevant registration info: password found in cache\n")
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.CodeValidationError))
+			logger.Info("No relevant registration info: password found in cache")
+			server.Abort(context, server.CodeValidationError)
 			return
 		}
 		if err != nil {
-			log.Printf("Error occurred when getting password in cache | Reason: %v\n", err)
-			context.AbortWithStatus(http.StatusInternalServerError)
+			logger.WithError(err).Error("Error occurred when getting password in cache")
+			server.Abort(context, server.InternalError)
 			return
 		}
 
 		db := context.Value("db").(database.MySQLService)
 		uuid, err := util.NewUUID()
 		if err != nil {
-			log.Printf("Error occurred when generating uuid | Reason: %v\n", err)
-			context.AbortWithStatus(http.StatusInternalServerError)
+			logger.WithError(err).Error("Error occurred when generating uuid")
+			server.Abort(context, server.InternalError)
 			return
 		}
 		err = db.CreateUser(database.User{
@@ -101,35 +109,46 @@ func UserSignUpCompletionHandler(emailVerificationIgnored bool) gin.HandlerFunc
 			Password: hashedPassword,
 		})
 		if err != nil {
-			log.Printf("Error occurred when creating user in database | Reason: %v\n", err)
-			context.AbortWithStatus(http.StatusInternalServerError)
+			logger.WithError(err).Error("Error occurred when creating user in database")
+			server.Abort(context, server.InternalError)
 			return
 		}
+		audit.Record(context, uuid, database.AuditActionSignUp, database.AuditTargetUser, uuid, nil, gin.H{
+			"email": strings.ToLower(verification.Email),
+			"type":  database.UserTypeLocal,
+		})
 
-		context.String(http.StatusOK, "Registered successfully")
+		context.JSON(http.StatusOK, gin.H{
+			"message": "Registered successfully",
+		})
 	}
 }
 
 func UserSignUpHandler(emailRequest chan<- mail.SendEmailOptions, emailVerificationIgnored bool) gin.HandlerFunc {
 	return func(context *gin.Context) {
+		logger := logging.FromContext(context)
+
 		body := context.Request.Body
 		r, err := ioutil.ReadAll(body)
 		if err != nil {
-			log.Printf("Unable to read body properly | Reason: %v\n", err)
-			context.AbortWithStatus(http.StatusInternalServerError)
+			logger.WithError(err).Error("Unable to read body properly")
+			server.Abort(context, server.InternalError)
 			return
 		}
 
 		var auth Auth
 		err = json.Unmarshal(r, &auth)
 		if err != nil {
-			log.Printf("Unexpected json string: %v | Reason: %v\n", string(r), err)
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.InvalidJSONStringError))
+			logger.WithError(err).Warn("Unexpected json string")
+			server.Abort(context, server.InvalidJSONStringError)
 			return
 		}
 		if !util.CheckEmailIfValid(auth.Email) {
-			log.Printf("Email is not valid\n")
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.EmailValidationError))
+			logger.Info("Email is not valid")
+			server.Abort(context, server.EmailValidationError.WithDetails(server.FieldError{
+				Field:   "email",
+				Message: "must be a valid email address",
+			}))
 			return
 		}
 
@@ -137,26 +156,29 @@ func UserSignUpHandler(emailRequest chan<- mail.SendEmailOptions, emailVerificat
 		_, err = db.GetUserWithEmail(strings.ToLower(auth.Email))
 		if err != nil {
 			if _, ok := err.(database.RecordNotFoundError); !ok {
-				log.Printf("Unable to query for user info in database | Reason: %v\n", err)
-				context.AbortWithStatus(http.StatusInternalServerError)
+				logger.WithError(err).Error("Unable to query for user info in database")
+				server.Abort(context, server.InternalError)
 				return
 			}
 		} else {
-			log.Printf("This user is registered in database\n")
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.AlreadyRegisteredError))
+			logger.Info("This user is registered in database")
+			server.Abort(context, server.AlreadyRegisteredError)
 			return
 		}
 
 		if len(auth.Password) > 20 || len(auth.Password) < 6 {
-			log.Printf("Password is too weak or too long\n")
-			context.AbortWithStatusJSON(http.StatusBadRequest, server.NewResponseErrorWithMessage(server.PasswordValidationError))
+			logger.Info("Password is too weak or too long")
+			server.Abort(context, server.PasswordValidationError.WithDetails(server.FieldError{
+				Field:   "password",
+				Message: "must be between 6 and 20 characters",
+			}))
 			return
 		}
 		// TODO: sanitation
 		hashedPassword, err := util.HashPassword(auth.Password)
 		if err != nil {
-			log.Printf("Error occurred when hashing password | Reason: %v\n", err)
-			context.AbortWithStatus(http.StatusInternalServerError)
+			logger.WithError(err).Error("Error occurred when hashing password")
+			server.Abort(context, server.InternalError)
 			return
 		}
 
@@ -168,22 +190,24 @@ func UserSignUpHandler(emailRequest chan<- mail.SendEmailOptions, emailVerificat
 
 		_code, err := rand.Int(rand.Reader, big.NewInt(999999))
 		if err != nil {
-			log.Printf(fmt.Sprintf("Failure on generating verification code | Reason: %v\n", err))
-			context.AbortWithStatus(http.StatusInternalServerError)
+			logger.WithError(err).Error("Failure on generating verification code")
+			server.Abort(context, server.InternalError)
 			return
 		}
 		code := fmt.Sprintf("%06d", _code.Uint64())
 		tx.Set(ck.CodeKey(), code, expiration)
 		_, err = tx.Exec()
 		if err != nil {
-			log.Printf(fmt.Sprintf("Failure on generating verification code | Reason: %v\n", err))
-			context.AbortWithStatus(http.StatusInternalServerError)
+			logger.WithError(err).Error("Failure on generating verification code")
+			server.Abort(context, server.InternalError)
 			return
 		}
 
 		if emailVerificationIgnored {
-			log.Printf("Warning: Registration request accepted without email verification\n")
-			context.String(http.StatusOK, "Registration request accepted (without email verification)")
+			logger.Warn("Registration request accepted without email verification")
+			context.JSON(http.StatusOK, gin.H{
+				"message": "Registration request accepted (without email verification)",
+			})
 			return
 		}
 
@@ -195,7 +219,9 @@ func UserSignUpHandler(emailRequest chan<- mail.SendEmailOptions, emailVerificat
 			}
 		}()
 
-		context.String(http.StatusOK, "Registration request accepted")
+		context.JSON(http.StatusOK, gin.H{
+			"message": "Registration request accepted",
+		})
 		return
 	}
 }
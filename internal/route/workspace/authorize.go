@@ -0,0 +1,47 @@
+package workspace
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"url-shortener/internal/database"
+	server "url-shortener/internal/route/error"
+)
+
+// FromQuery returns the workspace selected by query parameter workspace, defaulting to personal workspace of user.
+func FromQuery(context *gin.Context) string {
+	if workspaceID := strings.TrimSpace(context.Query("workspace")); workspaceID != "" {
+		return workspaceID
+	}
+	return context.Value("user").(*database.User).UserID
+}
+
+// Authorize aborts the request unless its user holds at least required role in workspace.
+// Workspaces user is not a member of are reported as not found, so their existence is not disclosed.
+func Authorize(context *gin.Context, logger *logrus.Entry, workspaceID string, required string) bool {
+	db := context.Value("db").(database.MySQLService)
+	user := context.Value("user").(*database.User)
+
+	role, err := db.GetWorkspaceRole(workspaceID, user.UserID)
+	if err != nil {
+		if _, ok := err.(database.RecordNotFoundError); ok {
+			logger.WithField("workspace_id", workspaceID).Info("User is not a member of workspace")
+			server.Abort(context, server.NotFoundError)
+			return false
+		}
+		logger.WithError(err).Error("Unable to query for role in workspace")
+		server.Abort(context, server.InternalError)
+		return false
+	}
+
+	if !database.WorkspaceRoleAllows(role, required) {
+		logger.WithFields(logrus.Fields{
+			"workspace_id": workspaceID,
+			"role":         role,
+		}).Info("Role of user does not permit the operation")
+		server.Abort(context, server.ForbiddenError.WithMessage("Requires "+required+" role in workspace"))
+		return false
+	}
+
+	return true
+}
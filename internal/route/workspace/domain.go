@@ -0,0 +1,269 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	server "url-shortener/internal/route/error"
+	"url-shortener/internal/service/domain"
+)
+
+const verificationTimeout = 5 * time.Second
+
+type DomainRequest struct {
+	Hostname string `json:"hostname"`
+}
+
+type DomainResponse struct {
+	Hostname     string             `json:"hostname"`
+	Verified     bool               `json:"verified"`
+	VerifiedAt   *time.Time         `json:"verified_at,omitempty"`
+	CreatedAt    time.Time          `json:"created_at"`
+	Verification VerificationRecord `json:"verification"`
+}
+
+// VerificationRecord tells what to publish in DNS to prove control of the domain.
+type VerificationRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type DomainsResponse struct {
+	Domains []DomainResponse `json:"domains"`
+}
+
+func newDomainResponse(d database.Domain) DomainResponse {
+	res := DomainResponse{
+		Hostname:  d.Hostname,
+		Verified:  !d.VerifiedAt.IsZero(),
+		CreatedAt: d.CreatedAt,
+		Verification: VerificationRecord{
+			Type:  "TXT",
+			Name:  domain.RecordName(d.Hostname),
+			Value: domain.RecordValue(d.VerificationToken),
+		},
+	}
+	if res.Verified {
+		verifiedAt := d.VerifiedAt
+		res.VerifiedAt = &verifiedAt
+	}
+	return res
+}
+
+func GetDomainsHandler(context *gin.Context) {
+	workspaceID := context.Param("workspace_id")
+	logger := logging.FromContext(context).WithField("workspace_id", workspaceID)
+	if !Authorize(context, logger, workspaceID, database.WorkspaceRoleViewer) {
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	domains, err := db.GetDomainsInWorkspace(workspaceID)
+	if err != nil {
+		logger.WithError(err).Error("Unable to query for domains of workspace")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	resDomains := make([]DomainResponse, len(domains))
+	for i, d := range domains {
+		resDomains[i] = newDomainResponse(d)
+	}
+
+	context.JSON(http.StatusOK, DomainsResponse{Domains: resDomains})
+}
+
+// CreateDomainHandler registers a claim of workspace on a custom domain. Links can use it once its DNS record is
+// verified, until then other workspaces can claim it too and the first one verifying it gets it.
+func CreateDomainHandler(baseDomain string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		workspaceID := context.Param("workspace_id")
+		logger := logging.FromContext(context).WithField("workspace_id", workspaceID)
+		if !Authorize(context, logger, workspaceID, database.WorkspaceRoleOwner) {
+			return
+		}
+
+		r, err := ioutil.ReadAll(context.Request.Body)
+		if err != nil {
+			logger.WithError(err).Error("Unable to read body properly")
+			server.Abort(context, server.InternalError)
+			return
+		}
+
+		var req DomainRequest
+		if err := json.Unmarshal(r, &req); err != nil {
+			logger.WithError(err).Warn("Unexpected json string")
+			server.Abort(context, server.InvalidJSONStringError)
+			return
+		}
+		hostname, err := domain.Normalize(req.Hostname)
+		if err != nil {
+			logger.WithField("hostname", req.Hostname).Info("Invalid hostname")
+			server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+				Field:   "hostname",
+				Message: "must be a fully qualified domain name",
+			}))
+			return
+		}
+		if hostname == baseDomain || strings.HasSuffix(hostname, "."+baseDomain) {
+			logger.WithField("hostname", hostname).Info("Refused to register domain of this service")
+			server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+				Field:   "hostname",
+				Message: "must not be a domain of this service",
+			}))
+			return
+		}
+
+		db := context.Value("db").(database.MySQLService)
+		if _, err := db.GetDomain(hostname); err == nil {
+			logger.WithField("hostname", hostname).Info("Domain already registered")
+			server.Abort(context, server.RequestError.WithMessage("Domain is already registered"))
+			return
+		} else if _, ok := err.(database.RecordNotFoundError); !ok {
+			logger.WithError(err).Error("Unable to query for domain")
+			server.Abort(context, server.InternalError)
+			return
+		}
+		if _, err := db.GetWorkspaceDomain(workspaceID, hostname); err == nil {
+			logger.WithField("hostname", hostname).Info("Domain already claimed by workspace")
+			server.Abort(context, server.RequestError.WithMessage("Domain is already registered"))
+			return
+		} else if _, ok := err.(database.RecordNotFoundError); !ok {
+			logger.WithError(err).Error("Unable to query for domain")
+			server.Abort(context, server.InternalError)
+			return
+		}
+
+		token, err := domain.NewVerificationToken()
+		if err != nil {
+			logger.WithError(err).Error("Unable to generate verification token")
+			server.Abort(context, server.InternalError)
+			return
+		}
+
+		d := database.Domain{
+			Hostname:          hostname,
+			WorkspaceID:       workspaceID,
+			VerificationToken: token,
+			CreatedAt:         time.Now(),
+		}
+		if err := db.CreateDomain(d); err != nil {
+			logger.WithError(err).Error("Unable to create domain")
+			server.Abort(context, server.InternalError)
+			return
+		}
+
+		context.JSON(http.StatusOK, newDomainResponse(d))
+	}
+}
+
+// getWorkspaceDomain queries the claim of workspace on domain given in path, aborting unless user holds required role.
+func getWorkspaceDomain(context *gin.Context, logger *logrus.Entry, required string) (*database.Domain, bool) {
+	workspaceID := context.Param("workspace_id")
+	if !Authorize(context, logger, workspaceID, required) {
+		return nil, false
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	d, err := db.GetWorkspaceDomain(workspaceID, strings.ToLower(context.Param("hostname")))
+	if err != nil {
+		if _, ok := err.(database.RecordNotFoundError); ok {
+			logger.Info("Domain not found")
+			server.Abort(context, server.NotFoundError)
+			return nil, false
+		}
+		logger.WithError(err).Error("Unable to query for domain")
+		server.Abort(context, server.InternalError)
+		return nil, false
+	}
+
+	return d, true
+}
+
+// VerifyDomainHandler looks up the TXT record of domain with resolver and, if it holds the token, gives the domain
+// to workspace, dropping the claims of other workspaces.
+func VerifyDomainHandler(resolver domain.Resolver) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		logger := logging.FromContext(context).WithField("workspace_id", context.Param("workspace_id")).WithField("hostname", context.Param("hostname"))
+		d, ok := getWorkspaceDomain(context, logger, database.WorkspaceRoleOwner)
+		if !ok {
+			return
+		}
+		if !d.VerifiedAt.IsZero() {
+			context.JSON(http.StatusOK, newDomainResponse(*d))
+			return
+		}
+
+		verified, err := verifyRecord(context.Request, resolver, *d)
+		if err != nil {
+			logger.WithError(err).Info("Unable to look up verification record")
+			server.Abort(context, server.RequestError.WithMessage("Unable to look up verification record, try again later"))
+			return
+		}
+		if !verified {
+			logger.Info("Verification record not found")
+			server.Abort(context, server.RequestError.WithMessage("Verification record not found"))
+			return
+		}
+
+		d.VerifiedAt = time.Now()
+		db := context.Value("db").(database.MySQLService)
+		if err := db.MarkDomainVerified(d.WorkspaceID, d.Hostname, d.VerifiedAt); err != nil {
+			if _, ok := err.(database.DomainTakenError); ok {
+				logger.Info("Domain verified by another workspace first")
+				server.Abort(context, server.RequestError.WithMessage("Domain is already registered"))
+				return
+			}
+			logger.WithError(err).Error("Unable to mark domain verified")
+			server.Abort(context, server.InternalError)
+			return
+		}
+
+		context.JSON(http.StatusOK, newDomainResponse(*d))
+	}
+}
+
+// verifyRecord checks the TXT record of d, giving up after verificationTimeout.
+func verifyRecord(request *http.Request, resolver domain.Resolver, d database.Domain) (bool, error) {
+	ctx, cancel := context.WithTimeout(request.Context(), verificationTimeout)
+	defer cancel()
+	return domain.Verify(ctx, resolver, d.Hostname, d.VerificationToken)
+}
+
+// RemoveDomainHandler deletes a domain no link is served on anymore.
+func RemoveDomainHandler(context *gin.Context) {
+	logger := logging.FromContext(context).WithField("workspace_id", context.Param("workspace_id")).WithField("hostname", context.Param("hostname"))
+	d, ok := getWorkspaceDomain(context, logger, database.WorkspaceRoleOwner)
+	if !ok {
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	count, err := db.CountURLsOnDomain(d.Hostname)
+	if err != nil {
+		logger.WithError(err).Error("Unable to count urls on domain")
+		server.Abort(context, server.InternalError)
+		return
+	}
+	if count > 0 {
+		logger.WithField("urls", count).Info("Refused to remove domain still in use")
+		server.Abort(context, server.RequestError.WithMessage("Domain still has links, delete them first"))
+		return
+	}
+
+	if err := db.DeleteDomain(d.WorkspaceID, d.Hostname); err != nil {
+		logger.WithError(err).Error("Unable to delete domain")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	context.Status(http.StatusOK)
+}
@@ -0,0 +1,392 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	url2 "net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	server "url-shortener/internal/route/error"
+	"url-shortener/internal/service/webhook"
+	"url-shortener/internal/util"
+)
+
+const (
+	maxWebhookURLLength    = 2048
+	maxClickThresholds     = 10
+	maxWebhooksInWorkspace = 10
+)
+
+// WebhookRequest registers or updates a webhook, omitted fields are left unchanged on update.
+type WebhookRequest struct {
+	URL             *string   `json:"url"`
+	Events          *[]string `json:"events"`
+	ClickThresholds *[]uint64 `json:"click_thresholds"`
+	Enabled         *bool     `json:"enabled"` // re-enabling resets the count of failed attempts
+}
+
+type WebhookResponse struct {
+	ID                  string     `json:"id"`
+	URL                 string     `json:"url"`
+	Events              []string   `json:"events"`
+	ClickThresholds     []uint64   `json:"click_thresholds"`
+	Enabled             bool       `json:"enabled"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	CreatedAt           time.Time  `json:"created_at"`
+	Secret              string     `json:"secret,omitempty"` // only returned on creation
+}
+
+type WebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             uint64          `json:"id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"` // pending deliveries only
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+type WebhookDeliveriesResponse struct {
+	Total      uint64                    `json:"total"`
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+}
+
+func newWebhookResponse(w database.Webhook) WebhookResponse {
+	res := WebhookResponse{
+		ID:                  w.ID,
+		URL:                 w.URL,
+		Events:              w.Events,
+		ClickThresholds:     w.ClickThresholds,
+		Enabled:             w.DisabledAt.IsZero(),
+		ConsecutiveFailures: w.ConsecutiveFailures,
+		CreatedAt:           w.CreatedAt,
+	}
+	if !res.Enabled {
+		disabledAt := w.DisabledAt
+		res.DisabledAt = &disabledAt
+	}
+	return res
+}
+
+func newWebhookDeliveryResponse(d database.WebhookDelivery) WebhookDeliveryResponse {
+	res := WebhookDeliveryResponse{
+		ID:             d.ID,
+		Event:          d.Event,
+		Payload:        json.RawMessage(d.Payload),
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		Error:          d.Error,
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.Status == database.WebhookDeliveryStatusPending {
+		nextAttemptAt := d.NextAttemptAt
+		res.NextAttemptAt = &nextAttemptAt
+	}
+	if !d.DeliveredAt.IsZero() {
+		deliveredAt := d.DeliveredAt
+		res.DeliveredAt = &deliveredAt
+	}
+	return res
+}
+
+// apply validates r and sets its fields on w.
+func (r WebhookRequest) apply(w *database.Webhook, now time.Time) []server.FieldError {
+	var errs []server.FieldError
+
+	if r.URL != nil {
+		w.URL = strings.TrimSpace(*r.URL)
+		u, err := url2.ParseRequestURI(w.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(w.URL) > maxWebhookURLLength {
+			errs = append(errs, server.FieldError{Field: "url", Message: "must be an http or https url"})
+		}
+	}
+
+	if r.Events != nil {
+		w.Events = []string{}
+		seen := map[string]bool{}
+		for _, event := range *r.Events {
+			if !database.IsWebhookEvent(event) {
+				errs = append(errs, server.FieldError{Field: "events", Message: "must be some of " + strings.Join(database.WebhookEvents, ", ")})
+				break
+			}
+			if !seen[event] {
+				seen[event] = true
+				w.Events = append(w.Events, event)
+			}
+		}
+	}
+	if len(w.Events) == 0 {
+		errs = append(errs, server.FieldError{Field: "events", Message: "must not be empty"})
+	}
+
+	if r.ClickThresholds != nil {
+		w.ClickThresholds = []uint64{}
+		seen := map[uint64]bool{}
+		for _, clicks := range *r.ClickThresholds {
+			if clicks == 0 {
+				errs = append(errs, server.FieldError{Field: "click_thresholds", Message: "must be positive"})
+				break
+			}
+			if !seen[clicks] {
+				seen[clicks] = true
+				w.ClickThresholds = append(w.ClickThresholds, clicks)
+			}
+		}
+		sort.Slice(w.ClickThresholds, func(i, j int) bool { return w.ClickThresholds[i] < w.ClickThresholds[j] })
+		if len(w.ClickThresholds) > maxClickThresholds {
+			errs = append(errs, server.FieldError{Field: "click_thresholds", Message: fmt.Sprintf("must have at most %v thresholds", maxClickThresholds)})
+		}
+	}
+	subscribedToClicks := false
+	for _, event := range w.Events {
+		subscribedToClicks = subscribedToClicks || event == database.WebhookEventURLClicks
+	}
+	if subscribedToClicks && len(w.ClickThresholds) == 0 {
+		errs = append(errs, server.FieldError{Field: "click_thresholds", Message: "must not be empty to subscribe to " + database.WebhookEventURLClicks})
+	}
+
+	if r.Enabled != nil {
+		if *r.Enabled {
+			w.DisabledAt = time.Time{}
+			w.ConsecutiveFailures = 0
+		} else if w.DisabledAt.IsZero() {
+			w.DisabledAt = now
+		}
+	}
+
+	return errs
+}
+
+func readWebhookRequest(context *gin.Context, logger *logrus.Entry) (*WebhookRequest, bool) {
+	r, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		logger.WithError(err).Error("Unable to read body properly")
+		server.Abort(context, server.InternalError)
+		return nil, false
+	}
+
+	var req WebhookRequest
+	if err := json.Unmarshal(r, &req); err != nil {
+		logger.WithError(err).Warn("Unexpected json string")
+		server.Abort(context, server.InvalidJSONStringError)
+		return nil, false
+	}
+	return &req, true
+}
+
+func GetWebhooksHandler(context *gin.Context) {
+	workspaceID := context.Param("workspace_id")
+	logger := logging.FromContext(context).WithField("workspace_id", workspaceID)
+	if !Authorize(context, logger, workspaceID, database.WorkspaceRoleOwner) {
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	webhooks, err := db.GetWebhooksInWorkspace(workspaceID)
+	if err != nil {
+		logger.WithError(err).Error("Unable to query for webhooks of workspace")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	res := WebhooksResponse{Webhooks: make([]WebhookResponse, len(webhooks))}
+	for i, w := range webhooks {
+		res.Webhooks[i] = newWebhookResponse(w)
+	}
+	context.JSON(http.StatusOK, res)
+}
+
+// CreateWebhookHandler registers a webhook receiving events about links of workspace. The secret signing its
+// deliveries is only returned here.
+func CreateWebhookHandler(context *gin.Context) {
+	workspaceID := context.Param("workspace_id")
+	logger := logging.FromContext(context).WithField("workspace_id", workspaceID)
+	if !Authorize(context, logger, workspaceID, database.WorkspaceRoleOwner) {
+		return
+	}
+
+	req, ok := readWebhookRequest(context, logger)
+	if !ok {
+		return
+	}
+	if req.URL == nil {
+		req.URL = new(string)
+	}
+
+	now := time.Now()
+	w := database.Webhook{WorkspaceID: workspaceID, CreatedAt: now}
+	if errs := req.apply(&w, now); len(errs) > 0 {
+		logger.Info("Invalid webhook")
+		server.Abort(context, server.ValidationError.WithDetails(errs...))
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	webhooks, err := db.GetWebhooksInWorkspace(workspaceID)
+	if err != nil {
+		logger.WithError(err).Error("Unable to query for webhooks of workspace")
+		server.Abort(context, server.InternalError)
+		return
+	}
+	if len(webhooks) >= maxWebhooksInWorkspace {
+		logger.Info("Refused to register more webhooks")
+		server.Abort(context, server.RequestError.WithMessage(fmt.Sprintf("Workspaces can have at most %v webhooks", maxWebhooksInWorkspace)))
+		return
+	}
+
+	if w.ID, err = util.NewUUID(); err != nil {
+		logger.WithError(err).Error("Unable to generate id of webhook")
+		server.Abort(context, server.InternalError)
+		return
+	}
+	if w.Secret, err = webhook.NewSecret(); err != nil {
+		logger.WithError(err).Error("Unable to generate secret of webhook")
+		server.Abort(context, server.InternalError)
+		return
+	}
+	w.CreatedBy = context.Value("user").(*database.User).UserID
+
+	if err := db.CreateWebhook(w); err != nil {
+		logger.WithError(err).Error("Unable to create webhook")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	res := newWebhookResponse(w)
+	res.Secret = w.Secret
+	context.JSON(http.StatusOK, res)
+}
+
+// getWorkspaceWebhook queries webhook given in path, aborting unless it belongs to workspace and user owns it.
+func getWorkspaceWebhook(context *gin.Context, logger *logrus.Entry) (*database.Webhook, bool) {
+	workspaceID := context.Param("workspace_id")
+	if !Authorize(context, logger, workspaceID, database.WorkspaceRoleOwner) {
+		return nil, false
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	w, err := db.GetWebhook(context.Param("webhook_id"))
+	if err != nil {
+		if _, ok := err.(database.RecordNotFoundError); ok {
+			logger.Info("Webhook not found")
+			server.Abort(context, server.NotFoundError)
+			return nil, false
+		}
+		logger.WithError(err).Error("Unable to query for webhook")
+		server.Abort(context, server.InternalError)
+		return nil, false
+	}
+	if w.WorkspaceID != workspaceID {
+		logger.Info("Webhook belongs to another workspace")
+		server.Abort(context, server.NotFoundError)
+		return nil, false
+	}
+
+	return w, true
+}
+
+func UpdateWebhookHandler(context *gin.Context) {
+	logger := logging.FromContext(context).WithField("workspace_id", context.Param("workspace_id")).WithField("webhook_id", context.Param("webhook_id"))
+	w, ok := getWorkspaceWebhook(context, logger)
+	if !ok {
+		return
+	}
+
+	req, ok := readWebhookRequest(context, logger)
+	if !ok {
+		return
+	}
+	if errs := req.apply(w, time.Now()); len(errs) > 0 {
+		logger.Info("Invalid webhook")
+		server.Abort(context, server.ValidationError.WithDetails(errs...))
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	if err := db.UpdateWebhook(*w); err != nil {
+		logger.WithError(err).Error("Unable to update webhook")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	context.JSON(http.StatusOK, newWebhookResponse(*w))
+}
+
+// RemoveWebhookHandler deletes a webhook along with its pending deliveries and log.
+func RemoveWebhookHandler(context *gin.Context) {
+	logger := logging.FromContext(context).WithField("workspace_id", context.Param("workspace_id")).WithField("webhook_id", context.Param("webhook_id"))
+	w, ok := getWorkspaceWebhook(context, logger)
+	if !ok {
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	if err := db.DeleteWebhook(w.ID); err != nil {
+		logger.WithError(err).Error("Unable to delete webhook")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	context.Status(http.StatusOK)
+}
+
+// GetWebhookDeliveriesHandler lists deliveries of a webhook, the newest first, with the outcome of their last attempt.
+func GetWebhookDeliveriesHandler(context *gin.Context) {
+	logger := logging.FromContext(context).WithField("workspace_id", context.Param("workspace_id")).WithField("webhook_id", context.Param("webhook_id"))
+	w, ok := getWorkspaceWebhook(context, logger)
+	if !ok {
+		return
+	}
+
+	var offset, limit uint64 = 0, 100
+	for name, value := range map[string]*uint64{"offset": &offset, "limit": &limit} {
+		param := context.Query(name)
+		if param == "" {
+			continue
+		}
+		parsed, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			logger.WithError(err).WithField(name, param).Info("Unable to decode query parameter")
+			server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+				Field:   name,
+				Message: "must be a non-negative integer",
+			}))
+			return
+		}
+		*value = parsed
+	}
+	if limit < 1 || limit > 100 {
+		limit = 100
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	total, deliveries, err := db.GetWebhookDeliveries(w.ID, offset, limit)
+	if err != nil {
+		logger.WithError(err).Error("Unable to query for webhook deliveries")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	res := WebhookDeliveriesResponse{Total: total, Deliveries: make([]WebhookDeliveryResponse, len(deliveries))}
+	for i, d := range deliveries {
+		res.Deliveries[i] = newWebhookDeliveryResponse(d)
+	}
+	context.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,458 @@
+package workspace
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"url-shortener/internal/database"
+	"url-shortener/internal/logging"
+	server "url-shortener/internal/route/error"
+	"url-shortener/internal/service/mail"
+	"url-shortener/internal/util"
+)
+
+const (
+	maxNameLength         = 100
+	invitationExpiration  = 7 * 24 * time.Hour
+	invitationTokenLength = 32
+)
+
+type WorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+type WorkspaceResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Personal  bool      `json:"personal"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WorkspacesResponse struct {
+	Workspaces []WorkspaceResponse `json:"workspaces"`
+}
+
+type MemberRequest struct {
+	Role string `json:"role"`
+}
+
+type MemberResponse struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+}
+
+type MembersResponse struct {
+	Members []MemberResponse `json:"members"`
+}
+
+type InvitationRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// InvitationResponse carries the token only if it could not be delivered by email.
+type InvitationResponse struct {
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Token     string    `json:"token,omitempty"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+func newWorkspaceResponse(membership database.Membership) WorkspaceResponse {
+	return WorkspaceResponse{
+		ID:        membership.Workspace.ID,
+		Name:      membership.Workspace.Name,
+		Personal:  membership.Workspace.Personal,
+		Role:      membership.Role,
+		CreatedAt: membership.Workspace.CreatedAt,
+	}
+}
+
+func invalidRole() server.APIError {
+	return server.ValidationError.WithDetails(server.FieldError{
+		Field:   "role",
+		Message: fmt.Sprintf("must be one of %v, %v, %v", database.WorkspaceRoleOwner, database.WorkspaceRoleEditor, database.WorkspaceRoleViewer),
+	})
+}
+
+// hashToken returns the form an invitation token is stored in, so a leaked table does not leak usable tokens.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func GetWorkspacesHandler(context *gin.Context) {
+	logger := logging.FromContext(context)
+
+	db := context.Value("db").(database.MySQLService)
+	user := context.Value("user").(*database.User)
+	memberships, err := db.GetMembershipsWithUser(*user)
+	if err != nil {
+		logger.WithError(err).Error("Unable to query for user's workspaces")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	workspaces := make([]WorkspaceResponse, len(memberships))
+	for i, membership := range memberships {
+		workspaces[i] = newWorkspaceResponse(membership)
+	}
+
+	context.JSON(http.StatusOK, WorkspacesResponse{Workspaces: workspaces})
+}
+
+func CreateWorkspaceHandler(context *gin.Context) {
+	logger := logging.FromContext(context)
+
+	r, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		logger.WithError(err).Error("Unable to read body properly")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	var req WorkspaceRequest
+	if err := json.Unmarshal(r, &req); err != nil {
+		logger.WithError(err).Warn("Unexpected json string")
+		server.Abort(context, server.InvalidJSONStringError)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		logger.Info("Invalid workspace name")
+		server.Abort(context, server.ValidationError.WithDetails(server.FieldError{
+			Field:   "name",
+			Message: fmt.Sprintf("must be between 1 and %v characters", maxNameLength),
+		}))
+		return
+	}
+
+	id, err := util.NewUUID()
+	if err != nil {
+		logger.WithError(err).Error("Unable to generate workspace id")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	user := context.Value("user").(*database.User)
+	workspace := database.Workspace{ID: id, Name: name, CreatedAt: time.Now()}
+	if err := db.CreateWorkspace(workspace, *user); err != nil {
+		logger.WithError(err).Error("Unable to create workspace")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	context.JSON(http.StatusOK, newWorkspaceResponse(database.Membership{
+		Workspace: workspace,
+		Role:      database.WorkspaceRoleOwner,
+	}))
+}
+
+func GetMembersHandler(context *gin.Context) {
+	workspaceID := context.Param("workspace_id")
+	logger := logging.FromContext(context).WithField("workspace_id", workspaceID)
+	if !Authorize(context, logger, workspaceID, database.WorkspaceRoleViewer) {
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	members, err := db.GetWorkspaceMembers(workspaceID)
+	if err != nil {
+		logger.WithError(err).Error("Unable to query for members of workspace")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	resMembers := make([]MemberResponse, len(members))
+	for i, member := range members {
+		resMembers[i] = MemberResponse{
+			UserID: member.User.UserID,
+			Email:  member.User.Email,
+			Role:   member.Role,
+		}
+	}
+
+	context.JSON(http.StatusOK, MembersResponse{Members: resMembers})
+}
+
+// countOwners returns the number of owners of workspace, or aborts the request if they could not be queried.
+func countOwners(context *gin.Context, workspaceID string) (int, bool) {
+	db := context.Value("db").(database.MySQLService)
+	members, err := db.GetWorkspaceMembers(workspaceID)
+	if err != nil {
+		logging.FromContext(context).WithError(err).Error("Unable to query for members of workspace")
+		server.Abort(context, server.InternalError)
+		return 0, false
+	}
+
+	owners := 0
+	for _, member := range members {
+		if member.Role == database.WorkspaceRoleOwner {
+			owners++
+		}
+	}
+	return owners, true
+}
+
+func UpdateMemberHandler(context *gin.Context) {
+	workspaceID := context.Param("workspace_id")
+	memberID := context.Param("user_id")
+	logger := logging.FromContext(context).WithField("workspace_id", workspaceID).WithField("member_id", memberID)
+	if !Authorize(context, logger, workspaceID, database.WorkspaceRoleOwner) {
+		return
+	}
+
+	r, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		logger.WithError(err).Error("Unable to read body properly")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	var req MemberRequest
+	if err := json.Unmarshal(r, &req); err != nil {
+		logger.WithError(err).Warn("Unexpected json string")
+		server.Abort(context, server.InvalidJSONStringError)
+		return
+	}
+	if !database.IsWorkspaceRole(req.Role) {
+		logger.WithField("role", req.Role).Info("Unknown role")
+		server.Abort(context, invalidRole())
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	role, err := db.GetWorkspaceRole(workspaceID, memberID)
+	if err != nil {
+		if _, ok := err.(database.RecordNotFoundError); ok {
+			logger.Info("Member not found in workspace")
+			server.Abort(context, server.NotFoundError)
+			return
+		}
+		logger.WithError(err).Error("Unable to query for role of member")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	if role == database.WorkspaceRoleOwner && req.Role != database.WorkspaceRoleOwner {
+		owners, ok := countOwners(context, workspaceID)
+		if !ok {
+			return
+		}
+		if owners < 2 {
+			logger.Info("Refused to demote the last owner")
+			server.Abort(context, server.RequestError.WithMessage("Workspace must keep at least one owner"))
+			return
+		}
+	}
+
+	if err := db.SetWorkspaceMemberRole(workspaceID, memberID, req.Role); err != nil {
+		logger.WithError(err).Error("Unable to update role of member")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	context.Status(http.StatusOK)
+}
+
+// RemoveMemberHandler removes a member from workspace. Owners remove anyone, other members only themselves.
+func RemoveMemberHandler(context *gin.Context) {
+	workspaceID := context.Param("workspace_id")
+	memberID := context.Param("user_id")
+	logger := logging.FromContext(context).WithField("workspace_id", workspaceID).WithField("member_id", memberID)
+
+	user := context.Value("user").(*database.User)
+	required := database.WorkspaceRoleOwner
+	if memberID == user.UserID {
+		required = database.WorkspaceRoleViewer
+	}
+	if !Authorize(context, logger, workspaceID, required) {
+		return
+	}
+
+	if workspaceID == memberID {
+		logger.Info("Refused to remove user from personal workspace")
+		server.Abort(context, server.RequestError.WithMessage("Personal workspace can not be left"))
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	role, err := db.GetWorkspaceRole(workspaceID, memberID)
+	if err != nil {
+		if _, ok := err.(database.RecordNotFoundError); ok {
+			logger.Info("Member not found in workspace")
+			server.Abort(context, server.NotFoundError)
+			return
+		}
+		logger.WithError(err).Error("Unable to query for role of member")
+		server.Abort(context, server.InternalError)
+		return
+	}
+	if role == database.WorkspaceRoleOwner {
+		owners, ok := countOwners(context, workspaceID)
+		if !ok {
+			return
+		}
+		if owners < 2 {
+			logger.Info("Refused to remove the last owner")
+			server.Abort(context, server.RequestError.WithMessage("Workspace must keep at least one owner"))
+			return
+		}
+	}
+
+	if err := db.DeleteWorkspaceMember(workspaceID, memberID); err != nil {
+		logger.WithError(err).Error("Unable to remove member")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	context.Status(http.StatusOK)
+}
+
+// InviteMemberHandler emails an invitation token to join workspace. The token is handed to the inviting owner instead
+// if tokenReturned is set or the email can not be queued.
+func InviteMemberHandler(emailRequest chan<- mail.SendEmailOptions, tokenReturned bool) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		workspaceID := context.Param("workspace_id")
+		logger := logging.FromContext(context).WithField("workspace_id", workspaceID)
+		if !Authorize(context, logger, workspaceID, database.WorkspaceRoleOwner) {
+			return
+		}
+
+		user := context.Value("user").(*database.User)
+		if workspaceID == user.UserID {
+			logger.Info("Refused to invite into personal workspace")
+			server.Abort(context, server.RequestError.WithMessage("Members can not be invited to a personal workspace"))
+			return
+		}
+
+		r, err := ioutil.ReadAll(context.Request.Body)
+		if err != nil {
+			logger.WithError(err).Error("Unable to read body properly")
+			server.Abort(context, server.InternalError)
+			return
+		}
+
+		var req InvitationRequest
+		if err := json.Unmarshal(r, &req); err != nil {
+			logger.WithError(err).Warn("Unexpected json string")
+			server.Abort(context, server.InvalidJSONStringError)
+			return
+		}
+		if !util.CheckEmailIfValid(req.Email) {
+			logger.Info("Email is not valid")
+			server.Abort(context, server.EmailValidationError.WithDetails(server.FieldError{
+				Field:   "email",
+				Message: "must be a valid email address",
+			}))
+			return
+		}
+		if !database.IsWorkspaceRole(req.Role) {
+			logger.WithField("role", req.Role).Info("Unknown role")
+			server.Abort(context, invalidRole())
+			return
+		}
+
+		raw := make([]byte, invitationTokenLength)
+		if _, err := rand.Read(raw); err != nil {
+			logger.WithError(err).Error("Unable to generate invitation token")
+			server.Abort(context, server.InternalError)
+			return
+		}
+		token := base64.RawURLEncoding.EncodeToString(raw)
+
+		invitation := database.WorkspaceInvitation{
+			TokenHash:   hashToken(token),
+			WorkspaceID: workspaceID,
+			Email:       strings.ToLower(req.Email),
+			Role:        req.Role,
+			InvitedBy:   user.UserID,
+			ExpiresAt:   time.Now().Add(invitationExpiration),
+		}
+		db := context.Value("db").(database.MySQLService)
+		if err := db.CreateWorkspaceInvitation(invitation); err != nil {
+			logger.WithError(err).Error("Unable to create invitation")
+			server.Abort(context, server.InternalError)
+			return
+		}
+
+		res := InvitationResponse{
+			Email:     invitation.Email,
+			Role:      invitation.Role,
+			ExpiresAt: invitation.ExpiresAt,
+		}
+
+		if tokenReturned || emailRequest == nil {
+			logger.Warn("Invitation created without email delivery")
+			res.Token = token
+			context.JSON(http.StatusOK, res)
+			return
+		}
+
+		select {
+		case emailRequest <- mail.SendEmailOptions{
+			To:      invitation.Email,
+			Subject: "Workspace invitation",
+			Message: fmt.Sprintf("%v invited you to join a workspace as %v. "+
+				"Sign in with this email address and accept the invitation with token %v within 7 days.",
+				user.Email, invitation.Role, token),
+		}:
+		default:
+			logger.Warn("Email request queue is full, token is handed to the owner instead")
+			res.Token = token
+		}
+
+		context.JSON(http.StatusOK, res)
+	}
+}
+
+func AcceptInvitationHandler(context *gin.Context) {
+	logger := logging.FromContext(context)
+
+	r, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		logger.WithError(err).Error("Unable to read body properly")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	var req AcceptInvitationRequest
+	if err := json.Unmarshal(r, &req); err != nil {
+		logger.WithError(err).Warn("Unexpected json string")
+		server.Abort(context, server.InvalidJSONStringError)
+		return
+	}
+
+	db := context.Value("db").(database.MySQLService)
+	user := context.Value("user").(*database.User)
+	invitation, err := db.AcceptWorkspaceInvitation(hashToken(strings.TrimSpace(req.Token)), *user)
+	if err != nil {
+		if _, ok := err.(database.RecordNotFoundError); ok {
+			logger.Info("No valid invitation for user with given token")
+			server.Abort(context, server.NotFoundError.WithMessage("Invitation not found or expired"))
+			return
+		}
+		logger.WithError(err).Error("Unable to accept invitation")
+		server.Abort(context, server.InternalError)
+		return
+	}
+
+	logger.WithField("workspace_id", invitation.WorkspaceID).Info("Invitation accepted")
+	context.Status(http.StatusOK)
+}
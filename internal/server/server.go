@@ -4,15 +4,30 @@ import (
 	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+	"net"
+	"net/http"
 	"path"
 	"time"
 	"url-shortener/internal/cache"
+	"url-shortener/internal/clock"
 	"url-shortener/internal/database"
 	"url-shortener/internal/middleware"
+	"url-shortener/internal/openapi"
+	"url-shortener/internal/route/admin"
+	"url-shortener/internal/route/audit"
+	"url-shortener/internal/route/page"
+	"url-shortener/internal/route/report"
 	"url-shortener/internal/route/shortener"
+	"url-shortener/internal/route/user/preferences"
+	"url-shortener/internal/route/user/privacy"
 	userUrls "url-shortener/internal/route/user/shortener"
 	"url-shortener/internal/route/user/sign"
+	"url-shortener/internal/route/workspace"
+	"url-shortener/internal/service/domain"
 	"url-shortener/internal/service/mail"
+	"url-shortener/internal/service/targeting"
 )
 
 type ServerOptions struct {
@@ -25,62 +40,209 @@ type ServerOptions struct {
 	HtmlTemplate             string
 	GoogleOauthConf          sign.GoogleOauthConfig
 	EmailVerificationIgnored bool
+	InvitationTokenReturned  bool // hands invitation tokens to inviting owners instead of emailing them
 	EmailRequest             chan<- mail.SendEmailOptions
+	MetadataRequest          chan<- string
+	DataExportRequest        chan<- string
+	Logger                   *logrus.Logger
+	LegacyAPISunset          time.Time
+	DomainResolver           domain.Resolver      // looks up verification records of custom domains, net.DefaultResolver if nil
+	GeoLocator               targeting.GeoLocator // resolves countries for redirect rules, rules on countries never match if nil
+	AppleAppSiteAssociation  []byte               // served for iOS universal links, not found if empty
+	AndroidAssetLinks        []byte               // served for Android app links, not found if empty
+	Clock                    clock.Clock          // tells when urls are active, clock.System if nil
+	TrashRetention           time.Duration        // how long deleted urls can be restored, DefaultTrashRetention if zero
+	TrustedProxies           []*net.IPNet         // peers whose X-Forwarded-For is believed, never believed if empty
+	ReportRateLimit          int64                // reports accepted per address an hour, DefaultReportRateLimit if zero
+	ReportURLRateLimit       int64                // reports accepted per url an hour, DefaultReportURLRateLimit if zero
+	ReportSuspendThreshold   uint64               // distinct reporters suspending a url, DefaultReportSuspendThreshold if zero
 }
 
+const (
+	DefaultTrashRetention         = 30 * 24 * time.Hour
+	DefaultReportRateLimit        = 10
+	DefaultReportURLRateLimit     = 20
+	DefaultReportSuspendThreshold = 5
+)
+
 // Start server, return error if failed to start.
 func SetupServer(options ServerOptions) *gin.Engine {
-	r := gin.Default()
+	logger := options.Logger
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+	if options.DomainResolver == nil {
+		options.DomainResolver = net.DefaultResolver
+	}
+	if options.Clock == nil {
+		options.Clock = clock.System
+	}
+	if options.TrashRetention == 0 {
+		options.TrashRetention = DefaultTrashRetention
+	}
+	if options.ReportRateLimit == 0 {
+		options.ReportRateLimit = DefaultReportRateLimit
+	}
+	if options.ReportURLRateLimit == 0 {
+		options.ReportURLRateLimit = DefaultReportURLRateLimit
+	}
+	if options.ReportSuspendThreshold == 0 {
+		options.ReportSuspendThreshold = DefaultReportSuspendThreshold
+	}
+
+	r := gin.New()
+	r.Use(middleware.ClientAddress(options.TrustedProxies))
+	r.Use(middleware.RequestLogger(logger))
+	r.Use(middleware.Recovery())
 
 	r.LoadHTMLGlob(path.Join(options.HtmlTemplate, "*.tmpl"))
 
+	spec := openapi.Spec()
+
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{options.BaseUrl},
-		AllowMethods:     []string{"GET", "POST", "DELETE"},
-		AllowHeaders:     []string{"Origin"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowHeaders:     []string{"Origin", middleware.RequestIDHeader},
 		AllowCredentials: true,
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", middleware.RequestIDHeader},
 		MaxAge:           12 * time.Hour,
 	}))
+	r.Use(middleware.RequestMetrics())
 	r.Use(middleware.GetDatabaseConnector(options.Database))
 	r.Use(middleware.GetCacheConnector(options.Cache))
+	r.Use(middleware.RequestValidator(spec))
+
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	r.GET(shortener.AppleAppSiteAssociationRoute, shortener.WellKnownJSONHandler(options.AppleAppSiteAssociation))
+	r.GET(shortener.AndroidAssetLinksRoute, shortener.WellKnownJSONHandler(options.AndroidAssetLinks))
+	r.GET("/p/:slug", page.RenderPageHandler(options.BaseUrl))
+
+	// note: the redirect uri is registered at Google console, keep it on the legacy path until updated there
+	options.GoogleOauthConf.RedirectUrl = fmt.Sprintf("%v/api/user/sign/google/callback", options.BaseUrl)
+	sign.VarConfig(options.Domain, options.GoogleOauthConf)
+
+	// note: short urls are served at the root for any path not matched by the routes below
+	r.NoRoute(shortener.RootRedirectHandler(shortener.GetShortenUrlHandler(options.Domain, options.GeoLocator, options.Clock)))
 
 	apiRouter := r.Group("/api")
 	{
-		userRouter := apiRouter.Group("/user")
-		{
-			userRouter.GET("/authCheck", middleware.UserAuthenticated(options.JwtKey), sign.AuthCheckHandler)
+		apiRouter.GET("/openapi.json", func(context *gin.Context) {
+			context.JSON(http.StatusOK, spec)
+		})
 
-			signRouter := userRouter.Group("/sign")
-			{
-				options.GoogleOauthConf.RedirectUrl = fmt.Sprintf("%v/api/user/sign/google/callback", options.BaseUrl)
-				sign.VarConfig(options.Domain, options.GoogleOauthConf)
+		setupAPIRoutes(apiRouter.Group("/v1"), options, 1)
+		setupAPIRoutes(apiRouter.Group("/v2"), options, 2)
 
-				googleOauth := signRouter.Group("/google")
-				{
-					googleOauth.GET("/", sign.GoogleSignHandler(options.UseHttps))
-					googleOauth.GET("/callback", sign.GoogleSignCallbackHandler(options.JwtKey, options.BaseUrl))
-				}
+		// unversioned paths are kept as aliases of v1 until sunset
+		legacyRouter := apiRouter.Group("", middleware.Deprecated(options.LegacyAPISunset, "/api/v1"))
+		setupAPIRoutes(legacyRouter, options, 1)
+	}
 
-				signRouter.POST("/", sign.UserSignInHandler(options.JwtKey))
-			}
+	return r
+}
 
-			userRouter.POST("/signup", sign.UserSignUpHandler(options.EmailRequest, options.EmailVerificationIgnored))
-			userRouter.POST("/signup/complete", sign.UserSignUpCompletionHandler(options.EmailVerificationIgnored))
+// setupAPIRoutes registers routes of given api version on router. v1 is frozen to the routes served
+// when v2 was introduced, later ones are only registered from v2 on.
+func setupAPIRoutes(apiRouter *gin.RouterGroup, options ServerOptions, version int) {
+	userRouter := apiRouter.Group("/user")
+	userURLRouter := userRouter.Group("/url")
+	{
+		userRouter.GET("/authCheck", middleware.UserAuthenticated(options.JwtKey), sign.AuthCheckHandler)
 
-			shortenerRouter := userRouter.Group("/url")
+		signRouter := userRouter.Group("/sign")
+		{
+			googleOauth := signRouter.Group("/google")
 			{
-				shortenerRouter.GET("/list", middleware.UserAuthenticated(options.JwtKey), userUrls.GetShortenUrlsHandler)
-				shortenerRouter.DELETE("/r/:shorten_url", middleware.UserAuthenticated(options.JwtKey), userUrls.RemoveShortenUrlHandler)
+				googleOauth.GET("/", sign.GoogleSignHandler(options.UseHttps))
+				googleOauth.GET("/callback", sign.GoogleSignCallbackHandler(options.JwtKey, options.BaseUrl))
 			}
+
+			signRouter.POST("/", sign.UserSignInHandler(options.JwtKey))
 		}
 
-		shortenerRouter := apiRouter.Group("/shortener")
-		{
-			shortenerRouter.POST("/", middleware.UserAuthenticated(options.JwtKey), shortener.CreateShortenUrlHandler(options.Domain))
-			shortenerRouter.GET("/r/:shorten_url", shortener.GetShortenUrlHandler)
+		userRouter.POST("/signup", sign.UserSignUpHandler(options.EmailRequest, options.EmailVerificationIgnored))
+		userRouter.POST("/signup/complete", sign.UserSignUpCompletionHandler(options.EmailVerificationIgnored))
+
+		if version >= 2 {
+			userURLRouter.GET("/list", middleware.UserAuthenticated(options.JwtKey), userUrls.GetShortenUrlsPageHandler)
+		} else {
+			userURLRouter.GET("/list", middleware.UserAuthenticated(options.JwtKey), userUrls.GetShortenUrlsHandler)
 		}
+		userURLRouter.DELETE("/r/:shorten_url", middleware.UserAuthenticated(options.JwtKey), userUrls.RemoveShortenUrlHandler)
 	}
 
-	return r
+	shortenerRouter := apiRouter.Group("/shortener")
+	{
+		shortenerRouter.POST("/", middleware.UserAuthenticated(options.JwtKey), shortener.CreateShortenUrlHandler(options.Domain, options.MetadataRequest))
+		shortenerRouter.GET("/r/:shorten_url", shortener.GetShortenUrlHandler(options.Domain, options.GeoLocator, options.Clock))
+		shortenerRouter.HEAD("/r/:shorten_url", shortener.GetShortenUrlHandler(options.Domain, options.GeoLocator, options.Clock))
+	}
+
+	if version < 2 {
+		return
+	}
+
+	userRouter.GET("/preferences", middleware.UserAuthenticated(options.JwtKey), preferences.GetPreferencesHandler)
+	userRouter.PATCH("/preferences", middleware.UserAuthenticated(options.JwtKey), preferences.UpdatePreferencesHandler)
+	userRouter.POST("/invitations/accept", middleware.UserAuthenticated(options.JwtKey), workspace.AcceptInvitationHandler)
+	userRouter.GET("/audit", middleware.UserAuthenticated(options.JwtKey), audit.GetAuditTrailHandler)
+	userRouter.POST("/data-export", middleware.UserAuthenticated(options.JwtKey), privacy.CreateDataExportHandler(options.DataExportRequest))
+	userRouter.GET("/data-export", middleware.UserAuthenticated(options.JwtKey), privacy.GetDataExportsHandler)
+	userRouter.GET("/data-export/:export_id", middleware.UserAuthenticated(options.JwtKey), privacy.DownloadDataExportHandler)
+
+	userURLRouter.GET("/tags", middleware.UserAuthenticated(options.JwtKey), userUrls.GetTagsHandler)
+	userURLRouter.GET("/trash", middleware.UserAuthenticated(options.JwtKey), userUrls.GetTrashHandler(options.TrashRetention))
+	userURLRouter.GET("/export", middleware.UserAuthenticated(options.JwtKey), userUrls.ExportURLsHandler)
+	userURLRouter.POST("/import", middleware.UserAuthenticated(options.JwtKey), userUrls.ImportURLsHandler(options.Domain))
+	userURLRouter.PATCH("/r/:shorten_url", middleware.UserAuthenticated(options.JwtKey), userUrls.UpdateShortenUrlHandler)
+	userURLRouter.POST("/r/:shorten_url/restore", middleware.UserAuthenticated(options.JwtKey), userUrls.RestoreShortenUrlHandler(options.TrashRetention))
+	userURLRouter.GET("/r/:shorten_url/checks", middleware.UserAuthenticated(options.JwtKey), userUrls.GetURLChecksHandler)
+	userURLRouter.GET("/r/:shorten_url/rules", middleware.UserAuthenticated(options.JwtKey), userUrls.GetRedirectRulesHandler)
+	userURLRouter.PUT("/r/:shorten_url/rules", middleware.UserAuthenticated(options.JwtKey), userUrls.UpdateRedirectRulesHandler)
+	userURLRouter.GET("/r/:shorten_url/variants", middleware.UserAuthenticated(options.JwtKey), userUrls.GetURLVariantsHandler)
+	userURLRouter.PUT("/r/:shorten_url/variants", middleware.UserAuthenticated(options.JwtKey), userUrls.UpdateURLVariantsHandler)
+	userURLRouter.GET("/r/:shorten_url/schedule", middleware.UserAuthenticated(options.JwtKey), userUrls.GetURLScheduleHandler)
+	userURLRouter.PUT("/r/:shorten_url/schedule", middleware.UserAuthenticated(options.JwtKey), userUrls.UpdateURLScheduleHandler)
+
+	adminRouter := apiRouter.Group("/admin", middleware.UserAuthenticated(options.JwtKey), middleware.AdminAuthorized())
+	{
+		adminRouter.GET("/audit", audit.GetAllAuditEntriesHandler)
+		adminRouter.GET("/users", admin.GetUsersHandler)
+		adminRouter.PATCH("/users/:user_id", admin.UpdateUserHandler)
+		adminRouter.POST("/users/:user_id/transfer", admin.TransferURLsHandler)
+		adminRouter.DELETE("/urls/:shorten_url", admin.PurgeURLHandler)
+		adminRouter.GET("/reports", admin.GetReportsHandler)
+		adminRouter.POST("/reports/:shorten_url/resolve", admin.ResolveReportsHandler)
+		adminRouter.GET("/stats", admin.GetStatsHandler)
+	}
+
+	apiRouter.POST("/report/:shorten_url", report.ReportURLHandler(options.ReportRateLimit, options.ReportURLRateLimit, options.ReportSuspendThreshold))
+
+	workspaceRouter := apiRouter.Group("/workspaces")
+	{
+		workspaceRouter.GET("/", middleware.UserAuthenticated(options.JwtKey), workspace.GetWorkspacesHandler)
+		workspaceRouter.POST("/", middleware.UserAuthenticated(options.JwtKey), workspace.CreateWorkspaceHandler)
+		workspaceRouter.GET("/:workspace_id/members", middleware.UserAuthenticated(options.JwtKey), workspace.GetMembersHandler)
+		workspaceRouter.PATCH("/:workspace_id/members/:user_id", middleware.UserAuthenticated(options.JwtKey), workspace.UpdateMemberHandler)
+		workspaceRouter.DELETE("/:workspace_id/members/:user_id", middleware.UserAuthenticated(options.JwtKey), workspace.RemoveMemberHandler)
+		workspaceRouter.POST("/:workspace_id/invitations", middleware.UserAuthenticated(options.JwtKey), workspace.InviteMemberHandler(options.EmailRequest, options.InvitationTokenReturned))
+		workspaceRouter.GET("/:workspace_id/domains", middleware.UserAuthenticated(options.JwtKey), workspace.GetDomainsHandler)
+		workspaceRouter.POST("/:workspace_id/domains", middleware.UserAuthenticated(options.JwtKey), workspace.CreateDomainHandler(options.Domain))
+		workspaceRouter.DELETE("/:workspace_id/domains/:hostname", middleware.UserAuthenticated(options.JwtKey), workspace.RemoveDomainHandler)
+		workspaceRouter.POST("/:workspace_id/domains/:hostname/verify", middleware.UserAuthenticated(options.JwtKey), workspace.VerifyDomainHandler(options.DomainResolver))
+		workspaceRouter.GET("/:workspace_id/webhooks", middleware.UserAuthenticated(options.JwtKey), workspace.GetWebhooksHandler)
+		workspaceRouter.POST("/:workspace_id/webhooks", middleware.UserAuthenticated(options.JwtKey), workspace.CreateWebhookHandler)
+		workspaceRouter.PATCH("/:workspace_id/webhooks/:webhook_id", middleware.UserAuthenticated(options.JwtKey), workspace.UpdateWebhookHandler)
+		workspaceRouter.DELETE("/:workspace_id/webhooks/:webhook_id", middleware.UserAuthenticated(options.JwtKey), workspace.RemoveWebhookHandler)
+		workspaceRouter.GET("/:workspace_id/webhooks/:webhook_id/deliveries", middleware.UserAuthenticated(options.JwtKey), workspace.GetWebhookDeliveriesHandler)
+	}
+
+	pageRouter := apiRouter.Group("/pages")
+	{
+		pageRouter.GET("/", middleware.UserAuthenticated(options.JwtKey), page.GetPagesHandler)
+		pageRouter.POST("/", middleware.UserAuthenticated(options.JwtKey), page.CreatePageHandler)
+		pageRouter.GET("/:slug", middleware.UserAuthenticated(options.JwtKey), page.GetPageHandler)
+		pageRouter.PUT("/:slug", middleware.UserAuthenticated(options.JwtKey), page.UpdatePageHandler)
+		pageRouter.DELETE("/:slug", middleware.UserAuthenticated(options.JwtKey), page.RemovePageHandler)
+	}
 }
@@ -9,6 +9,7 @@ import (
 	. "github.com/onsi/gomega"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -18,6 +19,7 @@ import (
 	"url-shortener/internal/database"
 	"url-shortener/internal/route/user/shortener"
 	"url-shortener/internal/route/user/sign"
+	"url-shortener/internal/route/workspace"
 	"url-shortener/internal/server"
 )
 
@@ -92,7 +94,10 @@ var _ = Describe("Server APIs", func() {
 			HtmlTemplate:             "../template",
 			GoogleOauthConf:          gConf,
 			EmailVerificationIgnored: true,
+			InvitationTokenReturned:  true,
 			EmailRequest:             nil,
+			AppleAppSiteAssociation:  []byte(`{"applinks":{"apps":[],"details":[]}}`),
+			GeoLocator:               countryByIP{"198.51.100.1": "DE"},
 		}
 		router = server.SetupServer(serverOptions)
 	})
@@ -274,6 +279,7 @@ var _ = Describe("Server APIs", func() {
 			Expect(recorder.Code).To(Equal(http.StatusTemporaryRedirect))
 			redirectUrl := recorder.Header().Get("Location")
 			Expect(redirectUrl).To(Equal(user1Url))
+			Expect(recorder.Header().Get("Cache-Control")).To(Equal("private, no-cache"))
 		})
 
 		It("should reject due to invalid request", func() {
@@ -282,6 +288,88 @@ var _ = Describe("Server APIs", func() {
 			router.ServeHTTP(recorder, req)
 			Expect(recorder.Code).To(Equal(http.StatusNotFound))
 		})
+
+		It("should be served at the root without counting HEAD requests", func() {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest("HEAD", fmt.Sprintf("/%v", user1ShortenUrl), nil)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusTemporaryRedirect))
+			Expect(recorder.Header().Get("Location")).To(Equal(user1Url))
+		})
+
+		It("should not resolve reserved paths at the root", func() {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api", nil)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Redirect rules on countries", func() {
+		It("should locate visitors by peer address, not by X-Forwarded-For of untrusted peers", func() {
+			code := fmt.Sprintf("geo%v", time.Now().UnixNano())
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/v2/user/url/import?format=jsonl",
+				strings.NewReader(fmt.Sprintf(`{"shorten_url":"%v","origin_url":"https://example.com/geo"}`+"\n", code)))
+			req.Header.Set("Cookie", user1AccessTokenHeader)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			recorder = httptest.NewRecorder()
+			req = httptest.NewRequest("PUT", fmt.Sprintf("/api/v2/user/url/r/%v/rules", code),
+				strings.NewReader(`{"rules":[{"country":"DE","destination":"https://example.com/de"}]}`))
+			req.Header.Set("Cookie", user1AccessTokenHeader)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			recorder = httptest.NewRecorder()
+			req = httptest.NewRequest("HEAD", fmt.Sprintf("/%v", code), nil)
+			req.Header.Set("X-Forwarded-For", "198.51.100.1")
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusTemporaryRedirect))
+			Expect(recorder.Header().Get("Location")).To(Equal("https://example.com/geo"))
+
+			recorder = httptest.NewRecorder()
+			req = httptest.NewRequest("HEAD", fmt.Sprintf("/%v", code), nil)
+			req.RemoteAddr = "198.51.100.1:4321"
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusTemporaryRedirect))
+			Expect(recorder.Header().Get("Location")).To(Equal("https://example.com/de"))
+		})
+	})
+
+	Context("Report a shorten url", func() {
+		It("should not suspend it for reports from forged addresses", func() {
+			for i := 0; i < server.DefaultReportSuspendThreshold+1; i++ {
+				recorder := httptest.NewRecorder()
+				req := httptest.NewRequest("POST", fmt.Sprintf("/api/v2/report/%v", user1ShortenUrl),
+					strings.NewReader(`{"reason":"spam"}`))
+				req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%v", i+1))
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Or(Equal(http.StatusOK), Equal(http.StatusTooManyRequests)))
+			}
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", fmt.Sprintf("/api/shortener/r/%v", user1ShortenUrl), nil)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusTemporaryRedirect))
+		})
+	})
+
+	Context("Well-known association of apps", func() {
+		It("should serve configured content only", func() {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/.well-known/apple-app-site-association", nil)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+			Expect(recorder.Body.String()).To(Equal(`{"applinks":{"apps":[],"details":[]}}`))
+
+			recorder = httptest.NewRecorder()
+			req = httptest.NewRequest("GET", "/.well-known/assetlinks.json", nil)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
 	})
 
 	Context("Get user's urls", func() {
@@ -310,6 +398,159 @@ var _ = Describe("Server APIs", func() {
 		})
 	})
 
+	Context("Export and import user's urls", func() {
+		It("should export urls as csv and skip them when imported again", func() {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/v2/user/url/export?format=csv", nil)
+			req.Header.Set("Cookie", user1AccessTokenHeader)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("text/csv"))
+			exported := recorder.Body.String()
+			Expect(exported).To(HavePrefix("shorten_url,origin_url,"))
+			Expect(exported).To(ContainSubstring(user1ShortenUrl))
+
+			recorder = httptest.NewRecorder()
+			req = httptest.NewRequest("POST", "/api/v2/user/url/import?format=csv", strings.NewReader(exported))
+			req.Header.Set("Cookie", user1AccessTokenHeader)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var resp shortener.ImportResponse
+			Expect(getJSON(recorder.Result(), &resp)).To(Succeed())
+			Expect(resp.Imported).To(BeZero())
+			Expect(resp.Skipped).To(BeNumerically(">=", 1))
+		})
+
+		It("should import Bitly csv exports and report invalid rows", func() {
+			bitly := "Title,Bitlink,Long URL,Created,Total Clicks\n" +
+				"Example,https://bit.ly/imported1,https://example.com/imported,2021-03-04 12:34:56,42\n" +
+				"Broken,https://bit.ly/imported2,javascript:alert(1),2021-03-04 12:34:56,1\n"
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/v2/user/url/import", strings.NewReader(bitly))
+			req.Header.Set("Cookie", user1AccessTokenHeader)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var resp shortener.ImportResponse
+			Expect(getJSON(recorder.Result(), &resp)).To(Succeed())
+			Expect(resp.Imported + resp.Skipped).To(Equal(uint64(1)))
+			Expect(resp.Failures).To(HaveLen(1))
+			Expect(resp.Failures[0].Row).To(Equal(2))
+		})
+
+		It("should escape csv cells from being evaluated as formulas and unescape them when imported", func() {
+			code := fmt.Sprintf("formula%v", time.Now().UnixNano())
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/v2/user/url/import?format=jsonl", strings.NewReader(fmt.Sprintf(
+				`{"shorten_url":"%v","origin_url":"https://example.com/formula","title":"=1+1","folder":"-folder","note":"@note"}`+"\n", code)))
+			req.Header.Set("Cookie", user1AccessTokenHeader)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var resp shortener.ImportResponse
+			Expect(getJSON(recorder.Result(), &resp)).To(Succeed())
+			Expect(resp.Imported).To(Equal(uint64(1)))
+
+			recorder = httptest.NewRecorder()
+			req = httptest.NewRequest("GET", "/api/v2/user/url/export?format=csv", nil)
+			req.Header.Set("Cookie", user1AccessTokenHeader)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(ContainSubstring(code + ",https://example.com/formula,,'=1+1,'-folder,'@note,"))
+
+			unescaped := "un" + code
+			recorder = httptest.NewRecorder()
+			req = httptest.NewRequest("POST", "/api/v2/user/url/import?format=csv", strings.NewReader(
+				"shorten_url,origin_url,title,note\n"+unescaped+",https://example.com/unformula,'+1,'quoted\n"))
+			req.Header.Set("Cookie", user1AccessTokenHeader)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(getJSON(recorder.Result(), &resp)).To(Succeed())
+			Expect(resp.Imported).To(Equal(uint64(1)))
+
+			recorder = httptest.NewRecorder()
+			req = httptest.NewRequest("GET", "/api/v2/user/url/export?format=jsonl", nil)
+			req.Header.Set("Cookie", user1AccessTokenHeader)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var imported shortener.ExportedURL
+			for _, line := range strings.Split(recorder.Body.String(), "\n") {
+				if strings.Contains(line, unescaped) {
+					Expect(json.Unmarshal([]byte(line), &imported)).To(Succeed())
+				}
+			}
+			Expect(imported.Title).To(Equal("+1"))
+			Expect(imported.Note).To(Equal("'quoted"))
+		})
+	})
+
+	Context("Webhooks of workspace", func() {
+		It("should register a webhook returning its secret once and list its deliveries", func() {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/v2/workspaces/", nil)
+			req.Header.Set("Cookie", user1AccessTokenHeader)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var workspaces workspace.WorkspacesResponse
+			Expect(getJSON(recorder.Result(), &workspaces)).To(Succeed())
+			personal := workspaces.Workspaces[0].ID
+
+			recorder = httptest.NewRecorder()
+			req = httptest.NewRequest("POST", fmt.Sprintf("/api/v2/workspaces/%v/webhooks", personal),
+				strings.NewReader(`{"url":"ftp://example.com","events":["url.created"]}`))
+			req.Header.Set("Cookie", user1AccessTokenHeader)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+
+			recorder = httptest.NewRecorder()
+			req = httptest.NewRequest("POST", fmt.Sprintf("/api/v2/workspaces/%v/webhooks", personal),
+				strings.NewReader(`{"url":"https://example.com/hook","events":["url.created","url.clicks"],"click_thresholds":[1000,100]}`))
+			req.Header.Set("Cookie", user1AccessTokenHeader)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var created workspace.WebhookResponse
+			Expect(getJSON(recorder.Result(), &created)).To(Succeed())
+			Expect(created.Secret).NotTo(BeEmpty())
+			Expect(created.ClickThresholds).To(Equal([]uint64{100, 1000}))
+			Expect(created.Enabled).To(BeTrue())
+
+			code := fmt.Sprintf("hooked%v", time.Now().UnixNano())
+			recorder = httptest.NewRecorder()
+			req = httptest.NewRequest("POST", "/api/v2/user/url/import?format=jsonl",
+				strings.NewReader(fmt.Sprintf(`{"shorten_url":"%v","origin_url":"https://example.com/imported-hook"}`+"\n", code)))
+			req.Header.Set("Cookie", user1AccessTokenHeader)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var imported shortener.ImportResponse
+			Expect(getJSON(recorder.Result(), &imported)).To(Succeed())
+			Expect(imported.Imported).To(Equal(uint64(1)))
+
+			recorder = httptest.NewRecorder()
+			req = httptest.NewRequest("GET", fmt.Sprintf("/api/v2/workspaces/%v/webhooks", personal), nil)
+			req.Header.Set("Cookie", user1AccessTokenHeader)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(ContainSubstring(created.ID))
+			Expect(recorder.Body.String()).NotTo(ContainSubstring(created.Secret))
+
+			recorder = httptest.NewRecorder()
+			req = httptest.NewRequest("GET", fmt.Sprintf("/api/v2/workspaces/%v/webhooks/%v/deliveries", personal, created.ID), nil)
+			req.Header.Set("Cookie", user1AccessTokenHeader)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			var deliveries workspace.WebhookDeliveriesResponse
+			Expect(getJSON(recorder.Result(), &deliveries)).To(Succeed())
+			Expect(deliveries.Total).To(Equal(uint64(1)))
+			Expect(deliveries.Deliveries[0].Event).To(Equal("url.created"))
+			Expect(string(deliveries.Deliveries[0].Payload)).To(ContainSubstring(code))
+
+			recorder = httptest.NewRecorder()
+			req = httptest.NewRequest("DELETE", fmt.Sprintf("/api/v2/workspaces/%v/webhooks/%v", personal, created.ID), nil)
+			req.Header.Set("Cookie", user1AccessTokenHeader)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+		})
+	})
+
 	Context("Delete user's shorten url", func() {
 		It("should reject due to authorized problem", func() {
 			recorder := httptest.NewRecorder()
@@ -363,3 +604,10 @@ var _ = Describe("Server APIs", func() {
 func getJSON(response *http.Response, target interface{}) error {
 	return json.NewDecoder(response.Body).Decode(target)
 }
+
+// countryByIP locates visitors from a fixed table of addresses.
+type countryByIP map[string]string
+
+func (c countryByIP) Country(ip net.IP) (string, error) {
+	return c[ip.String()], nil
+}
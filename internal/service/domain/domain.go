@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+)
+
+const (
+	recordPrefix = "_url-shortener."
+	valuePrefix  = "url-shortener-verification="
+	tokenLength  = 16
+)
+
+// Resolver looks up DNS TXT records, *net.Resolver satisfies it.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+var ErrInvalidHostname = errors.New("invalid hostname")
+
+var labelPattern = regexp.MustCompile("^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$")
+
+// Normalize lowercases hostname and checks it is a fully qualified domain name rather than an ip address.
+func Normalize(hostname string) (string, error) {
+	hostname = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(hostname)), ".")
+	if len(hostname) > 253 || net.ParseIP(hostname) != nil {
+		return "", ErrInvalidHostname
+	}
+
+	labels := strings.Split(hostname, ".")
+	if len(labels) < 2 {
+		return "", ErrInvalidHostname
+	}
+	for _, label := range labels {
+		if !labelPattern.MatchString(label) {
+			return "", ErrInvalidHostname
+		}
+	}
+	return hostname, nil
+}
+
+// NewVerificationToken returns a random token to be published in a TXT record of the domain.
+func NewVerificationToken() (string, error) {
+	b := make([]byte, tokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// RecordName is the name of the TXT record proving control of hostname.
+func RecordName(hostname string) string {
+	return recordPrefix + hostname
+}
+
+// RecordValue is the content of the TXT record expected for token.
+func RecordValue(token string) string {
+	return valuePrefix + token
+}
+
+// Verify tells whether the TXT records of hostname contain token. A missing record is not an error.
+func Verify(ctx context.Context, resolver Resolver, hostname string, token string) (bool, error) {
+	records, err := resolver.LookupTXT(ctx, RecordName(hostname))
+	if err != nil {
+		if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	expected := RecordValue(token)
+	for _, record := range records {
+		if strings.TrimSpace(record) == expected {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,70 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"url-shortener/internal/service/domain"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if name == "_url-shortener.broken.example.com" {
+		return nil, errors.New("server misbehaving")
+	}
+	records, ok := r[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+var _ = Describe("Domain", func() {
+	Describe("Normalize", func() {
+		It("should lowercase and drop trailing dot", func() {
+			hostname, err := domain.Normalize(" Go.Example.COM. ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hostname).To(Equal("go.example.com"))
+		})
+
+		It("should reject what is not a domain name", func() {
+			for _, hostname := range []string{"", "localhost", "127.0.0.1", "-bad.example.com", "exa_mple.com", "example..com", "https://example.com"} {
+				_, err := domain.Normalize(hostname)
+				Expect(err).To(Equal(domain.ErrInvalidHostname), hostname)
+			}
+		})
+	})
+
+	Describe("Verify", func() {
+		resolver := fakeResolver{
+			"_url-shortener.go.example.com": {"v=spf1 -all", "url-shortener-verification=abc123"},
+		}
+
+		It("should find token among the records", func() {
+			ok, err := domain.Verify(context.Background(), resolver, "go.example.com", "abc123")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should not accept another token", func() {
+			ok, err := domain.Verify(context.Background(), resolver, "go.example.com", "other")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should treat missing record as unverified", func() {
+			ok, err := domain.Verify(context.Background(), resolver, "new.example.com", "abc123")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should report failing lookups", func() {
+			_, err := domain.Verify(context.Background(), resolver, "broken.example.com", "abc123")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -3,10 +3,11 @@ package mail
 import (
 	"context"
 	"fmt"
-	"log"
+	"github.com/sirupsen/logrus"
 	"net/smtp"
 	"strings"
 	"time"
+	"url-shortener/internal/metrics"
 )
 
 type EmailServiceOptions struct {
@@ -28,42 +29,40 @@ func (s SendEmailOptions) toBodyBytes() []byte {
 		"%v\r\n", s.To, s.Subject, s.Message))
 }
 
-var emailServiceLogTag = "EmailService"
-
-func logMessage(msg interface{}) {
-	log.Printf("%v: %v\n", emailServiceLogTag, msg)
-}
+var logger = logrus.WithField("service", "EmailService")
 
 func StartEmailService(ctx context.Context, c *EmailServiceOptions, incoming <-chan SendEmailOptions) {
 	if c == nil {
-		logMessage("Service disabled")
+		logger.Info("Service disabled")
 		return
 	}
 
 	auth := smtp.PlainAuth("", c.Email, c.Password, strings.Split(c.Server, ":")[0])
 
-	logMessage("Started...")
+	logger.Info("Started...")
 
 	for {
-		logMessage("Awaiting another incoming request for sending email...")
+		logger.Debug("Awaiting another incoming request for sending email...")
 
 		req := <-incoming
 		go func(req SendEmailOptions) {
-			logMessage("=== Requested ===")
-			logMessage(fmt.Sprintf("Recipient: %v", req.To))
-			logMessage(fmt.Sprintf("Content: %v", req.Message))
-			logMessage("=================")
+			// note: message content is never logged as it may carry verification codes
+			reqLogger := logger.WithFields(logrus.Fields{
+				"recipient": req.To,
+				"subject":   req.Subject,
+			})
+			reqLogger.Info("Requested")
 
 			_ctx, cancel := context.WithTimeout(ctx, time.Second*30)
-			done := make(chan bool)
+			defer cancel()
+			done := make(chan bool, 1)
 
 			go func(isDone chan<- bool) {
-				defer cancel()
-
 				err := smtp.SendMail(c.Server, auth, c.Email, []string{req.To}, req.toBodyBytes())
 				if err != nil {
-					logMessage(fmt.Sprintf("Sending email failed | Reason: %v", err))
+					reqLogger.WithError(err).Error("Sending email failed")
 					isDone <- false
+					return
 				}
 				isDone <- true
 			}(done)
@@ -74,15 +73,18 @@ func StartEmailService(ctx context.Context, c *EmailServiceOptions, incoming <-c
 				select {
 				case isDone := <-done:
 					if isDone {
-						logMessage("Sending email succeed")
+						reqLogger.Info("Sending email succeed")
+						metrics.EmailsSent.WithLabelValues(metrics.EmailSuccess).Inc()
+					} else {
+						metrics.EmailsSent.WithLabelValues(metrics.EmailFailure).Inc()
 					}
 
 					completed = true
 				case <-_ctx.Done():
-					logMessage("Sending email timed out")
+					reqLogger.Warn("Sending email timed out")
+					metrics.EmailsSent.WithLabelValues(metrics.EmailTimeout).Inc()
 
 					completed = true
-				default:
 				}
 
 				if completed {
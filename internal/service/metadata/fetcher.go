@@ -0,0 +1,183 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	url2 "net/url"
+	"syscall"
+	"time"
+)
+
+// Metadata describes the destination page of a url.
+type Metadata struct {
+	Title       string
+	Description string
+	Image       string // Open Graph image
+	Favicon     string
+	Status      int // http status of the last response, 0 if none was received
+}
+
+type FetcherOptions struct {
+	Timeout              time.Duration
+	MaxBodySize          int64
+	MaxRedirects         int
+	AllowPrivateNetworks bool // only meant for tests against local servers
+}
+
+// DefaultFetcherOptions is used by the service unless overridden.
+var DefaultFetcherOptions = FetcherOptions{
+	Timeout:      5 * time.Second,
+	MaxBodySize:  1 << 20,
+	MaxRedirects: 5,
+}
+
+var ErrPrivateAddress = errors.New("destination resolves to a non-public address")
+
+// maxDrainedBodySize bounds what DrainBody reads, larger bodies are not worth keeping the connection for.
+const maxDrainedBodySize = 64 << 10
+
+// Fetcher downloads destination pages. Addresses are checked right before connecting,
+// so a hostname can not be rebound to a private address after validation.
+type Fetcher struct {
+	client      *http.Client
+	maxBodySize int64
+}
+
+func NewFetcher(options FetcherOptions) *Fetcher {
+	return &Fetcher{
+		client:      NewHTTPClient(options),
+		maxBodySize: options.MaxBodySize,
+	}
+}
+
+// DrainBody reads what is left of a response body, up to maxDrainedBodySize, so that the connection can be reused
+// by the client. It is meant for responses whose body is of no interest, before closing it.
+func DrainBody(body io.Reader) {
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(body, maxDrainedBodySize))
+}
+
+// NewHTTPClient returns a client honouring timeouts and redirect limit of options,
+// which refuses to connect to non-public addresses unless AllowPrivateNetworks is set.
+func NewHTTPClient(options FetcherOptions) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: options.Timeout,
+	}
+	if !options.AllowPrivateNetworks {
+		dialer.Control = func(network string, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+				return ErrPrivateAddress
+			}
+			return nil
+		}
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // a proxy would hide the address actually connected to
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   options.Timeout,
+		ResponseHeaderTimeout: options.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   options.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > options.MaxRedirects {
+				return fmt.Errorf("stopped after %v redirects", options.MaxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("unsupported redirect scheme %v", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// Fetch requests rawURL and extracts metadata from its html head.
+// A response with an error status is not an error, its status is reported in Metadata.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Metadata, error) {
+	u, err := url2.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %v", u.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "url-shortener-metadata/1.0")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	m := &Metadata{Status: res.StatusCode}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return m, nil
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(res.Header.Get("Content-Type")); err != nil ||
+		(mediaType != "text/html" && mediaType != "application/xhtml+xml") {
+		return m, nil
+	}
+
+	parseHead(io.LimitReader(res.Body, f.maxBodySize), res.Request.URL, m)
+	return m, nil
+}
+
+var nonPublicNetworks = func() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range []string{
+		"0.0.0.0/8",
+		"10.0.0.0/8",
+		"100.64.0.0/10",
+		"127.0.0.0/8",
+		"169.254.0.0/16",
+		"172.16.0.0/12",
+		"192.0.0.0/24",
+		"192.168.0.0/16",
+		"198.18.0.0/15",
+		"224.0.0.0/4",
+		"240.0.0.0/4",
+		"::/128",
+		"::1/128",
+		"fc00::/7",
+		"fe80::/10",
+		"ff00::/8",
+	} {
+		_, network, _ := net.ParseCIDR(cidr)
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// IsPublicIP reports whether ip is routable on the public internet.
+func IsPublicIP(ip net.IP) bool {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package metadata_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"url-shortener/internal/service/metadata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fetcher", func() {
+	var destination *httptest.Server
+
+	BeforeEach(func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(`<!doctype html><html><head>
+				<title>  Example
+				page </title>
+				<meta name="description" content="An example page">
+				<meta property="og:image" content="/images/cover.png">
+				<link rel="shortcut icon" href="https://cdn.example.com/icon.png">
+				</head><body><title>not this one</title></body></html>`))
+		})
+		mux.HandleFunc("/huge", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html><head>" + strings.Repeat("<!-- padding -->", 1<<16) + "<title>too late</title></head></html>"))
+		})
+		mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		})
+		destination = httptest.NewServer(mux)
+	})
+
+	AfterEach(func() {
+		destination.Close()
+	})
+
+	Context("with private networks allowed", func() {
+		var fetcher *metadata.Fetcher
+
+		BeforeEach(func() {
+			options := metadata.DefaultFetcherOptions
+			options.AllowPrivateNetworks = true
+			options.MaxBodySize = 4096
+			fetcher = metadata.NewFetcher(options)
+		})
+
+		It("should extract metadata from html head", func() {
+			m, err := fetcher.Fetch(context.Background(), destination.URL+"/page")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.Status).To(Equal(http.StatusOK))
+			Expect(m.Title).To(Equal("Example page"))
+			Expect(m.Description).To(Equal("An example page"))
+			Expect(m.Image).To(Equal(destination.URL + "/images/cover.png"))
+			Expect(m.Favicon).To(Equal("https://cdn.example.com/icon.png"))
+		})
+
+		It("should stop reading at the size cap", func() {
+			m, err := fetcher.Fetch(context.Background(), destination.URL+"/huge")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.Title).To(BeEmpty())
+		})
+
+		It("should report error status without metadata", func() {
+			m, err := fetcher.Fetch(context.Background(), destination.URL+"/gone")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.Status).To(Equal(http.StatusNotFound))
+			Expect(m.Title).To(BeEmpty())
+		})
+	})
+
+	It("should refuse to connect to private addresses", func() {
+		fetcher := metadata.NewFetcher(metadata.DefaultFetcherOptions)
+		_, err := fetcher.Fetch(context.Background(), destination.URL+"/page")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(metadata.ErrPrivateAddress.Error()))
+	})
+
+	It("should tell public addresses from private ones", func() {
+		Expect(metadata.IsPublicIP(net.ParseIP("8.8.8.8"))).To(BeTrue())
+		Expect(metadata.IsPublicIP(net.ParseIP("2001:4860:4860::8888"))).To(BeTrue())
+		Expect(metadata.IsPublicIP(net.ParseIP("127.0.0.1"))).To(BeFalse())
+		Expect(metadata.IsPublicIP(net.ParseIP("10.1.2.3"))).To(BeFalse())
+		Expect(metadata.IsPublicIP(net.ParseIP("169.254.169.254"))).To(BeFalse())
+		Expect(metadata.IsPublicIP(net.ParseIP("::ffff:192.168.0.1"))).To(BeFalse())
+		Expect(metadata.IsPublicIP(net.ParseIP("fd00::1"))).To(BeFalse())
+	})
+})
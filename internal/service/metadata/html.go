@@ -0,0 +1,113 @@
+package metadata
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"io"
+	url2 "net/url"
+	"strings"
+)
+
+const maxTextLength = 1000
+
+// parseHead fills m with title, description, image and favicon declared in the html head read from r.
+// Relative links are resolved against base.
+func parseHead(r io.Reader, base *url2.URL, m *Metadata) {
+	var description, ogTitle, ogDescription, ogImage, icon string
+	var title strings.Builder
+	inTitle := false
+
+	z := html.NewTokenizer(r)
+	for done := false; !done; {
+		switch z.Next() {
+		case html.ErrorToken:
+			done = true
+		case html.TextToken:
+			if inTitle {
+				title.Write(z.Text())
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Title:
+				inTitle = false
+			case atom.Head:
+				done = true
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			tag := atom.Lookup(name)
+			attrs := map[string]string{}
+			for hasAttr {
+				var key, value []byte
+				key, value, hasAttr = z.TagAttr()
+				attrs[string(key)] = string(value)
+			}
+
+			switch tag {
+			case atom.Title:
+				inTitle = true
+			case atom.Body:
+				done = true
+			case atom.Meta:
+				content := attrs["content"]
+				switch strings.ToLower(attrs["name"]) {
+				case "description":
+					description = content
+				}
+				switch strings.ToLower(attrs["property"]) {
+				case "og:title":
+					ogTitle = content
+				case "og:description":
+					ogDescription = content
+				case "og:image", "og:image:url":
+					if ogImage == "" {
+						ogImage = content
+					}
+				}
+			case atom.Link:
+				for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+					if rel == "icon" && icon == "" {
+						icon = attrs["href"]
+					}
+				}
+			}
+		}
+	}
+
+	m.Title = clean(firstNonEmpty(title.String(), ogTitle))
+	m.Description = clean(firstNonEmpty(description, ogDescription))
+	m.Image = resolve(base, ogImage)
+	m.Favicon = resolve(base, firstNonEmpty(icon, "/favicon.ico"))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// clean collapses whitespace and truncates text to maxTextLength characters.
+func clean(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if runes := []rune(s); len(runes) > maxTextLength {
+		s = string(runes[:maxTextLength])
+	}
+	return s
+}
+
+// resolve returns ref as absolute http(s) url, empty if it is not one.
+func resolve(base *url2.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
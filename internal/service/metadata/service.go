@@ -0,0 +1,106 @@
+package metadata
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+	"url-shortener/internal/database"
+)
+
+type ServiceOptions struct {
+	Fetcher       FetcherOptions
+	RefreshAfter  time.Duration // metadata older than this is fetched again
+	CheckInterval time.Duration // how often stale metadata is looked for
+	BatchSize     uint64
+	Concurrency   int
+}
+
+var logger = logrus.WithField("service", "MetadataService")
+
+// StartMetadataService fetches metadata of each shorten url received from incoming,
+// and periodically refreshes the stale ones. It returns once ctx is done.
+func StartMetadataService(ctx context.Context, c *ServiceOptions, db database.MySQLService, incoming <-chan string) {
+	if c == nil {
+		logger.Info("Service disabled")
+		return
+	}
+
+	fetcher := NewFetcher(c.Fetcher)
+	concurrency := c.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	refresh := func(url database.URL) {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			refreshURL(ctx, fetcher, db, url)
+		}()
+	}
+
+	ticker := time.NewTicker(c.CheckInterval)
+	defer ticker.Stop()
+
+	logger.Info("Started...")
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Stopped")
+			return
+		case shortenURL := <-incoming:
+			url, err := db.GetURLWithShortenURL(shortenURL)
+			if err != nil {
+				logger.WithError(err).WithField("shorten_url", shortenURL).Warn("Unable to query url to fetch metadata for")
+				continue
+			}
+			refresh(*url)
+		case <-ticker.C:
+			urls, err := db.GetURLsForMetadataRefresh(time.Now().Add(-c.RefreshAfter), c.BatchSize)
+			if err != nil {
+				logger.WithError(err).Error("Unable to query urls with stale metadata")
+				continue
+			}
+			logger.WithField("count", len(urls)).Debug("Refreshing stale metadata")
+			for _, url := range urls {
+				refresh(url)
+			}
+		}
+	}
+}
+
+// refreshURL fetches and stores metadata of url. Previously fetched fields are kept if the destination
+// did not answer with a page this time, only the status is updated then.
+func refreshURL(ctx context.Context, fetcher *Fetcher, db database.MySQLService, url database.URL) {
+	urlLogger := logger.WithField("shorten_url", url.ShortenURL)
+
+	stored := url.Metadata
+	stored.FetchedAt = time.Now()
+
+	m, err := fetcher.Fetch(ctx, url.OriginURL)
+	if err != nil {
+		urlLogger.WithError(err).Info("Unable to fetch destination")
+		stored.Status = 0
+	} else {
+		stored.Status = m.Status
+		if m.Status >= 200 && m.Status < 300 {
+			stored.Title = m.Title
+			stored.Description = m.Description
+			stored.Image = m.Image
+			stored.Favicon = m.Favicon
+		}
+	}
+
+	if err := db.UpdateURLMetadata(url.ShortenURL, stored); err != nil {
+		urlLogger.WithError(err).Warn("Unable to store metadata")
+	}
+}
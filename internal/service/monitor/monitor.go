@@ -0,0 +1,264 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	url2 "net/url"
+	"strings"
+	"sync"
+	"time"
+	"url-shortener/internal/database"
+	"url-shortener/internal/service/mail"
+	"url-shortener/internal/service/metadata"
+)
+
+type ServiceOptions struct {
+	Client           metadata.FetcherOptions
+	Interval         time.Duration // how often every url is checked
+	AlertAfter       time.Duration // how long a destination stays broken before its owner is told
+	HistoryRetention time.Duration
+	BatchSize        uint64
+	Concurrency      int
+	BaseUrl          string // used to print shorten urls in digests
+}
+
+var logger = logrus.WithField("service", "MonitorService")
+
+// Monitor checks destinations of stored urls and alerts owners about the ones staying broken.
+type Monitor struct {
+	options      ServiceOptions
+	db           database.MySQLService
+	client       *http.Client
+	emailRequest chan<- mail.SendEmailOptions
+}
+
+func NewMonitor(c ServiceOptions, db database.MySQLService, emailRequest chan<- mail.SendEmailOptions) *Monitor {
+	if c.BatchSize == 0 {
+		c.BatchSize = 100
+	}
+	if c.Concurrency < 1 {
+		c.Concurrency = 1
+	}
+	return &Monitor{
+		options:      c,
+		db:           db,
+		client:       metadata.NewHTTPClient(c.Client),
+		emailRequest: emailRequest,
+	}
+}
+
+// StartMonitorService runs a round of checks every c.Interval until ctx is done.
+func StartMonitorService(ctx context.Context, c *ServiceOptions, db database.MySQLService, emailRequest chan<- mail.SendEmailOptions) {
+	if c == nil {
+		logger.Info("Service disabled")
+		return
+	}
+
+	m := NewMonitor(*c, db, emailRequest)
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	logger.Info("Started...")
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Stopped")
+			return
+		case <-ticker.C:
+			m.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce checks every url, sends pending digests and prunes expired history.
+func (m *Monitor) RunOnce(ctx context.Context) {
+	m.CheckAll(ctx)
+	m.SendAlerts()
+
+	if m.options.HistoryRetention > 0 {
+		if err := m.db.DeleteURLChecksBefore(time.Now().Add(-m.options.HistoryRetention)); err != nil {
+			logger.WithError(err).Warn("Unable to prune url check history")
+		}
+	}
+}
+
+// CheckAll probes the destination of every stored url and records the outcome.
+func (m *Monitor) CheckAll(ctx context.Context) {
+	slots := make(chan struct{}, m.options.Concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	after := ""
+	for {
+		urls, err := m.db.GetURLsAfter(after, m.options.BatchSize)
+		if err != nil {
+			logger.WithError(err).Error("Unable to query urls to check")
+			return
+		}
+
+		for _, url := range urls {
+			select {
+			case <-ctx.Done():
+				return
+			case slots <- struct{}{}:
+			}
+
+			wg.Add(1)
+			go func(url database.URL) {
+				defer func() {
+					<-slots
+					wg.Done()
+				}()
+
+				check := m.check(ctx, url)
+				if err := m.db.CreateURLCheck(check); err != nil {
+					logger.WithError(err).WithField("shorten_url", url.ShortenURL).Warn("Unable to record url check")
+				}
+			}(url)
+		}
+
+		if uint64(len(urls)) < m.options.BatchSize {
+			return
+		}
+		after = urls[len(urls)-1].ShortenURL
+	}
+}
+
+// check requests url.OriginURL with HEAD, falling back to GET for servers not supporting it.
+func (m *Monitor) check(ctx context.Context, url database.URL) database.URLCheck {
+	check := database.URLCheck{ShortenURL: url.ShortenURL}
+
+	status, err := m.request(ctx, http.MethodHead, url.OriginURL)
+	if err != nil || status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented {
+		status, err = m.request(ctx, http.MethodGet, url.OriginURL)
+	}
+
+	check.CheckedAt = time.Now()
+	check.Status = status
+	if err != nil {
+		check.Error = err.Error()
+	}
+	check.Broken = IsBroken(status, err)
+	return check
+}
+
+func (m *Monitor) request(ctx context.Context, method string, rawURL string) (int, error) {
+	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+		return 0, fmt.Errorf("unsupported scheme of %v", rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "url-shortener-monitor/1.0")
+
+	res, err := m.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	metadata.DrainBody(res.Body)
+
+	return res.StatusCode, nil
+}
+
+// IsBroken tells whether a check outcome means the destination is gone. Statuses used by
+// servers to turn away bots (e.g. 401, 403, 429) are not considered broken.
+func IsBroken(status int, err error) bool {
+	if err != nil {
+		return true
+	}
+	return status == http.StatusNotFound || status == http.StatusGone || status >= 500
+}
+
+// SendAlerts emails owners of each workspace a digest of urls broken for longer than AlertAfter, once per breakage.
+// Owners who turned alerts off are skipped. Nothing is sent without an email request channel. The digests of
+// a workspace are postponed together unless the queue has room for every owner, so that none is sent twice.
+func (m *Monitor) SendAlerts() {
+	if m.emailRequest == nil {
+		return
+	}
+
+	urls, err := m.db.GetBrokenURLsToAlert(time.Now().Add(-m.options.AlertAfter))
+	if err != nil {
+		logger.WithError(err).Error("Unable to query broken urls")
+		return
+	}
+
+	byWorkspace := map[string][]database.URL{}
+	var workspaces []string
+	for _, url := range urls {
+		if _, ok := byWorkspace[url.Owner]; !ok {
+			workspaces = append(workspaces, url.Owner)
+		}
+		byWorkspace[url.Owner] = append(byWorkspace[url.Owner], url)
+	}
+
+	for _, workspaceID := range workspaces {
+		workspaceLogger := logger.WithField("workspace_id", workspaceID)
+		members, err := m.db.GetWorkspaceMembers(workspaceID)
+		if err != nil {
+			workspaceLogger.WithError(err).Warn("Unable to query members of workspace with broken urls")
+			continue
+		}
+
+		var recipients []database.User
+		for _, member := range members {
+			if member.Role == database.WorkspaceRoleOwner && !member.User.DeadLinkAlertsDisabled {
+				recipients = append(recipients, member.User)
+			}
+		}
+		if cap(m.emailRequest)-len(m.emailRequest) < len(recipients) {
+			workspaceLogger.Warn("Email request queue is full, digest postponed")
+			continue
+		}
+
+		for _, recipient := range recipients {
+			select {
+			case m.emailRequest <- m.digest(recipient, byWorkspace[workspaceID]):
+			default:
+				// note: other senders filled the queue meanwhile, urls are marked alerted anyway not to repeat the others
+				workspaceLogger.WithField("user_id", recipient.UserID).Warn("Email request queue is full, digest dropped")
+			}
+		}
+
+		shortenURLs := make([]string, len(byWorkspace[workspaceID]))
+		for i, url := range byWorkspace[workspaceID] {
+			shortenURLs[i] = url.ShortenURL
+		}
+		if err := m.db.MarkURLsAlerted(shortenURLs, time.Now()); err != nil {
+			workspaceLogger.WithError(err).Warn("Unable to mark urls alerted")
+		}
+	}
+}
+
+// shortLink prints url as served at the root of its domain.
+func (m *Monitor) shortLink(url database.URL) string {
+	base := m.options.BaseUrl
+	if url.Domain != "" {
+		if u, err := url2.Parse(base); err == nil {
+			base = u.Scheme + "://" + url.Domain
+		}
+	}
+	return base + "/" + url.ShortenURL
+}
+
+func (m *Monitor) digest(user database.User, urls []database.URL) mail.SendEmailOptions {
+	var b strings.Builder
+	b.WriteString("The destinations of following links have not been reachable for a while:\r\n\r\n")
+	for _, url := range urls {
+		fmt.Fprintf(&b, "%v -> %v (broken since %v)\r\n",
+			m.shortLink(url), url.OriginURL, url.BrokenSince.UTC().Format(time.RFC1123))
+	}
+	b.WriteString("\r\nYou can turn these alerts off in your preferences.")
+
+	return mail.SendEmailOptions{
+		To:      user.Email,
+		Subject: fmt.Sprintf("Broken links in your account (%v)", len(urls)),
+		Message: b.String(),
+	}
+}
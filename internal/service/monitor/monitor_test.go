@@ -0,0 +1,144 @@
+package monitor_test
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"time"
+	"url-shortener/internal/database"
+	mocks "url-shortener/internal/database/mocks"
+	"url-shortener/internal/service/mail"
+	"url-shortener/internal/service/metadata"
+	"url-shortener/internal/service/monitor"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Monitor", func() {
+	var (
+		ctrl         *gomock.Controller
+		db           *mocks.MockMySQLService
+		destination  *httptest.Server
+		emailRequest chan mail.SendEmailOptions
+		m            *monitor.Monitor
+		owner        database.User
+		members      []database.WorkspaceMember
+		alive        database.URL
+		gone         database.URL
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		db = mocks.NewMockMySQLService(ctrl)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/alive", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		})
+		destination = httptest.NewServer(mux)
+
+		owner = database.User{UserID: "owner", Email: "owner@test.com"}
+		members = []database.WorkspaceMember{{WorkspaceID: owner.UserID, User: owner, Role: database.WorkspaceRoleOwner}}
+		alive = database.URL{ShortenURL: "a1", Owner: owner.UserID, OriginURL: destination.URL + "/alive"}
+		gone = database.URL{ShortenURL: "b2", Owner: owner.UserID, OriginURL: destination.URL + "/no-head"}
+
+		emailRequest = make(chan mail.SendEmailOptions, 1)
+		client := metadata.DefaultFetcherOptions
+		client.AllowPrivateNetworks = true
+		m = monitor.NewMonitor(monitor.ServiceOptions{
+			Client:     client,
+			AlertAfter: time.Hour,
+			BatchSize:  10,
+			BaseUrl:    "http://short.test",
+		}, db, emailRequest)
+	})
+
+	AfterEach(func() {
+		destination.Close()
+		ctrl.Finish()
+	})
+
+	It("should record status of every destination", func() {
+		db.EXPECT().GetURLsAfter("", uint64(10)).Return([]database.URL{alive, gone}, nil)
+
+		checks := map[string]database.URLCheck{}
+		recorded := make(chan database.URLCheck, 2)
+		db.EXPECT().CreateURLCheck(gomock.Any()).Times(2).DoAndReturn(func(check database.URLCheck) error {
+			recorded <- check
+			return nil
+		})
+
+		m.CheckAll(context.Background())
+		close(recorded)
+		for check := range recorded {
+			checks[check.ShortenURL] = check
+		}
+
+		Expect(checks[alive.ShortenURL].Status).To(Equal(http.StatusOK))
+		Expect(checks[alive.ShortenURL].Broken).To(BeFalse())
+		Expect(checks[gone.ShortenURL].Status).To(Equal(http.StatusNotFound))
+		Expect(checks[gone.ShortenURL].Broken).To(BeTrue())
+	})
+
+	It("should send only owners who did not opt out a digest", func() {
+		viewer := database.User{UserID: "viewer", Email: "viewer@test.com"}
+		quiet := database.User{UserID: "quiet", Email: "quiet@test.com", DeadLinkAlertsDisabled: true}
+		db.EXPECT().GetBrokenURLsToAlert(gomock.Any()).Return([]database.URL{gone}, nil)
+		db.EXPECT().GetWorkspaceMembers(owner.UserID).Return(append(members,
+			database.WorkspaceMember{WorkspaceID: owner.UserID, User: viewer, Role: database.WorkspaceRoleViewer},
+			database.WorkspaceMember{WorkspaceID: owner.UserID, User: quiet, Role: database.WorkspaceRoleOwner},
+		), nil)
+		db.EXPECT().MarkURLsAlerted([]string{gone.ShortenURL}, gomock.Any()).Return(nil)
+
+		m.SendAlerts()
+
+		Expect(emailRequest).To(HaveLen(1))
+		Expect((<-emailRequest).To).To(Equal(owner.Email))
+	})
+
+	It("should send owner a digest of urls broken for too long", func() {
+		gone.BrokenSince = time.Now().Add(-2 * time.Hour)
+		db.EXPECT().GetBrokenURLsToAlert(gomock.Any()).Return([]database.URL{gone}, nil)
+		db.EXPECT().GetWorkspaceMembers(owner.UserID).Return(members, nil)
+		db.EXPECT().MarkURLsAlerted([]string{gone.ShortenURL}, gomock.Any()).Return(nil)
+
+		m.SendAlerts()
+
+		Expect(emailRequest).To(HaveLen(1))
+		email := <-emailRequest
+		Expect(email.To).To(Equal(owner.Email))
+		Expect(email.Message).To(ContainSubstring(gone.OriginURL))
+		Expect(email.Message).To(ContainSubstring("http://short.test/" + gone.ShortenURL))
+	})
+
+	It("should not mark urls alerted when digest could not be queued", func() {
+		emailRequest <- mail.SendEmailOptions{}
+		db.EXPECT().GetBrokenURLsToAlert(gomock.Any()).Return([]database.URL{gone}, nil)
+		db.EXPECT().GetWorkspaceMembers(owner.UserID).Return(members, nil)
+		db.EXPECT().MarkURLsAlerted(gomock.Any(), gomock.Any()).Times(0)
+
+		m.SendAlerts()
+	})
+
+	It("should postpone digests of a workspace unless every owner can be queued", func() {
+		coOwner := database.User{UserID: "co-owner", Email: "co-owner@test.com"}
+		db.EXPECT().GetBrokenURLsToAlert(gomock.Any()).Return([]database.URL{gone}, nil)
+		db.EXPECT().GetWorkspaceMembers(owner.UserID).Return(append(members,
+			database.WorkspaceMember{WorkspaceID: owner.UserID, User: coOwner, Role: database.WorkspaceRoleOwner},
+		), nil)
+		db.EXPECT().MarkURLsAlerted(gomock.Any(), gomock.Any()).Times(0)
+
+		m.SendAlerts()
+
+		Expect(emailRequest).To(BeEmpty())
+	})
+})
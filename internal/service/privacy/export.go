@@ -0,0 +1,373 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"time"
+	"url-shortener/internal/database"
+	"url-shortener/internal/service/mail"
+)
+
+type ServiceOptions struct {
+	Retention time.Duration // how long archives can be downloaded once requested
+	Interval  time.Duration // how often exports not received from incoming are looked for, and expired ones removed
+	BatchSize uint64
+	BaseUrl   string // used to print the download link in notifications
+}
+
+var logger = logrus.WithField("service", "DataExportService")
+
+// Exporter packages everything held about users requesting it into zip archives of json files.
+type Exporter struct {
+	options      ServiceOptions
+	db           database.MySQLService
+	emailRequest chan<- mail.SendEmailOptions
+	now          func() time.Time
+}
+
+func NewExporter(c ServiceOptions, db database.MySQLService, emailRequest chan<- mail.SendEmailOptions, now func() time.Time) *Exporter {
+	if c.BatchSize == 0 {
+		c.BatchSize = 100
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return &Exporter{
+		options:      c,
+		db:           db,
+		emailRequest: emailRequest,
+		now:          now,
+	}
+}
+
+// StartExportService packages each export whose id is received from incoming, and every c.Interval the ones
+// pending since a restart, until ctx is done. Users are notified by email once their archive is ready.
+func StartExportService(ctx context.Context, c *ServiceOptions, db database.MySQLService, incoming <-chan string, emailRequest chan<- mail.SendEmailOptions) {
+	if c == nil {
+		logger.Info("Service disabled")
+		return
+	}
+
+	e := NewExporter(*c, db, emailRequest, nil)
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	logger.Info("Started...")
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Stopped")
+			return
+		case id := <-incoming:
+			export, err := db.GetDataExport(id)
+			if err != nil {
+				logger.WithError(err).WithField("export_id", id).Warn("Unable to query export to package")
+				continue
+			}
+			if export.Status == database.DataExportStatusPending {
+				e.Export(*export)
+			}
+		case <-ticker.C:
+			e.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce packages pending exports and removes the expired ones.
+func (e *Exporter) RunOnce(ctx context.Context) {
+	exports, err := e.db.GetPendingDataExports(e.options.BatchSize)
+	if err != nil {
+		logger.WithError(err).Error("Unable to query pending exports")
+	}
+	for _, export := range exports {
+		if ctx.Err() != nil {
+			return
+		}
+		e.Export(export)
+	}
+
+	if err := e.db.DeleteDataExportsBefore(e.now().Add(-e.options.Retention)); err != nil {
+		logger.WithError(err).Warn("Unable to remove expired exports")
+	}
+}
+
+// Export packages export and notifies its user. Failed exports are marked so, for users to request another one.
+func (e *Exporter) Export(export database.DataExport) {
+	exportLogger := logger.WithField("export_id", export.ID).WithField("user_id", export.UserID)
+
+	user, err := e.db.GetUserWithID(export.UserID)
+	if err != nil {
+		exportLogger.WithError(err).Error("Unable to query user of export")
+		e.fail(exportLogger, export)
+		return
+	}
+
+	archive, err := e.Archive(*user)
+	if err != nil {
+		exportLogger.WithError(err).Error("Unable to package export")
+		e.fail(exportLogger, export)
+		return
+	}
+
+	expiresAt := export.CreatedAt.Add(e.options.Retention)
+	if err := e.db.CompleteDataExport(export.ID, archive, e.now(), expiresAt); err != nil {
+		exportLogger.WithError(err).Error("Unable to store export")
+		return
+	}
+	exportLogger.WithField("size", len(archive)).Info("Export ready")
+
+	if e.emailRequest == nil {
+		return
+	}
+	select {
+	case e.emailRequest <- e.notification(*user, export.ID, expiresAt):
+	default:
+		exportLogger.Warn("Email request queue is full, user is not notified")
+	}
+}
+
+func (e *Exporter) fail(exportLogger *logrus.Entry, export database.DataExport) {
+	if err := e.db.FailDataExport(export.ID, e.now()); err != nil {
+		exportLogger.WithError(err).Warn("Unable to mark export failed")
+	}
+}
+
+func (e *Exporter) notification(user database.User, id string, expiresAt time.Time) mail.SendEmailOptions {
+	return mail.SendEmailOptions{
+		To:      user.Email,
+		Subject: "Your data export is ready",
+		Message: fmt.Sprintf("The copy of your data you requested is ready. Once signed in, download it from:\r\n\r\n"+
+			"%v/api/v2/user/data-export/%v\r\n\r\nIt is available until %v.",
+			e.options.BaseUrl, id, expiresAt.UTC().Format(time.RFC1123)),
+	}
+}
+
+type accountFile struct {
+	UserID                 string `json:"user_id"`
+	Email                  string `json:"email"`
+	Type                   string `json:"type"`
+	Role                   string `json:"role"`
+	Disabled               bool   `json:"disabled"`
+	DeadLinkAlertsDisabled bool   `json:"dead_link_alerts_disabled"`
+}
+
+type googleAccountFile struct {
+	GoogleUUID string `json:"google_uuid"`
+}
+
+type workspaceFile struct {
+	WorkspaceID string    `json:"workspace_id"`
+	Name        string    `json:"name"`
+	Personal    bool      `json:"personal"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type linkFile struct {
+	ShortenURL     string     `json:"shorten_url"`
+	OriginURL      string     `json:"origin_url"`
+	Workspace      string     `json:"workspace"`
+	CreatedBy      string     `json:"created_by"`
+	Domain         string     `json:"domain"`
+	Title          string     `json:"title"`
+	Folder         string     `json:"folder"`
+	Note           string     `json:"note"`
+	Tags           []string   `json:"tags"`
+	RedirectStatus int        `json:"redirect_status"`
+	Analytics      bool       `json:"analytics"`
+	AppURI         string     `json:"app_uri"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+}
+
+// analyticsFile holds the hits counted for a link. Visits are only counted, nothing is kept about visitors.
+type analyticsFile struct {
+	ShortenURL string                 `json:"shorten_url"`
+	Hits       int64                  `json:"hits"`
+	Variants   []variantAnalyticsFile `json:"variants,omitempty"`
+}
+
+type variantAnalyticsFile struct {
+	Destination string `json:"destination"`
+	Hits        int64  `json:"hits"`
+}
+
+type auditFile struct {
+	ID         uint64          `json:"id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// Archive returns a zip of json files with the account of user, its Google account if linked, its workspaces,
+// the links of its personal workspace or created by it, their hits, and the audit entries of its actions.
+// The password hash is left out.
+func (e *Exporter) Archive(user database.User) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	add := func(name string, v interface{}) error {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+
+	err := add("account.json", accountFile{
+		UserID:                 user.UserID,
+		Email:                  user.Email,
+		Type:                   user.Type,
+		Role:                   user.Role,
+		Disabled:               user.Disabled,
+		DeadLinkAlertsDisabled: user.DeadLinkAlertsDisabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	googleUser, err := e.db.GetGoogleUserWithUserID(user.UserID)
+	if err == nil {
+		if err := add("google_account.json", googleAccountFile{GoogleUUID: googleUser.GoogleUUID}); err != nil {
+			return nil, err
+		}
+	} else if _, ok := err.(database.RecordNotFoundError); !ok {
+		return nil, err
+	}
+
+	memberships, err := e.db.GetMembershipsWithUser(user)
+	if err != nil {
+		return nil, err
+	}
+	workspaces := make([]workspaceFile, len(memberships))
+	for i, m := range memberships {
+		workspaces[i] = workspaceFile{
+			WorkspaceID: m.Workspace.ID,
+			Name:        m.Workspace.Name,
+			Personal:    m.Workspace.Personal,
+			Role:        m.Role,
+			CreatedAt:   m.Workspace.CreatedAt,
+		}
+	}
+	if err := add("workspaces.json", workspaces); err != nil {
+		return nil, err
+	}
+
+	links, analytics, err := e.links(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := add("links.json", links); err != nil {
+		return nil, err
+	}
+	if err := add("analytics.json", analytics); err != nil {
+		return nil, err
+	}
+
+	entries, err := e.auditEntries(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := add("audit.json", entries); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (e *Exporter) links(user database.User) ([]linkFile, []analyticsFile, error) {
+	links := []linkFile{}
+	analytics := []analyticsFile{}
+	after := ""
+	for {
+		urls, err := e.db.GetURLsOfUserAfter(user.UserID, after, e.options.BatchSize)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, url := range urls {
+			link := linkFile{
+				ShortenURL:     url.ShortenURL,
+				OriginURL:      url.OriginURL,
+				Workspace:      url.Owner,
+				CreatedBy:      url.CreatedBy,
+				Domain:         url.Domain,
+				Title:          url.Title,
+				Folder:         url.Folder,
+				Note:           url.Note,
+				Tags:           url.Tags,
+				RedirectStatus: database.RedirectStatusOf(url),
+				Analytics:      !url.AnalyticsDisabled,
+				AppURI:         url.AppURI,
+				CreatedAt:      url.CreatedAt,
+				UpdatedAt:      url.UpdatedAt,
+			}
+			if !url.DeletedAt.IsZero() {
+				deletedAt := url.DeletedAt
+				link.DeletedAt = &deletedAt
+			}
+			links = append(links, link)
+
+			hits := analyticsFile{ShortenURL: url.ShortenURL, Hits: url.Count}
+			for _, variant := range url.Variants {
+				hits.Variants = append(hits.Variants, variantAnalyticsFile{Destination: variant.Destination, Hits: variant.Hits})
+			}
+			analytics = append(analytics, hits)
+		}
+
+		if uint64(len(urls)) < e.options.BatchSize {
+			return links, analytics, nil
+		}
+		after = urls[len(urls)-1].ShortenURL
+	}
+}
+
+func (e *Exporter) auditEntries(user database.User) ([]auditFile, error) {
+	files := []auditFile{}
+	query := database.AuditQuery{ActorID: user.UserID, Limit: e.options.BatchSize}
+	for {
+		entries, err := e.db.GetAuditEntries(query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			file := auditFile{
+				ID:         entry.ID,
+				Action:     entry.Action,
+				TargetType: entry.TargetType,
+				TargetID:   entry.TargetID,
+				IP:         entry.IP,
+				UserAgent:  entry.UserAgent,
+				CreatedAt:  entry.CreatedAt,
+			}
+			if entry.Before != "" {
+				file.Before = json.RawMessage(entry.Before)
+			}
+			if entry.After != "" {
+				file.After = json.RawMessage(entry.After)
+			}
+			files = append(files, file)
+		}
+
+		if uint64(len(entries)) < query.Limit {
+			return files, nil
+		}
+		query.BeforeID = entries[len(entries)-1].ID
+	}
+}
@@ -0,0 +1,160 @@
+package privacy_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"io/ioutil"
+	"time"
+	"url-shortener/internal/database"
+	mocks "url-shortener/internal/database/mocks"
+	"url-shortener/internal/service/mail"
+	"url-shortener/internal/service/privacy"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func readArchive(archive []byte) map[string][]byte {
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	Expect(err).ShouldNot(HaveOccurred())
+
+	files := map[string][]byte{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		Expect(err).ShouldNot(HaveOccurred())
+		content, err := ioutil.ReadAll(rc)
+		Expect(err).ShouldNot(HaveOccurred())
+		_ = rc.Close()
+		files[f.Name] = content
+	}
+	return files
+}
+
+var _ = Describe("Exporter", func() {
+	var (
+		ctrl         *gomock.Controller
+		db           *mocks.MockMySQLService
+		now          time.Time
+		emailRequest chan mail.SendEmailOptions
+		e            *privacy.Exporter
+		user         database.User
+		export       database.DataExport
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		db = mocks.NewMockMySQLService(ctrl)
+		now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		emailRequest = make(chan mail.SendEmailOptions, 1)
+		e = privacy.NewExporter(privacy.ServiceOptions{
+			Retention: 7 * 24 * time.Hour,
+			BatchSize: 2,
+			BaseUrl:   "https://sho.rt",
+		}, db, emailRequest, func() time.Time { return now })
+		user = database.User{UserID: "user-id", Email: "user@example.com", Type: "local", Password: "hash", Role: database.UserRoleUser}
+		export = database.DataExport{ID: "export-id", UserID: user.UserID, Status: database.DataExportStatusPending, CreatedAt: now.Add(-time.Minute)}
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	expectData := func() {
+		db.EXPECT().GetGoogleUserWithUserID(user.UserID).Return(&database.GoogleUser{UserID: user.UserID, GoogleUUID: "google-uuid"}, nil)
+		db.EXPECT().GetMembershipsWithUser(user).Return([]database.Membership{
+			{Workspace: database.Workspace{ID: user.UserID, Name: "Personal", Personal: true}, Role: database.WorkspaceRoleOwner},
+		}, nil)
+		gomock.InOrder(
+			db.EXPECT().GetURLsOfUserAfter(user.UserID, "", uint64(2)).Return([]database.URL{
+				{ShortenURL: "aaa", OriginURL: "https://a.com", Owner: user.UserID, Count: 3},
+				{ShortenURL: "bbb", OriginURL: "https://b.com", Owner: user.UserID, DeletedAt: now,
+					Variants: []database.URLVariant{{Destination: "https://b2.com", Hits: 2}}},
+			}, nil),
+			db.EXPECT().GetURLsOfUserAfter(user.UserID, "bbb", uint64(2)).Return([]database.URL{
+				{ShortenURL: "ccc", OriginURL: "https://c.com", Owner: "workspace-id", CreatedBy: user.UserID},
+			}, nil),
+		)
+		db.EXPECT().GetAuditEntries(database.AuditQuery{ActorID: user.UserID, Limit: 2}).Return([]database.AuditEntry{
+			{ID: 1, ActorID: user.UserID, Action: database.AuditActionSignIn, After: `{"type":"local"}`},
+		}, nil)
+	}
+
+	It("should package the data of user without its password", func() {
+		expectData()
+
+		archive, err := e.Archive(user)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		files := readArchive(archive)
+		Expect(files).To(HaveLen(6))
+		Expect(string(files["account.json"])).To(ContainSubstring("user@example.com"))
+		Expect(string(files["account.json"])).NotTo(ContainSubstring("hash"))
+		Expect(string(files["google_account.json"])).To(ContainSubstring("google-uuid"))
+		Expect(string(files["workspaces.json"])).To(ContainSubstring("Personal"))
+
+		var links []map[string]interface{}
+		Expect(json.Unmarshal(files["links.json"], &links)).To(Succeed())
+		Expect(links).To(HaveLen(3))
+		Expect(links[0]).NotTo(HaveKey("deleted_at"))
+		Expect(links[1]).To(HaveKey("deleted_at"))
+		Expect(links[2]["workspace"]).To(Equal("workspace-id"))
+
+		var analytics []map[string]interface{}
+		Expect(json.Unmarshal(files["analytics.json"], &analytics)).To(Succeed())
+		Expect(analytics[0]["hits"]).To(BeEquivalentTo(3))
+		Expect(analytics[1]["variants"]).To(HaveLen(1))
+
+		var audit []map[string]interface{}
+		Expect(json.Unmarshal(files["audit.json"], &audit)).To(Succeed())
+		Expect(audit).To(HaveLen(1))
+		Expect(audit[0]["after"]).To(Equal(map[string]interface{}{"type": "local"}))
+	})
+
+	It("should leave the Google account out if none is linked", func() {
+		db.EXPECT().GetGoogleUserWithUserID(user.UserID).Return(nil, database.NewRecordNotFoundError())
+		db.EXPECT().GetMembershipsWithUser(user).Return(nil, nil)
+		db.EXPECT().GetURLsOfUserAfter(user.UserID, "", uint64(2)).Return(nil, nil)
+		db.EXPECT().GetAuditEntries(gomock.Any()).Return(nil, nil)
+
+		archive, err := e.Archive(user)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(readArchive(archive)).NotTo(HaveKey("google_account.json"))
+	})
+
+	It("should store the archive until the retention period ends and notify user", func() {
+		expiresAt := export.CreatedAt.Add(7 * 24 * time.Hour)
+		db.EXPECT().GetUserWithID(user.UserID).Return(&user, nil)
+		expectData()
+		db.EXPECT().CompleteDataExport(export.ID, gomock.Any(), now, expiresAt).Return(nil)
+
+		e.Export(export)
+
+		var email mail.SendEmailOptions
+		Eventually(emailRequest).Should(Receive(&email))
+		Expect(email.To).To(Equal(user.Email))
+		Expect(email.Message).To(ContainSubstring("https://sho.rt/api/v2/user/data-export/export-id"))
+	})
+
+	It("should mark export failed if it cannot be packaged", func() {
+		db.EXPECT().GetUserWithID(user.UserID).Return(&user, nil)
+		db.EXPECT().GetGoogleUserWithUserID(user.UserID).Return(nil, errors.New("unexpected"))
+		db.EXPECT().FailDataExport(export.ID, now).Return(nil)
+
+		e.Export(export)
+
+		Consistently(emailRequest).ShouldNot(Receive())
+	})
+
+	It("should package pending exports and remove expired ones", func() {
+		db.EXPECT().GetPendingDataExports(uint64(2)).Return([]database.DataExport{export}, nil)
+		db.EXPECT().GetUserWithID(user.UserID).Return(nil, database.NewRecordNotFoundError())
+		db.EXPECT().FailDataExport(export.ID, now).Return(nil)
+		db.EXPECT().DeleteDataExportsBefore(now.Add(-7 * 24 * time.Hour)).Return(nil)
+
+		e.RunOnce(context.Background())
+	})
+})
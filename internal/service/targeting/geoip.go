@@ -0,0 +1,36 @@
+package targeting
+
+import (
+	"github.com/oschwald/maxminddb-golang"
+	"net"
+)
+
+// MMDB locates addresses with a local MaxMind database file, e.g. GeoLite2-Country.mmdb.
+type MMDB struct {
+	reader *maxminddb.Reader
+}
+
+func OpenMMDB(path string) (*MMDB, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &MMDB{reader: reader}, nil
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country of ip, empty if the database does not know it.
+func (m *MMDB) Country(ip net.IP) (string, error) {
+	var record struct {
+		Country struct {
+			ISOCode string `maxminddb:"iso_code"`
+		} `maxminddb:"country"`
+	}
+	if err := m.reader.Lookup(ip, &record); err != nil {
+		return "", err
+	}
+	return record.Country.ISOCode, nil
+}
+
+func (m *MMDB) Close() error {
+	return m.reader.Close()
+}
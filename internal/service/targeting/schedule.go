@@ -0,0 +1,58 @@
+package targeting
+
+import (
+	"sync"
+	"time"
+	"url-shortener/internal/database"
+)
+
+type Availability int
+
+const (
+	Available Availability = iota
+	NotYetAvailable
+	Ended
+)
+
+// AvailabilityAt tells whether a url active from startsAt until endsAt is served at now. Zero bounds are open.
+func AvailabilityAt(startsAt time.Time, endsAt time.Time, now time.Time) Availability {
+	if !startsAt.IsZero() && now.Before(startsAt) {
+		return NotYetAvailable
+	}
+	if !endsAt.IsZero() && !now.Before(endsAt) {
+		return Ended
+	}
+	return Available
+}
+
+var locations sync.Map
+
+// location loads the zone of name once, falling back to UTC for zones unknown to the system.
+func location(name string) *time.Location {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		loc = time.UTC
+	}
+	locations.Store(name, loc)
+	return loc
+}
+
+// InSchedule tells whether now falls into one of the windows of schedule, as seen in its timezone.
+func InSchedule(schedule database.Schedule, now time.Time) bool {
+	now = now.In(location(schedule.Timezone))
+	minute := now.Hour()*60 + now.Minute()
+	for _, window := range schedule.Windows {
+		if minute < window.Start || minute >= window.End {
+			continue
+		}
+		for _, day := range window.Days {
+			if day == now.Weekday() {
+				return true
+			}
+		}
+	}
+	return false
+}
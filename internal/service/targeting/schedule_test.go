@@ -0,0 +1,45 @@
+package targeting_test
+
+import (
+	"time"
+	"url-shortener/internal/clock"
+	"url-shortener/internal/database"
+	"url-shortener/internal/service/targeting"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Schedule", func() {
+	startsAt := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	endsAt := startsAt.Add(7 * 24 * time.Hour)
+
+	It("should only make urls available within their bounds", func() {
+		at := func(c clock.Clock) targeting.Availability {
+			return targeting.AvailabilityAt(startsAt, endsAt, c.Now())
+		}
+		Expect(at(clock.Fixed(startsAt.Add(-time.Second)))).To(Equal(targeting.NotYetAvailable))
+		Expect(at(clock.Fixed(startsAt))).To(Equal(targeting.Available))
+		Expect(at(clock.Fixed(endsAt.Add(-time.Second)))).To(Equal(targeting.Available))
+		Expect(at(clock.Fixed(endsAt))).To(Equal(targeting.Ended))
+		Expect(targeting.AvailabilityAt(time.Time{}, time.Time{}, startsAt)).To(Equal(targeting.Available))
+	})
+
+	It("should open urls during business hours in their timezone", func() {
+		schedule := database.Schedule{
+			Timezone: "Europe/Berlin",
+			Windows: []database.ScheduleWindow{
+				{Days: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, Start: 9 * 60, End: 17 * 60},
+			},
+		}
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		Expect(err).NotTo(HaveOccurred())
+
+		// Monday 2 March 2026
+		Expect(targeting.InSchedule(schedule, time.Date(2026, 3, 2, 9, 0, 0, 0, berlin))).To(BeTrue())
+		Expect(targeting.InSchedule(schedule, time.Date(2026, 3, 2, 16, 59, 0, 0, berlin))).To(BeTrue())
+		Expect(targeting.InSchedule(schedule, time.Date(2026, 3, 2, 17, 0, 0, 0, berlin))).To(BeFalse())
+		Expect(targeting.InSchedule(schedule, time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC))).To(BeTrue())
+		Expect(targeting.InSchedule(schedule, time.Date(2026, 3, 7, 12, 0, 0, 0, berlin))).To(BeFalse())
+	})
+})
@@ -0,0 +1,112 @@
+package targeting
+
+import (
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"url-shortener/internal/database"
+)
+
+// Visitor is what redirect rules are matched against.
+type Visitor struct {
+	Device   string // one of database.Device*
+	Country  string // empty if unknown
+	Language string // most preferred primary language subtag, empty if none
+}
+
+// GeoLocator resolves the country of an address.
+type GeoLocator interface {
+	Country(ip net.IP) (string, error)
+}
+
+// NewVisitor describes the visitor sending request from ip. Without geo, or if the lookup fails, the country is unknown.
+func NewVisitor(request *http.Request, ip net.IP, geo GeoLocator) Visitor {
+	v := Visitor{
+		Device:   DeviceOf(request.UserAgent()),
+		Language: PreferredLanguage(request.Header.Get("Accept-Language")),
+	}
+	if geo != nil && ip != nil {
+		if country, err := geo.Country(ip); err == nil {
+			v.Country = strings.ToUpper(country)
+		}
+	}
+	return v
+}
+
+// DeviceOf tells the platform of the device sending userAgent. Anything else than iOS and Android counts as desktop.
+func DeviceOf(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad") || strings.Contains(ua, "ipod"):
+		return database.DeviceIOS
+	case strings.Contains(ua, "android"):
+		return database.DeviceAndroid
+	}
+	return database.DeviceDesktop
+}
+
+// PreferredLanguage returns the primary subtag of the language ranked highest in an Accept-Language header.
+func PreferredLanguage(acceptLanguage string) string {
+	type ranged struct {
+		language string
+		quality  float64
+	}
+	var languages []ranged
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		languages = append(languages, ranged{language: strings.SplitN(tag, "-", 2)[0], quality: quality})
+	}
+	if len(languages) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].quality > languages[j].quality
+	})
+	return languages[0].language
+}
+
+// Matches tells whether visitor satisfies every condition of rule.
+func Matches(rule database.RedirectRule, visitor Visitor) bool {
+	return (rule.Device == "" || rule.Device == visitor.Device) &&
+		(rule.Country == "" || rule.Country == visitor.Country) &&
+		(rule.Language == "" || rule.Language == visitor.Language)
+}
+
+// Match returns the first of rules visitor satisfies.
+func Match(rules []database.RedirectRule, visitor Visitor) (database.RedirectRule, bool) {
+	for _, rule := range rules {
+		if Matches(rule, visitor) {
+			return rule, true
+		}
+	}
+	return database.RedirectRule{}, false
+}
+
+// NeedsCountry tells whether any of rules depends on the country of visitors, which takes a lookup.
+func NeedsCountry(rules []database.RedirectRule) bool {
+	for _, rule := range rules {
+		if rule.Country != "" {
+			return true
+		}
+	}
+	return false
+}
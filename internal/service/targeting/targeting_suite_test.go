@@ -0,0 +1,13 @@
+package targeting_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestTargeting(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Targeting Suite")
+}
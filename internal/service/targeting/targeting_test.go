@@ -0,0 +1,75 @@
+package targeting_test
+
+import (
+	"errors"
+	"net"
+	"net/http/httptest"
+	"url-shortener/internal/database"
+	"url-shortener/internal/service/targeting"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeLocator map[string]string
+
+func (l fakeLocator) Country(ip net.IP) (string, error) {
+	country, ok := l[ip.String()]
+	if !ok {
+		return "", errors.New("address not found")
+	}
+	return country, nil
+}
+
+const (
+	iPhone  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"
+	android = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36"
+	desktop = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36"
+)
+
+var _ = Describe("Targeting", func() {
+	geo := fakeLocator{"203.0.113.7": "de"}
+
+	visitor := func(userAgent string, acceptLanguage string, ip string) targeting.Visitor {
+		req := httptest.NewRequest("GET", "/abc", nil)
+		req.Header.Set("User-Agent", userAgent)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		return targeting.NewVisitor(req, net.ParseIP(ip), geo)
+	}
+
+	It("should describe visitor from request", func() {
+		Expect(visitor(iPhone, "de-DE,de;q=0.9,en;q=0.8", "203.0.113.7")).To(Equal(targeting.Visitor{
+			Device:   database.DeviceIOS,
+			Country:  "DE",
+			Language: "de",
+		}))
+		Expect(visitor(android, "", "198.51.100.1")).To(Equal(targeting.Visitor{Device: database.DeviceAndroid}))
+		Expect(visitor(desktop, "en;q=0.5, fr", "198.51.100.1").Language).To(Equal("fr"))
+	})
+
+	It("should pick the first rule matching every condition", func() {
+		rules := []database.RedirectRule{
+			{Device: database.DeviceIOS, Destination: "https://apps.apple.com/app/id1"},
+			{Device: database.DeviceAndroid, Destination: "https://play.google.com/store/apps/details?id=app"},
+			{Country: "DE", Language: "de", Destination: "https://example.com/de"},
+			{Language: "fr", Destination: "https://example.com/fr"},
+		}
+
+		rule, ok := targeting.Match(rules, visitor(iPhone, "de", "203.0.113.7"))
+		Expect(ok).To(BeTrue())
+		Expect(rule.Destination).To(Equal("https://apps.apple.com/app/id1"))
+
+		rule, ok = targeting.Match(rules, visitor(desktop, "de", "203.0.113.7"))
+		Expect(ok).To(BeTrue())
+		Expect(rule.Destination).To(Equal("https://example.com/de"))
+
+		rule, ok = targeting.Match(rules, visitor(desktop, "fr-CA", "198.51.100.1"))
+		Expect(ok).To(BeTrue())
+		Expect(rule.Destination).To(Equal("https://example.com/fr"))
+
+		_, ok = targeting.Match(rules, visitor(desktop, "de", "198.51.100.1"))
+		Expect(ok).To(BeFalse())
+	})
+})
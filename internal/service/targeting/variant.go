@@ -0,0 +1,36 @@
+package targeting
+
+import (
+	"url-shortener/internal/database"
+)
+
+// PickVariant returns the variant of a visitor. Visitors keep the variant identified by sticky while it is still
+// one of variants, the others are assigned one with a chance proportional to its weight, intn returning a number
+// in [0, n). Nothing is picked if no variant has a positive weight.
+func PickVariant(variants []database.URLVariant, sticky uint64, intn func(n int) int) (database.URLVariant, bool) {
+	total := 0
+	for _, variant := range variants {
+		if variant.Weight <= 0 {
+			continue
+		}
+		if sticky != 0 && variant.ID == sticky {
+			return variant, true
+		}
+		total += variant.Weight
+	}
+	if total == 0 {
+		return database.URLVariant{}, false
+	}
+
+	n := intn(total)
+	for _, variant := range variants {
+		if variant.Weight <= 0 {
+			continue
+		}
+		if n < variant.Weight {
+			return variant, true
+		}
+		n -= variant.Weight
+	}
+	return database.URLVariant{}, false
+}
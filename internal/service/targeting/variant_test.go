@@ -0,0 +1,55 @@
+package targeting_test
+
+import (
+	"url-shortener/internal/database"
+	"url-shortener/internal/service/targeting"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Variant", func() {
+	variants := []database.URLVariant{
+		{ID: 11, Destination: "https://example.com/a", Weight: 70},
+		{ID: 12, Destination: "https://example.com/b", Weight: 30},
+		{ID: 13, Destination: "https://example.com/c", Weight: 0},
+	}
+	fixed := func(value int) func(int) int {
+		return func(n int) int {
+			Expect(n).To(Equal(100))
+			return value
+		}
+	}
+
+	It("should split visitors by weight", func() {
+		variant, ok := targeting.PickVariant(variants, 0, fixed(0))
+		Expect(ok).To(BeTrue())
+		Expect(variant.ID).To(Equal(uint64(11)))
+
+		variant, _ = targeting.PickVariant(variants, 0, fixed(69))
+		Expect(variant.ID).To(Equal(uint64(11)))
+
+		variant, _ = targeting.PickVariant(variants, 0, fixed(70))
+		Expect(variant.ID).To(Equal(uint64(12)))
+
+		variant, _ = targeting.PickVariant(variants, 0, fixed(99))
+		Expect(variant.ID).To(Equal(uint64(12)))
+	})
+
+	It("should keep visitors on their variant while it exists", func() {
+		variant, ok := targeting.PickVariant(variants, 12, fixed(0))
+		Expect(ok).To(BeTrue())
+		Expect(variant.ID).To(Equal(uint64(12)))
+
+		variant, _ = targeting.PickVariant(variants, 13, fixed(0))
+		Expect(variant.ID).To(Equal(uint64(11)))
+
+		variant, _ = targeting.PickVariant(variants, 99, fixed(0))
+		Expect(variant.ID).To(Equal(uint64(11)))
+	})
+
+	It("should pick nothing without weights", func() {
+		_, ok := targeting.PickVariant(variants[2:], 0, fixed(0))
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,84 @@
+package trash
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"time"
+	"url-shortener/internal/database"
+)
+
+type ServiceOptions struct {
+	Retention time.Duration // how long deleted urls stay restorable before being purged
+	Interval  time.Duration // how often the trash is emptied of expired urls
+	BatchSize uint64
+}
+
+var logger = logrus.WithField("service", "PurgeService")
+
+// Purger permanently removes urls staying in the trash longer than the retention period.
+type Purger struct {
+	options ServiceOptions
+	db      database.MySQLService
+	now     func() time.Time
+}
+
+func NewPurger(c ServiceOptions, db database.MySQLService, now func() time.Time) *Purger {
+	if c.BatchSize == 0 {
+		c.BatchSize = 100
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return &Purger{
+		options: c,
+		db:      db,
+		now:     now,
+	}
+}
+
+// StartPurgeService empties the trash of expired urls every c.Interval until ctx is done.
+func StartPurgeService(ctx context.Context, c *ServiceOptions, db database.MySQLService) {
+	if c == nil {
+		logger.Info("Service disabled")
+		return
+	}
+
+	p := NewPurger(*c, db, nil)
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	logger.Info("Started...")
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Stopped")
+			return
+		case <-ticker.C:
+			p.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce purges urls deleted before the retention period, batch by batch, and returns how many were purged.
+func (p *Purger) RunOnce(ctx context.Context) uint64 {
+	deletedBefore := p.now().Add(-p.options.Retention)
+
+	var total uint64
+	for ctx.Err() == nil {
+		purged, err := p.db.PurgeURLsDeletedBefore(deletedBefore, p.options.BatchSize)
+		total += purged
+		if err != nil {
+			logger.WithError(err).Error("Unable to purge deleted urls")
+			break
+		}
+		if purged < p.options.BatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		logger.WithField("count", total).Info("Purged deleted urls")
+	}
+	return total
+}
@@ -0,0 +1,59 @@
+package trash_test
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"time"
+	mocks "url-shortener/internal/database/mocks"
+	"url-shortener/internal/service/trash"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Purger", func() {
+	var (
+		ctrl *gomock.Controller
+		db   *mocks.MockMySQLService
+		now  time.Time
+		p    *trash.Purger
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		db = mocks.NewMockMySQLService(ctrl)
+		now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		p = trash.NewPurger(trash.ServiceOptions{
+			Retention: 30 * 24 * time.Hour,
+			BatchSize: 2,
+		}, db, func() time.Time { return now })
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should purge urls deleted before the retention period until a batch is not full", func() {
+		deletedBefore := now.Add(-30 * 24 * time.Hour)
+		gomock.InOrder(
+			db.EXPECT().PurgeURLsDeletedBefore(deletedBefore, uint64(2)).Return(uint64(2), nil),
+			db.EXPECT().PurgeURLsDeletedBefore(deletedBefore, uint64(2)).Return(uint64(1), nil),
+		)
+
+		Expect(p.RunOnce(context.Background())).To(Equal(uint64(3)))
+	})
+
+	It("should stop at the first error", func() {
+		db.EXPECT().PurgeURLsDeletedBefore(gomock.Any(), uint64(2)).Return(uint64(1), errors.New("unexpected"))
+
+		Expect(p.RunOnce(context.Background())).To(Equal(uint64(1)))
+	})
+
+	It("should not purge once context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		Expect(p.RunOnce(ctx)).To(Equal(uint64(0)))
+	})
+})
@@ -0,0 +1,290 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"url-shortener/internal/database"
+	"url-shortener/internal/service/mail"
+	"url-shortener/internal/service/metadata"
+)
+
+type ServiceOptions struct {
+	Client           metadata.FetcherOptions
+	Interval         time.Duration // how often due deliveries are attempted and click thresholds looked for
+	BatchSize        uint64
+	MaxAttempts      int           // attempts of a delivery before giving up
+	RetryDelay       time.Duration // delay before the first retry, doubled for each following one
+	MaxRetryDelay    time.Duration
+	DisableAfter     int // failed attempts in a row disabling a webhook, never disabled if 0
+	HistoryRetention time.Duration
+}
+
+// DefaultServiceOptions is used by the service unless overridden.
+var DefaultServiceOptions = ServiceOptions{
+	Client:           metadata.FetcherOptions{Timeout: 10 * time.Second},
+	Interval:         10 * time.Second,
+	BatchSize:        100,
+	MaxAttempts:      8,
+	RetryDelay:       30 * time.Second,
+	MaxRetryDelay:    6 * time.Hour,
+	DisableAfter:     20,
+	HistoryRetention: 30 * 24 * time.Hour,
+}
+
+var logger = logrus.WithField("service", "WebhookService")
+
+// Dispatcher delivers queued events to webhooks, retrying failed deliveries with an exponential backoff.
+type Dispatcher struct {
+	options      ServiceOptions
+	db           database.MySQLService
+	client       *http.Client
+	emailRequest chan<- mail.SendEmailOptions
+	now          func() time.Time
+}
+
+func NewDispatcher(c ServiceOptions, db database.MySQLService, emailRequest chan<- mail.SendEmailOptions, now func() time.Time) *Dispatcher {
+	if c.BatchSize == 0 {
+		c.BatchSize = 100
+	}
+	if c.MaxAttempts < 1 {
+		c.MaxAttempts = 1
+	}
+	if now == nil {
+		now = time.Now
+	}
+	// note: receivers answering with a redirect are considered failing rather than followed
+	c.Client.MaxRedirects = 0
+	return &Dispatcher{
+		options:      c,
+		db:           db,
+		client:       metadata.NewHTTPClient(c.Client),
+		emailRequest: emailRequest,
+		now:          now,
+	}
+}
+
+// StartDeliveryService runs a round of deliveries every c.Interval until ctx is done. Creators of webhooks
+// disabled after repeated failures are told by email.
+func StartDeliveryService(ctx context.Context, c *ServiceOptions, db database.MySQLService, emailRequest chan<- mail.SendEmailOptions) {
+	if c == nil {
+		logger.Info("Service disabled")
+		return
+	}
+
+	d := NewDispatcher(*c, db, emailRequest, nil)
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	logger.Info("Started...")
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Stopped")
+			return
+		case <-ticker.C:
+			d.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce queues click thresholds newly reached, attempts due deliveries and prunes expired history.
+func (d *Dispatcher) RunOnce(ctx context.Context) {
+	d.QueueClicks()
+	d.DeliverDue(ctx)
+
+	if d.options.HistoryRetention > 0 {
+		if err := d.db.DeleteWebhookDeliveriesBefore(d.now().Add(-d.options.HistoryRetention)); err != nil {
+			logger.WithError(err).Warn("Unable to prune webhook deliveries")
+		}
+	}
+}
+
+// QueueClicks queues a delivery for each url reaching a click threshold of a webhook of its workspace.
+func (d *Dispatcher) QueueClicks() {
+	webhooks, err := d.db.GetWebhooksWithEvent(database.WebhookEventURLClicks)
+	if err != nil {
+		logger.WithError(err).Error("Unable to query webhooks subscribed to clicks")
+		return
+	}
+
+	for _, webhook := range webhooks {
+		webhookLogger := logger.WithField("webhook_id", webhook.ID)
+		for _, clicks := range webhook.ClickThresholds {
+			urls, err := d.db.GetURLsReachingClicks(webhook, clicks, d.options.BatchSize)
+			if err != nil {
+				webhookLogger.WithError(err).Error("Unable to query urls reaching clicks")
+				return
+			}
+
+			for _, url := range urls {
+				payload, err := NewPayload(database.WebhookEventURLClicks, url, clicks, d.now())
+				if err != nil {
+					webhookLogger.WithError(err).Warn("Unable to encode webhook payload")
+					continue
+				}
+				delivery := database.WebhookDelivery{
+					WebhookID:     webhook.ID,
+					Event:         database.WebhookEventURLClicks,
+					Payload:       payload,
+					NextAttemptAt: d.now(),
+				}
+				if err := d.db.EnqueueWebhookClicksDelivery(delivery, url.ShortenURL, clicks); err != nil {
+					webhookLogger.WithError(err).WithField("shorten_url", url.ShortenURL).Error("Unable to queue clicks delivery")
+				}
+			}
+		}
+	}
+}
+
+// DeliverDue attempts deliveries due by now, batch after batch.
+func (d *Dispatcher) DeliverDue(ctx context.Context) {
+	for {
+		deliveries, err := d.db.GetDueWebhookDeliveries(d.now(), d.options.BatchSize)
+		if err != nil {
+			logger.WithError(err).Error("Unable to query due webhook deliveries")
+			return
+		}
+
+		webhooks := map[string]*database.Webhook{}
+		for _, delivery := range deliveries {
+			if ctx.Err() != nil {
+				return
+			}
+
+			webhook, ok := webhooks[delivery.WebhookID]
+			if !ok {
+				webhook, err = d.db.GetWebhook(delivery.WebhookID)
+				if err != nil {
+					logger.WithError(err).WithField("webhook_id", delivery.WebhookID).Error("Unable to query webhook of delivery")
+					return
+				}
+				webhooks[delivery.WebhookID] = webhook
+			}
+			if !webhook.DisabledAt.IsZero() {
+				continue
+			}
+
+			if !d.Deliver(ctx, webhook, delivery) {
+				// note: stop rather than attempting the same deliveries over and over
+				return
+			}
+		}
+
+		if uint64(len(deliveries)) < d.options.BatchSize {
+			return
+		}
+	}
+}
+
+// Deliver attempts delivery to webhook and records the outcome, scheduling a retry if it failed.
+// It returns false if the outcome could not be recorded.
+func (d *Dispatcher) Deliver(ctx context.Context, webhook *database.Webhook, delivery database.WebhookDelivery) bool {
+	deliveryLogger := logger.WithField("webhook_id", webhook.ID).WithField("delivery_id", delivery.ID)
+
+	status, err := d.post(ctx, *webhook, delivery)
+	now := d.now()
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+	delivery.Error = ""
+
+	switch {
+	case err == nil:
+		delivery.Status = database.WebhookDeliveryStatusDelivered
+		delivery.DeliveredAt = now
+	case delivery.Attempts >= d.options.MaxAttempts:
+		delivery.Status = database.WebhookDeliveryStatusFailed
+		delivery.Error = err.Error()
+	default:
+		delivery.NextAttemptAt = now.Add(d.RetryDelay(delivery.Attempts))
+		delivery.Error = err.Error()
+	}
+	if err != nil {
+		deliveryLogger.WithError(err).WithField("attempts", delivery.Attempts).Info("Webhook delivery failed")
+	}
+
+	disabled, err := d.db.RecordWebhookDelivery(delivery, d.options.DisableAfter, now)
+	if err != nil {
+		deliveryLogger.WithError(err).Error("Unable to record webhook delivery")
+		return false
+	}
+	if disabled {
+		deliveryLogger.Warn("Webhook disabled after repeated failures")
+		webhook.DisabledAt = now
+		d.alert(*webhook)
+	}
+	return true
+}
+
+// RetryDelay returns how long to wait before the next attempt of a delivery attempted attempts times.
+func (d *Dispatcher) RetryDelay(attempts int) time.Duration {
+	delay := d.options.RetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if d.options.MaxRetryDelay > 0 && delay >= d.options.MaxRetryDelay {
+			return d.options.MaxRetryDelay
+		}
+	}
+	return delay
+}
+
+// post sends the payload of delivery to webhook. Statuses other than 2xx are errors.
+func (d *Dispatcher) post(ctx context.Context, webhook database.Webhook, delivery database.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "url-shortener-webhook/1.0")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, timestamp, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	metadata.DrainBody(res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("receiver answered %v", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+// alert tells the creator of webhook it was disabled. Nothing is sent without an email request channel.
+func (d *Dispatcher) alert(webhook database.Webhook) {
+	if d.emailRequest == nil {
+		return
+	}
+
+	creator, err := d.db.GetUserWithID(webhook.CreatedBy)
+	if err != nil {
+		logger.WithError(err).WithField("webhook_id", webhook.ID).Warn("Unable to query creator of disabled webhook")
+		return
+	}
+
+	message := strings.Join([]string{
+		fmt.Sprintf("Your webhook to %v failed %v times in a row and was disabled.", webhook.URL, d.options.DisableAfter),
+		"Deliveries pending when it was disabled are attempted again once it is enabled from the API.",
+	}, "\r\n\r\n")
+	select {
+	case d.emailRequest <- mail.SendEmailOptions{
+		To:      creator.Email,
+		Subject: "Webhook disabled",
+		Message: message,
+	}:
+	default:
+		logger.WithField("webhook_id", webhook.ID).Warn("Email request queue is full, creator is not told")
+	}
+}
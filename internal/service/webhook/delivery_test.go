@@ -0,0 +1,189 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+	"url-shortener/internal/database"
+	mocks "url-shortener/internal/database/mocks"
+	"url-shortener/internal/service/mail"
+	"url-shortener/internal/service/metadata"
+	"url-shortener/internal/service/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+var _ = Describe("Dispatcher", func() {
+	var (
+		ctrl         *gomock.Controller
+		db           *mocks.MockMySQLService
+		now          time.Time
+		emailRequest chan mail.SendEmailOptions
+		d            *webhook.Dispatcher
+		status       int
+		requests     chan received
+		receiver     *httptest.Server
+		hook         database.Webhook
+		delivery     database.WebhookDelivery
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		db = mocks.NewMockMySQLService(ctrl)
+		now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		emailRequest = make(chan mail.SendEmailOptions, 1)
+		d = webhook.NewDispatcher(webhook.ServiceOptions{
+			Client:        metadata.FetcherOptions{Timeout: time.Second, AllowPrivateNetworks: true},
+			BatchSize:     10,
+			MaxAttempts:   3,
+			RetryDelay:    time.Minute,
+			MaxRetryDelay: 3 * time.Minute,
+			DisableAfter:  5,
+		}, db, emailRequest, func() time.Time { return now })
+
+		status = http.StatusOK
+		requests = make(chan received, 10)
+		receiver = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			requests <- received{header: r.Header, body: body}
+			w.WriteHeader(status)
+		}))
+
+		hook = database.Webhook{
+			ID:          "webhook-id",
+			WorkspaceID: "workspace-id",
+			URL:         receiver.URL,
+			Secret:      "secret",
+			Events:      []string{database.WebhookEventURLCreated},
+			CreatedBy:   "user-id",
+		}
+		delivery = database.WebhookDelivery{
+			ID:        7,
+			WebhookID: hook.ID,
+			Event:     database.WebhookEventURLCreated,
+			Payload:   `{"event":"url.created"}`,
+			Status:    database.WebhookDeliveryStatusPending,
+		}
+	})
+
+	AfterEach(func() {
+		receiver.Close()
+		ctrl.Finish()
+	})
+
+	It("should sign deliveries and record them delivered", func() {
+		db.EXPECT().RecordWebhookDelivery(gomock.Any(), 5, now).DoAndReturn(
+			func(recorded database.WebhookDelivery, _ int, _ time.Time) (bool, error) {
+				Expect(recorded.Status).To(Equal(database.WebhookDeliveryStatusDelivered))
+				Expect(recorded.Attempts).To(Equal(1))
+				Expect(recorded.ResponseStatus).To(Equal(http.StatusOK))
+				Expect(recorded.DeliveredAt).To(Equal(now))
+				return false, nil
+			})
+
+		Expect(d.Deliver(context.Background(), &hook, delivery)).To(BeTrue())
+
+		var r received
+		Expect(requests).To(Receive(&r))
+		Expect(string(r.body)).To(Equal(delivery.Payload))
+		Expect(r.header.Get(webhook.EventHeader)).To(Equal(database.WebhookEventURLCreated))
+		Expect(r.header.Get(webhook.DeliveryHeader)).To(Equal("7"))
+		timestamp, err := strconv.ParseInt(r.header.Get(webhook.TimestampHeader), 10, 64)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(timestamp).To(Equal(now.Unix()))
+		Expect(r.header.Get(webhook.SignatureHeader)).To(Equal(webhook.Sign("secret", timestamp, r.body)))
+	})
+
+	It("should retry failed deliveries with an exponential backoff and give up after max attempts", func() {
+		status = http.StatusInternalServerError
+		var recorded []database.WebhookDelivery
+		db.EXPECT().RecordWebhookDelivery(gomock.Any(), 5, now).DoAndReturn(
+			func(r database.WebhookDelivery, _ int, _ time.Time) (bool, error) {
+				recorded = append(recorded, r)
+				return false, nil
+			}).Times(3)
+
+		for i := 0; i < 3; i++ {
+			Expect(d.Deliver(context.Background(), &hook, delivery)).To(BeTrue())
+			delivery = recorded[i]
+		}
+
+		Expect(recorded[0].Status).To(Equal(database.WebhookDeliveryStatusPending))
+		Expect(recorded[0].ResponseStatus).To(Equal(http.StatusInternalServerError))
+		Expect(recorded[0].Error).NotTo(BeEmpty())
+		Expect(recorded[0].NextAttemptAt).To(Equal(now.Add(time.Minute)))
+		Expect(recorded[1].NextAttemptAt).To(Equal(now.Add(2 * time.Minute)))
+		Expect(recorded[2].Status).To(Equal(database.WebhookDeliveryStatusFailed))
+		Expect(recorded[2].Attempts).To(Equal(3))
+	})
+
+	It("should cap retry delays", func() {
+		Expect(d.RetryDelay(1)).To(Equal(time.Minute))
+		Expect(d.RetryDelay(2)).To(Equal(2 * time.Minute))
+		Expect(d.RetryDelay(3)).To(Equal(3 * time.Minute))
+		Expect(d.RetryDelay(10)).To(Equal(3 * time.Minute))
+	})
+
+	It("should not follow redirects of receivers", func() {
+		status = http.StatusFound
+		db.EXPECT().RecordWebhookDelivery(gomock.Any(), 5, now).DoAndReturn(
+			func(recorded database.WebhookDelivery, _ int, _ time.Time) (bool, error) {
+				Expect(recorded.Status).To(Equal(database.WebhookDeliveryStatusPending))
+				return false, nil
+			})
+
+		Expect(d.Deliver(context.Background(), &hook, delivery)).To(BeTrue())
+	})
+
+	It("should tell the creator once webhook is disabled and skip its other deliveries", func() {
+		status = http.StatusServiceUnavailable
+		other := delivery
+		other.ID = 8
+		db.EXPECT().GetDueWebhookDeliveries(now, uint64(10)).Return([]database.WebhookDelivery{delivery, other}, nil)
+		db.EXPECT().GetWebhook(hook.ID).Return(&hook, nil)
+		db.EXPECT().RecordWebhookDelivery(gomock.Any(), 5, now).Return(true, nil)
+		db.EXPECT().GetUserWithID("user-id").Return(&database.User{UserID: "user-id", Email: "user@example.com"}, nil)
+
+		d.DeliverDue(context.Background())
+
+		Expect(requests).To(HaveLen(1))
+		var email mail.SendEmailOptions
+		Expect(emailRequest).To(Receive(&email))
+		Expect(email.To).To(Equal("user@example.com"))
+		Expect(email.Message).To(ContainSubstring(receiver.URL))
+	})
+
+	It("should queue a delivery for urls reaching click thresholds", func() {
+		hook.Events = []string{database.WebhookEventURLClicks}
+		hook.ClickThresholds = []uint64{100}
+		url := database.URL{ShortenURL: "abc", OriginURL: "https://example.com", Owner: hook.WorkspaceID, Count: 120}
+		db.EXPECT().GetWebhooksWithEvent(database.WebhookEventURLClicks).Return([]database.Webhook{hook}, nil)
+		db.EXPECT().GetURLsReachingClicks(hook, uint64(100), uint64(10)).Return([]database.URL{url}, nil)
+		db.EXPECT().EnqueueWebhookClicksDelivery(gomock.Any(), "abc", uint64(100)).DoAndReturn(
+			func(queued database.WebhookDelivery, _ string, _ uint64) error {
+				Expect(queued.WebhookID).To(Equal(hook.ID))
+				Expect(queued.NextAttemptAt).To(Equal(now))
+
+				var payload webhook.Payload
+				Expect(json.Unmarshal([]byte(queued.Payload), &payload)).To(Succeed())
+				Expect(payload.Event).To(Equal(database.WebhookEventURLClicks))
+				Expect(payload.Workspace).To(Equal(hook.WorkspaceID))
+				Expect(payload.Clicks).To(Equal(uint64(100)))
+				Expect(payload.Link.Hits).To(Equal(int64(120)))
+				return nil
+			})
+
+		d.QueueClicks()
+	})
+})
@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"time"
+	"url-shortener/internal/database"
+)
+
+// Headers sent along with every delivery. Receivers check the signature by computing the HMAC-SHA256 of
+// "<timestamp>.<body>" with the secret of the webhook, and may refuse timestamps too far in the past.
+const (
+	SignatureHeader = "X-Webhook-Signature" // "sha256=" followed by the hex encoded HMAC
+	TimestampHeader = "X-Webhook-Timestamp" // unix seconds the delivery was attempted at
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery" // id of the delivery, the same across retries
+)
+
+const secretLength = 32
+
+// Payload is the json body delivered to webhooks.
+type Payload struct {
+	Event     string    `json:"event"`
+	Workspace string    `json:"workspace"`
+	Link      Link      `json:"link"`
+	Clicks    uint64    `json:"clicks,omitempty"` // threshold reached, for url.clicks only
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Link struct {
+	ShortenURL string     `json:"shorten_url"`
+	OriginURL  string     `json:"origin_url"`
+	Domain     string     `json:"domain,omitempty"`
+	Title      string     `json:"title"`
+	Tags       []string   `json:"tags"`
+	Hits       int64      `json:"hits"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+}
+
+func newLink(url database.URL) Link {
+	link := Link{
+		ShortenURL: url.ShortenURL,
+		OriginURL:  url.OriginURL,
+		Domain:     url.Domain,
+		Title:      url.Title,
+		Tags:       url.Tags,
+		Hits:       url.Count,
+		CreatedAt:  url.CreatedAt,
+		UpdatedAt:  url.UpdatedAt,
+	}
+	if link.Tags == nil {
+		link.Tags = []string{}
+	}
+	if !url.DeletedAt.IsZero() {
+		deletedAt := url.DeletedAt
+		link.DeletedAt = &deletedAt
+	}
+	return link
+}
+
+// NewPayload returns the body delivered about event on url, clicks being the threshold reached for url.clicks.
+func NewPayload(event string, url database.URL, clicks uint64, at time.Time) (string, error) {
+	b, err := json.Marshal(Payload{
+		Event:     event,
+		Workspace: url.Owner,
+		Link:      newLink(url),
+		Clicks:    clicks,
+		CreatedAt: at.UTC(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Sign returns the value of SignatureHeader for body sent at timestamp to a webhook holding secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "%d.", timestamp)
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret returns a random secret to sign deliveries of a new webhook with.
+func NewSecret() (string, error) {
+	raw := make([]byte, secretLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// Notify queues event about url for the webhooks of its workspace subscribed to it. Callers have stored the change
+// already, so a delivery failing to be queued is logged and missed by receivers rather than failing the request.
+func Notify(db database.MySQLService, logger *logrus.Entry, event string, url database.URL) {
+	logger = logger.WithField("event", event).WithField("shorten_url", url.ShortenURL)
+
+	payload, err := NewPayload(event, url, 0, time.Now())
+	if err != nil {
+		logger.WithError(err).Warn("Unable to encode webhook payload")
+		return
+	}
+	if _, err := db.EnqueueWebhookDeliveries(url.Owner, event, payload); err != nil {
+		logger.WithError(err).Error("Unable to queue webhook deliveries")
+	}
+}